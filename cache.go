@@ -0,0 +1,188 @@
+package arazzo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// cacheObjectsDir is the directory of the cache holding the
+	// documents, named after the SHA-256 of their content.
+	cacheObjectsDir = "objects"
+	// cacheURLsDir is the directory of the cache holding an entry per
+	// cached URL, named after the SHA-256 of the URL.
+	cacheURLsDir = "urls"
+)
+
+// Cache is an on-disk cache of remote documents. Documents are stored
+// by the SHA-256 of their content, so that URLs serving the same
+// document share it, and every cached URL records the digest of its
+// document along with the validators the server sent.
+type Cache struct {
+	dir string
+}
+
+// cacheEntry is the entry of a cached URL.
+type cacheEntry struct {
+	URL string `json:"url"`
+	// Digest is the hex encoded SHA-256 of the document.
+	Digest       string `json:"digest"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// CacheMissError is returned by an offline [Loader] when a remote
+// document is not cached.
+type CacheMissError struct {
+	// URL is the URL of the remote document.
+	URL string
+}
+
+// Error returns a formatted error message indicating the URL which is
+// not cached.
+func (e *CacheMissError) Error() string {
+	return fmt.Sprintf(
+		"arazzo-go: %q is not cached and remote lookups are offline",
+		e.URL,
+	)
+}
+
+// NewCache creates a cache stored within dir, which is created on the
+// first write.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Dir returns the directory the cache is stored within.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Seed stores every file of dir in the cache. Files are laid out as
+// <scheme>/<host>/<path>, e.g. the file
+// https/example.com/apis/petstore.yaml is cached as the document of
+// https://example.com/apis/petstore.yaml.
+func (c *Cache) Seed(dir string) error {
+	return filepath.WalkDir(
+		dir,
+		func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			rawURL, err := seedURL(rel)
+			if err != nil {
+				return fmt.Errorf("failed to seed %q: %w", path, err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to seed %q: %w", path, err)
+			}
+			return c.store(&cacheEntry{URL: rawURL}, data)
+		},
+	)
+}
+
+// seedURL returns the URL of the seed file at path, relative to the
+// seed directory.
+func seedURL(path string) (string, error) {
+	parts := strings.SplitN(filepath.ToSlash(path), "/", 3)
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" {
+		return "", errors.New("path must be <scheme>/<host>/<path>")
+	}
+	u := url.URL{
+		Scheme: parts[0],
+		Host:   parts[1],
+		Path:   "/" + parts[2],
+	}
+	return u.String(), nil
+}
+
+// lookup returns the entry and the document cached for rawURL. A
+// document whose content does not match its digest is not cached.
+func (c *Cache) lookup(rawURL string) (*cacheEntry, []byte, bool) {
+	data, err := os.ReadFile(c.entryPath(rawURL))
+	if err != nil {
+		return nil, nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil ||
+		entry.URL != rawURL {
+		return nil, nil, false
+	}
+	data, err = os.ReadFile(c.objectPath(entry.Digest))
+	if err != nil || digest(data) != entry.Digest {
+		return nil, nil, false
+	}
+	return &entry, data, true
+}
+
+// store caches data as the document of entry, whose digest is set.
+func (c *Cache) store(entry *cacheEntry, data []byte) error {
+	entry.Digest = digest(data)
+	object := c.objectPath(entry.Digest)
+	if _, err := os.Stat(object); err != nil {
+		if err := writeFileAtomic(object, data); err != nil {
+			return fmt.Errorf("failed to cache document: %w", err)
+		}
+	}
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to cache document: %w", err)
+	}
+	if err := writeFileAtomic(c.entryPath(entry.URL), encoded); err != nil {
+		return fmt.Errorf("failed to cache document: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) entryPath(rawURL string) string {
+	return filepath.Join(
+		c.dir,
+		cacheURLsDir,
+		digest([]byte(rawURL))+".json",
+	)
+}
+
+func (c *Cache) objectPath(digest string) string {
+	return filepath.Join(c.dir, cacheObjectsDir, digest)
+}
+
+// digest returns the hex encoded SHA-256 of data.
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeFileAtomic writes data to path through a temporary file, so
+// that concurrent readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,99 @@
+package arazzo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_Cache(t *testing.T) {
+	const etag = `"v1"`
+	body := "openapi: 3.1.0"
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Header.Get("If-None-Match"))
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			_, _ = w.Write([]byte(body))
+		},
+	))
+	defer server.Close()
+	rawURL := server.URL + "/petstore.yaml"
+	cache := NewCache(t.TempDir())
+
+	loader := NewLoader(
+		AllowRemoteLookup(),
+		WithHTTPClient(server.Client()),
+		WithCache(cache),
+	)
+	for range 2 {
+		data, err := loader.LoadFile(rawURL)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(data))
+	}
+	assert.Equal(t, []string{"", etag}, requests)
+
+	offline := NewLoader(
+		AllowRemoteLookup(),
+		WithCache(cache),
+		Offline(),
+	)
+	data, err := offline.LoadFile(rawURL)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(data))
+	assert.Len(t, requests, 2)
+
+	_, err = offline.LoadFile(server.URL + "/missing.yaml")
+	var missErr *CacheMissError
+	require.ErrorAs(t, err, &missErr)
+	assert.EqualError(
+		t,
+		err,
+		`arazzo-go: "`+server.URL+
+			`/missing.yaml" is not cached and remote lookups are offline`,
+	)
+}
+
+func TestCache_Seed(t *testing.T) {
+	seed := t.TempDir()
+	file := filepath.Join(
+		seed,
+		"https",
+		"example.com",
+		"apis",
+		"a.yaml",
+	)
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+	require.NoError(t, os.WriteFile(file, []byte("seeded"), 0o644))
+
+	cache := NewCache(t.TempDir())
+	require.NoError(t, cache.Seed(seed))
+	loader := NewLoader(
+		AllowRemoteLookup(),
+		WithCache(cache),
+		Offline(),
+	)
+	data, err := loader.LoadFile("https://example.com/apis/a.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "seeded", string(data))
+
+	require.NoError(t, os.WriteFile(
+		filepath.Join(seed, "https", "a.yaml"),
+		nil,
+		0o644,
+	))
+	assert.ErrorContains(
+		t,
+		cache.Seed(seed),
+		"path must be <scheme>/<host>/<path>",
+	)
+}
@@ -0,0 +1,132 @@
+// Command arazzo provides tooling for Arazzo documents.
+//
+// Usage:
+//
+//	arazzo bundle [flags] <document>
+//
+// The bundle command bundles an Arazzo document along with its source
+// descriptions, see [v1.NewBundle]. The output is a directory, unless
+// its name ends with .zip, .tar, .tar.gz or .tgz in which case the
+// bundle is written as an archive.
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	arazzo "github.com/bragdonD/arazzo-go"
+	v1 "github.com/bragdonD/arazzo-go/v1"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "arazzo:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command named by the first of args.
+func run(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: arazzo bundle [flags] <document>")
+	}
+	switch args[0] {
+	case "bundle":
+		return bundle(args[1:])
+	}
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// bundle runs the bundle command.
+func bundle(args []string) error {
+	flags := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	output := flags.String(
+		"o",
+		"bundle",
+		"output directory or .zip, .tar, .tar.gz or .tgz archive",
+	)
+	remote := flags.Bool("remote", false, "allow remote lookups")
+	cacheDir := flags.String(
+		"cache",
+		"",
+		"cache remote documents in `dir`",
+	)
+	seedDir := flags.String("seed", "", "seed the cache from `dir`")
+	offline := flags.Bool(
+		"offline",
+		false,
+		"load remote documents from the cache only",
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: arazzo bundle [flags] <document>")
+	}
+
+	loaderOpts := []arazzo.LoaderOption{arazzo.AllowLocalLookup()}
+	if *remote || *offline {
+		loaderOpts = append(loaderOpts, arazzo.AllowRemoteLookup())
+	}
+	if *cacheDir != "" {
+		cache := arazzo.NewCache(*cacheDir)
+		if *seedDir != "" {
+			if err := cache.Seed(*seedDir); err != nil {
+				return err
+			}
+		}
+		loaderOpts = append(loaderOpts, arazzo.WithCache(cache))
+	} else if *seedDir != "" {
+		return errors.New("-seed requires -cache")
+	}
+	if *offline {
+		loaderOpts = append(loaderOpts, arazzo.Offline())
+	}
+
+	b, err := v1.NewBundle(
+		flags.Arg(0),
+		v1.WithLoader(arazzo.NewLoader(loaderOpts...)),
+	)
+	if err != nil {
+		return err
+	}
+	return writeBundle(b, *output)
+}
+
+// writeBundle writes b to output, a directory or an archive depending
+// on its extension.
+func writeBundle(b *v1.Bundle, output string) error {
+	var write func(w io.Writer) error
+	switch {
+	case strings.HasSuffix(output, ".zip"):
+		write = b.WriteZip
+	case strings.HasSuffix(output, ".tar"):
+		write = b.WriteTar
+	case strings.HasSuffix(output, ".tar.gz"),
+		strings.HasSuffix(output, ".tgz"):
+		write = func(w io.Writer) error {
+			gw := gzip.NewWriter(w)
+			if err := b.WriteTar(gw); err != nil {
+				return err
+			}
+			return gw.Close()
+		}
+	default:
+		return b.WriteDir(output)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
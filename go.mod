@@ -5,24 +5,27 @@ go 1.24.1
 require github.com/go-test/deep v1.1.1
 
 require (
+	github.com/antchfx/xmlquery v1.5.1
+	github.com/antchfx/xpath v1.3.8
+	github.com/bragdond/jsonpointer-go v1.0.0
 	github.com/pb33f/libopenapi v0.21.8
-	github.com/pb33f/libopenapi-validator v0.3.0
 	github.com/santhosh-tekuri/jsonschema/v6 v6.0.1
+	github.com/speakeasy-api/jsonpath v0.6.1
 	github.com/stretchr/testify v1.10.0
+	github.com/vmware-labs/yaml-jsonpath v0.3.2
+	golang.org/x/text v0.21.0
+	gopkg.in/yaml.v3 v3.0.1
 	sigs.k8s.io/yaml v1.4.0
 )
 
 require (
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
-	github.com/bragdond/jsonpointer-go v1.0.0 // indirect
 	github.com/buger/jsonparser v1.1.1 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/dprotaso/go-yit v0.0.0-20240618133044-5a0af90af097 // indirect
+	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/speakeasy-api/jsonpath v0.6.1 // indirect
-	github.com/vmware-labs/yaml-jsonpath v0.3.2 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.9-0.20240815153524-6ea36470d1bd // indirect
-	golang.org/x/text v0.21.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/net v0.33.0 // indirect
 )
@@ -0,0 +1,37 @@
+// Package testutil provides the helpers shared by the tests of the
+// module.
+package testutil
+
+import "github.com/bragdonD/arazzo-go/v1/models"
+
+// Ptr returns a pointer to v, e.g. to set the optional string fields
+// of the models.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// PetstoreSource is the name of the source description of the spec
+// models returned by [NewSpecModel].
+const PetstoreSource = "petstore"
+
+// NewSpecModel returns a valid Arazzo 1.0.0 spec model holding
+// workflows, whose single OpenAPI source description, named
+// [PetstoreSource], is located at sourceURL. The petstore test
+// document is v1/test_specs/petstore.openapi.yaml.
+func NewSpecModel(
+	sourceURL string,
+	workflows ...models.Workflow,
+) *models.Spec {
+	return &models.Spec{
+		Arazzo: "1.0.0",
+		Info:   models.Info{Title: "petstore", Version: "1.0.0"},
+		SourcesDescriptions: []models.SourceDescription{
+			{
+				Name: PetstoreSource,
+				Url:  sourceURL,
+				Type: models.SourceDescriptionTypeOpenAPI.ToPtr(),
+			},
+		},
+		Workflows: workflows,
+	}
+}
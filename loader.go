@@ -3,17 +3,35 @@ package arazzo
 import (
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
+// fileScheme is the URL scheme of local files.
+const fileScheme = "file"
+
 // Loader handles file loading with configurable LoaderOptions.
 type Loader struct {
 	httpClient  *http.Client
 	allowRemote bool
 	allowLocal  bool
+	// fsys is the file system local files are read from, nil to use
+	// the operating system's one.
+	fsys fs.FS
+	// documents holds in-memory documents by URL.
+	documents map[string][]byte
+	// cache is the cache of remote documents, nil if they are not
+	// cached.
+	cache *Cache
+	// offline serves remote documents from the cache only.
+	offline bool
 }
 
 // LoaderOption defines a functional LoaderOption for configuring Loader.
@@ -40,12 +58,52 @@ func AllowLocalLookup() LoaderOption {
 	}
 }
 
+// WithFS reads local files from fsys, such as an [embed.FS], instead
+// of the operating system's file system. Paths are cleaned and made
+// relative to the root of fsys, so that "/specs/a.yaml", "./specs/a.yaml"
+// and "specs/a.yaml" designate the same file.
+func WithFS(fsys fs.FS) LoaderOption {
+	return func(l *Loader) {
+		l.fsys = fsys
+	}
+}
+
+// WithDocuments serves the given documents by URL, or path, before
+// looking anything up. In-memory documents are always allowed, no
+// matter the remote and local lookup policy. It can be used more than
+// once.
+func WithDocuments(documents map[string][]byte) LoaderOption {
+	return func(l *Loader) {
+		maps.Copy(l.documents, documents)
+	}
+}
+
+// WithCache caches remote documents in cache. Cached documents are
+// revalidated with their ETag or Last-Modified date, and only fetched
+// again once the server reports they changed.
+func WithCache(cache *Cache) LoaderOption {
+	return func(l *Loader) {
+		l.cache = cache
+	}
+}
+
+// Offline serves remote documents from the cache only, without any
+// network access, including the files referenced by OpenAPI source
+// descriptions. Loading a document which is not cached fails with a
+// [CacheMissError].
+func Offline() LoaderOption {
+	return func(l *Loader) {
+		l.offline = true
+	}
+}
+
 // NewLoader creates a new Loader with LoaderOptional configurations.
 func NewLoader(opts ...LoaderOption) *Loader {
 	l := &Loader{
 		httpClient:  &http.Client{Timeout: 10 * time.Second},
 		allowRemote: false,
 		allowLocal:  false,
+		documents:   map[string][]byte{},
 	}
 	for _, opt := range opts {
 		opt(l)
@@ -63,21 +121,54 @@ func IsRemoteFile(rawURL string) bool {
 }
 
 // LoadFile loads a file, supporting both local and remote paths.
+// Local files may also be designated by a "file" URL.
 func (l *Loader) LoadFile(path string) ([]byte, error) {
+	if data, ok := l.lookupDocument(path); ok {
+		return data, nil
+	}
 	if IsRemoteFile(path) {
 		if !l.allowRemote {
-			return nil, fmt.Errorf("remote file lookup is not allowed")
+			return nil, fmt.Errorf(
+				"remote file lookup is not allowed",
+			)
 		}
 		return l.loadRemoteFile(path)
 	}
 	if !l.allowLocal {
 		return nil, fmt.Errorf("local file lookup is not allowed")
 	}
+	if parsedURL, err := url.Parse(path); err == nil &&
+		parsedURL.Scheme == fileScheme {
+		path = parsedURL.Path
+	}
 	return l.loadLocalFile(path)
 }
 
+// lookupDocument returns the in-memory document of path, which is
+// looked up as is and, for local paths, once cleaned.
+func (l *Loader) lookupDocument(path string) ([]byte, bool) {
+	if data, ok := l.documents[path]; ok {
+		return data, true
+	}
+	if IsRemoteFile(path) {
+		return nil, false
+	}
+	data, ok := l.documents[filepath.Clean(path)]
+	return data, ok
+}
+
 // loadLocalFile reads a file from the local filesystem.
 func (l *Loader) loadLocalFile(path string) ([]byte, error) {
+	if l.fsys != nil {
+		data, err := fs.ReadFile(l.fsys, fsPath(path))
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to read local file: %w",
+				err,
+			)
+		}
+		return data, nil
+	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read local file: %w", err)
@@ -85,21 +176,75 @@ func (l *Loader) loadLocalFile(path string) ([]byte, error) {
 	return data, nil
 }
 
-// loadRemoteFile fetches a file from a remote URL using the configured HTTP client.
+// loadRemoteFile fetches a file from a remote URL using the configured
+// HTTP client, going through the cache if any.
 func (l *Loader) loadRemoteFile(rawURL string) ([]byte, error) {
-	resp, err := l.httpClient.Get(rawURL)
+	var entry *cacheEntry
+	var cached []byte
+	if l.cache != nil {
+		entry, cached, _ = l.cache.lookup(rawURL)
+	}
+	if l.offline {
+		if entry == nil {
+			return nil, &CacheMissError{URL: rawURL}
+		}
+		return cached, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote file: %w", err)
+	}
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	resp, err := l.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch remote file: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		return cached, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+		return nil, fmt.Errorf(
+			"unexpected response status: %d",
+			resp.StatusCode,
+		)
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read remote file: %w", err)
 	}
+	if l.cache != nil {
+		err := l.cache.store(&cacheEntry{
+			URL:          rawURL,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}, data)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return data, nil
 }
+
+// fsPath returns the path of a local file within an [fs.FS], which is
+// slash separated, cleaned and relative to the root.
+func fsPath(path string) string {
+	path = strings.TrimPrefix(
+		pathpkg.Clean(filepath.ToSlash(path)),
+		"/",
+	)
+	if path == "" {
+		return "."
+	}
+	return path
+}
@@ -0,0 +1,99 @@
+package arazzo
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_LoadFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"specs/a.yaml": {Data: []byte("fs")},
+	}
+	documents := map[string][]byte{
+		"https://example.com/a.yaml": []byte("remote"),
+		"specs/b.yaml":               []byte("local"),
+	}
+
+	tests := []struct {
+		name    string
+		opts    []LoaderOption
+		path    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "fs relative path",
+			opts: []LoaderOption{AllowLocalLookup(), WithFS(fsys)},
+			path: "specs/a.yaml",
+			want: "fs",
+		},
+		{
+			name: "fs absolute path",
+			opts: []LoaderOption{AllowLocalLookup(), WithFS(fsys)},
+			path: "/specs/../specs/a.yaml",
+			want: "fs",
+		},
+		{
+			name: "fs file url",
+			opts: []LoaderOption{AllowLocalLookup(), WithFS(fsys)},
+			path: "file:///specs/a.yaml",
+			want: "fs",
+		},
+		{
+			name:    "fs missing file",
+			opts:    []LoaderOption{AllowLocalLookup(), WithFS(fsys)},
+			path:    "specs/c.yaml",
+			wantErr: "failed to read local file: open specs/c.yaml: file does not exist",
+		},
+		{
+			name:    "fs local lookup not allowed",
+			opts:    []LoaderOption{WithFS(fsys)},
+			path:    "specs/a.yaml",
+			wantErr: "local file lookup is not allowed",
+		},
+		{
+			name: "remote document",
+			opts: []LoaderOption{WithDocuments(documents)},
+			path: "https://example.com/a.yaml",
+			want: "remote",
+		},
+		{
+			name: "local document",
+			opts: []LoaderOption{WithDocuments(documents)},
+			path: "./specs/b.yaml",
+			want: "local",
+		},
+		{
+			name: "document takes precedence over fs",
+			opts: []LoaderOption{
+				AllowLocalLookup(),
+				WithFS(fsys),
+				WithDocuments(map[string][]byte{
+					"specs/a.yaml": []byte("memory"),
+				}),
+			},
+			path: "specs/a.yaml",
+			want: "memory",
+		},
+		{
+			name:    "missing remote document",
+			opts:    []LoaderOption{WithDocuments(documents)},
+			path:    "https://example.com/b.yaml",
+			wantErr: "remote file lookup is not allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewLoader(tt.opts...).LoadFile(tt.path)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
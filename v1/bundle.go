@@ -0,0 +1,605 @@
+package v1
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	arazzo "github.com/bragdonD/arazzo-go"
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// BundleManifestFile is the path of the manifest within a bundle.
+	BundleManifestFile = "manifest.json"
+	// bundleSourcesDir is the directory of a bundle holding the
+	// source descriptions.
+	bundleSourcesDir = "sources"
+)
+
+// Bundle is a self-contained copy of an Arazzo document and of its
+// source descriptions, Arazzo source descriptions being bundled
+// recursively. The URLs of the source descriptions are rewritten to
+// relative paths within the bundle, so that the workflows can be run
+// later on without any access to the original locations.
+//
+// Arazzo documents are re-encoded as YAML. OpenAPI documents are
+// bundled along with the files they reference, their references being
+// rewritten to the bundled files. They are only re-encoded, in their
+// original format, if a reference had to be rewritten.
+type Bundle struct {
+	manifest *BundleManifest
+	files    map[string][]byte
+	spec     *Spec
+}
+
+// BundleManifest describes the files of a bundle.
+type BundleManifest struct {
+	// Root is the path of the bundled Arazzo document.
+	Root string `json:"root"`
+	// Files are the files of the bundle, sorted by path. The manifest
+	// itself is not listed.
+	Files []BundleFile `json:"files"`
+}
+
+// BundleFile describes a document of a bundle.
+type BundleFile struct {
+	// Path is the slash separated path of the file within the
+	// bundle.
+	Path string `json:"path"`
+	// URL is the location the document was loaded from.
+	URL string `json:"url"`
+	// Type is the type of the document, either arazzo or openapi.
+	// Files referenced by OpenAPI documents are of the openapi type.
+	Type models.SourceDescriptionType `json:"type"`
+	// SHA256 is the hex encoded SHA-256 of the content of the file.
+	SHA256 string `json:"sha256"`
+}
+
+// bundler holds the state of the creation of a bundle.
+type bundler struct {
+	options *specOptions
+	files   map[string][]byte
+	// paths holds the path within the bundle of the documents by
+	// document key.
+	paths map[string]string
+	// taken holds the paths of the bundled documents.
+	taken    map[string]bool
+	manifest *BundleManifest
+}
+
+// NewBundle bundles the Arazzo document located at url along with its
+// source descriptions, which are loaded with the [arazzo.Loader] of
+// opts, and the files referenced by the OpenAPI source descriptions.
+// The bundled documents are then loaded as a [Spec], so that the
+// bundle is known to be valid and self-contained.
+func NewBundle(url string, opts ...SpecOption) (*Bundle, error) {
+	options := &specOptions{
+		loader:    arazzo.NewLoader(arazzo.AllowLocalLookup()),
+		documents: map[string]*Spec{},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	b := &bundler{
+		options:  options,
+		files:    map[string][]byte{},
+		paths:    map[string]string{},
+		taken:    map[string]bool{},
+		manifest: &BundleManifest{},
+	}
+
+	root := b.uniquePath(
+		bundleFileName(url, models.SourceDescriptionTypeArazzo),
+	)
+	if err := b.addArazzoDocument(url, root); err != nil {
+		return nil, err
+	}
+	b.manifest.Root = root
+	slices.SortFunc(b.manifest.Files, func(a, b BundleFile) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	// The bundle is loaded from its own files only, remote lookups
+	// being disabled, so that a reference left to the original
+	// locations results in an error. The references of the OpenAPI
+	// documents are resolved with the same loader.
+	options.loader = arazzo.NewLoader(
+		arazzo.AllowLocalLookup(),
+		arazzo.WithFS(memFS(b.files)),
+	)
+	oaiOptions := &oaiDocumentOptions{
+		config: DefaultOAIDocumentConfiguration(),
+	}
+	for _, opt := range options.oaiOptions {
+		opt(oaiOptions)
+	}
+	config := *oaiOptions.config
+	config.LocalFS = nil
+	config.RemoteURLHandler = nil
+	options.oaiOptions = append(
+		options.oaiOptions,
+		WithOAILoader(options.loader),
+		WithOAIDocumentConfiguration(&config),
+	)
+	model, err := models.ExtractSpecWithDocumentCheck(b.files[root])
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+	spec, err := newSpec(model, root, options)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+
+	return &Bundle{
+		manifest: b.manifest,
+		files:    b.files,
+		spec:     spec,
+	}, nil
+}
+
+// GetManifest returns the manifest of the bundle.
+func (b *Bundle) GetManifest() *BundleManifest {
+	return b.manifest
+}
+
+// GetFiles returns the content of the files of the bundle by path,
+// the manifest excluded.
+func (b *Bundle) GetFiles() map[string][]byte {
+	return b.files
+}
+
+// GetSpec returns the bundled Arazzo document, loaded from the bundle.
+func (b *Bundle) GetSpec() *Spec {
+	return b.spec
+}
+
+// WriteDir writes the files of the bundle and its manifest within
+// dir, which is created if needed.
+func (b *Bundle) WriteDir(dir string) error {
+	return b.walk(func(name string, data []byte) error {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(file, data, 0o644)
+	})
+}
+
+// WriteTar writes the files of the bundle and its manifest as a tar
+// archive.
+func (b *Bundle) WriteTar(w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := b.walk(func(name string, data []byte) error {
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(data)),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// WriteZip writes the files of the bundle and its manifest as a zip
+// archive.
+func (b *Bundle) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	err := b.walk(func(name string, data []byte) error {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// walk calls write with the manifest and then every file of the
+// bundle, sorted by path, so that archives are reproducible.
+func (b *Bundle) walk(
+	write func(name string, data []byte) error,
+) error {
+	manifest, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle manifest: %w", err)
+	}
+	if err := write(BundleManifestFile, manifest); err != nil {
+		return fmt.Errorf("failed to write bundle manifest: %w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(b.files)) {
+		if err := write(name, b.files[name]); err != nil {
+			return fmt.Errorf("failed to write %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// addArazzoDocument bundles the Arazzo document located at url as
+// name, along with its source descriptions.
+func (b *bundler) addArazzoDocument(url string, name string) error {
+	b.paths[documentKey(url)] = name
+	data, err := b.options.loader.LoadFile(url)
+	if err != nil {
+		return fmt.Errorf("failed to load %q: %w", url, err)
+	}
+	model, err := models.ExtractSpecWithDocumentCheck(data)
+	if err != nil {
+		return fmt.Errorf("failed to load %q: %w", url, err)
+	}
+
+	// urls holds the rewritten URL of the source descriptions by
+	// name.
+	urls := map[string]string{}
+	for _, source := range model.SourcesDescriptions {
+		sourceURL, err := ResolveSourceURL(url, source.Url)
+		if err != nil {
+			return fmt.Errorf(
+				"%q: source description %q: %w",
+				url,
+				source.Name,
+				err,
+			)
+		}
+		sourceType := models.SourceDescriptionTypeOpenAPI
+		if source.Type != nil {
+			sourceType = *source.Type
+		}
+		sourceName, err := b.addSource(sourceURL, sourceType)
+		if err != nil {
+			return fmt.Errorf(
+				"%q: source description %q: %w",
+				url,
+				source.Name,
+				err,
+			)
+		}
+		urls[source.Name] = relativeBundlePath(name, sourceName)
+	}
+
+	data, err = rewriteSourceURLs(data, urls)
+	if err != nil {
+		return fmt.Errorf("failed to bundle %q: %w", url, err)
+	}
+	b.addFile(name, url, models.SourceDescriptionTypeArazzo, data)
+	return nil
+}
+
+// addSource bundles the source description located at url, unless it
+// is already bundled, and returns its path within the bundle.
+func (b *bundler) addSource(
+	url string,
+	sourceType models.SourceDescriptionType,
+) (string, error) {
+	if name, ok := b.paths[documentKey(url)]; ok {
+		return name, nil
+	}
+	name := b.uniquePath(
+		path.Join(bundleSourcesDir, bundleFileName(url, sourceType)),
+	)
+	if sourceType == models.SourceDescriptionTypeArazzo {
+		return name, b.addArazzoDocument(url, name)
+	}
+
+	b.paths[documentKey(url)] = name
+	data, err := b.options.loader.LoadFile(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to load %q: %w", url, err)
+	}
+	data, err = b.addReferences(url, name, data)
+	if err != nil {
+		return "", err
+	}
+	b.addFile(name, url, sourceType, data)
+	return name, nil
+}
+
+// addReferences bundles the files referenced by data, the OpenAPI
+// document located at url and bundled as name, and returns data with
+// the references rewritten to the bundled files.
+func (b *bundler) addReferences(
+	url string,
+	name string,
+	data []byte,
+) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// Files which are neither YAML nor JSON, such as examples,
+		// do not reference other files.
+		return data, nil
+	}
+
+	rewritten := false
+	for _, ref := range collectReferences(&doc, nil) {
+		file, fragment, hasFragment := strings.Cut(ref.Value, "#")
+		if file == "" {
+			continue
+		}
+		fileURL, err := ResolveSourceURL(url, file)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", url, err)
+		}
+		fileName, err := b.addSource(
+			fileURL,
+			models.SourceDescriptionTypeOpenAPI,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", url, err)
+		}
+		bundled := relativeBundlePath(name, fileName)
+		if bundled == file {
+			continue
+		}
+		if hasFragment {
+			bundled += "#" + fragment
+		}
+		ref.Value = bundled
+		rewritten = true
+	}
+	if !rewritten {
+		return data, nil
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 &&
+		trimmed[0] == '{' {
+		data, err := encodeJSON(&doc)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to bundle %q: %w",
+				url,
+				err,
+			)
+		}
+		return data, nil
+	}
+	data, err := encodeYAML(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bundle %q: %w", url, err)
+	}
+	return data, nil
+}
+
+func (b *bundler) addFile(
+	name string,
+	url string,
+	fileType models.SourceDescriptionType,
+	data []byte,
+) {
+	sum := sha256.Sum256(data)
+	b.files[name] = data
+	b.manifest.Files = append(b.manifest.Files, BundleFile{
+		Path:   name,
+		URL:    url,
+		Type:   fileType,
+		SHA256: hex.EncodeToString(sum[:]),
+	})
+}
+
+// uniquePath returns name, suffixed before its extension if another
+// document is already bundled as name.
+func (b *bundler) uniquePath(name string) string {
+	candidate := name
+	ext := path.Ext(name)
+	for i := 2; b.taken[candidate]; i++ {
+		candidate = strings.TrimSuffix(name, ext) + "-" +
+			strconv.Itoa(i) + ext
+	}
+	b.taken[candidate] = true
+	return candidate
+}
+
+// bundleFileName returns the name of the document located at source
+// within a bundle. Arazzo documents being re-encoded as YAML, their
+// JSON extension is replaced.
+func bundleFileName(
+	source string,
+	sourceType models.SourceDescriptionType,
+) string {
+	name := ""
+	if arazzo.IsRemoteFile(source) {
+		if sourceURL, err := url.Parse(source); err == nil {
+			name = path.Base(sourceURL.Path)
+		}
+	} else {
+		name = filepath.Base(localPath(source))
+	}
+	if name == "" || name == "." || name == "/" {
+		name = string(sourceType) + ".yaml"
+	}
+	if sourceType == models.SourceDescriptionTypeArazzo &&
+		path.Ext(name) == ".json" {
+		name = strings.TrimSuffix(name, ".json") + ".yaml"
+	}
+	return name
+}
+
+// relativeBundlePath returns the path of target relative to the
+// directory of from, both being paths within a bundle.
+func relativeBundlePath(from string, target string) string {
+	rel, err := filepath.Rel(
+		filepath.FromSlash(path.Dir(from)),
+		filepath.FromSlash(target),
+	)
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// rewriteSourceURLs sets the URL of the source descriptions of the
+// Arazzo document data to urls, by source description name. The
+// document is re-encoded as YAML, preserving the order of its fields
+// and its comments.
+func rewriteSourceURLs(
+	data []byte,
+	urls map[string]string,
+) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("empty document")
+	}
+	sources := mappingValue(doc.Content[0], "sourceDescriptions")
+	if sources != nil && sources.Kind == yaml.SequenceNode {
+		for _, source := range sources.Content {
+			name := mappingValue(source, "name")
+			sourceURL := mappingValue(source, "url")
+			if name == nil || sourceURL == nil {
+				continue
+			}
+			if rewritten, ok := urls[name.Value]; ok {
+				sourceURL.Value = rewritten
+				sourceURL.Style = 0
+			}
+		}
+	}
+	resetFlowStyle(&doc, false)
+	return encodeYAML(&doc)
+}
+
+// encodeYAML encodes doc as YAML, indented with two spaces.
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeJSON encodes doc, decoded from a JSON document, as indented
+// JSON, preserving the order of its fields.
+func encodeJSON(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSONNode(&buf, doc); err != nil {
+		return nil, err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes(), nil
+}
+
+// writeJSONNode writes node, decoded from a JSON document, to buf as
+// JSON. The scalars which are not strings are written as is.
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return fmt.Errorf("empty document")
+		}
+		return writeJSONNode(buf, node.Content[0])
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key := node.Content[i].Value
+			if err := writeJSONString(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeJSONNode(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONNode(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		if node.Tag == "!!str" {
+			return writeJSONString(buf, node.Value)
+		}
+		buf.WriteString(node.Value)
+	default:
+		return fmt.Errorf(
+			"unexpected YAML node at line %d",
+			node.Line,
+		)
+	}
+	return nil
+}
+
+// writeJSONString writes s to buf as a JSON string, without escaping
+// the HTML characters.
+func writeJSONString(buf *bytes.Buffer, s string) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(s)
+}
+
+// mappingValue returns the value of key within the mapping node, or
+// nil if node is not a mapping or has no such key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// resetFlowStyle switches the mappings and sequences of node to the
+// block style, so that JSON documents are encoded as regular YAML.
+// The scalars they hold are only quoted if they need to be.
+func resetFlowStyle(node *yaml.Node, flow bool) {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		flow = flow || node.Style&yaml.FlowStyle != 0
+		node.Style &^= yaml.FlowStyle
+	case yaml.ScalarNode:
+		if flow {
+			node.Style &^= yaml.DoubleQuotedStyle
+		}
+	}
+	for _, child := range node.Content {
+		resetFlowStyle(child, flow)
+	}
+}
@@ -0,0 +1,250 @@
+package v1
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	arazzo "github.com/bragdonD/arazzo-go"
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bundleTestDocument is an Arazzo document referencing the petstore
+// OpenAPI document both directly and through an Arazzo document.
+const bundleTestDocument = `{
+  "arazzo": "1.0.0",
+  "info": {"title": "main", "version": "1.0.0"},
+  "sourceDescriptions": [
+    {"name": "petstore", "url": "test_specs/petstore.openapi.yaml", "type": "openapi"},
+    {"name": "shared", "url": "test_specs/shared.arazzo.yaml", "type": "arazzo"}
+  ],
+  "workflows": [
+    {
+      "workflowId": "main",
+      "dependsOn": ["$sourceDescriptions.shared.setup"],
+      "steps": [{"stepId": "login", "operationId": "$sourceDescriptions.petstore.loginUser"}]
+    }
+  ]
+}`
+
+func TestNewBundle(t *testing.T) {
+	loader := arazzo.NewLoader(
+		arazzo.AllowLocalLookup(),
+		arazzo.WithDocuments(map[string][]byte{
+			"main.arazzo.json": []byte(bundleTestDocument),
+		}),
+	)
+	bundle, err := NewBundle("main.arazzo.json", WithLoader(loader))
+	require.NoError(t, err)
+
+	manifest := bundle.GetManifest()
+	assert.Equal(t, "main.arazzo.yaml", manifest.Root)
+	paths := []string{}
+	for _, file := range manifest.Files {
+		paths = append(paths, file.Path)
+		sum := sha256.Sum256(bundle.GetFiles()[file.Path])
+		assert.Equal(t, hex.EncodeToString(sum[:]), file.SHA256)
+	}
+	assert.Equal(t, []string{
+		"main.arazzo.yaml",
+		"sources/petstore.openapi.yaml",
+		"sources/shared.arazzo.yaml",
+	}, paths)
+	assert.Equal(t, BundleFile{
+		Path:   "sources/shared.arazzo.yaml",
+		URL:    "test_specs/shared.arazzo.yaml",
+		Type:   models.SourceDescriptionTypeArazzo,
+		SHA256: manifest.Files[2].SHA256,
+	}, manifest.Files[2])
+
+	root, err := models.ExtractSpecWithDocumentCheck(
+		bundle.GetFiles()["main.arazzo.yaml"],
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"sources/petstore.openapi.yaml",
+		root.SourcesDescriptions[0].Url,
+	)
+	assert.Equal(
+		t,
+		"sources/shared.arazzo.yaml",
+		root.SourcesDescriptions[1].Url,
+	)
+	shared, ok := bundle.GetSpec().GetArazzoDocument("shared")
+	require.True(t, ok)
+	assert.Equal(
+		t,
+		"petstore.openapi.yaml",
+		shared.GetModel().SourcesDescriptions[0].Url,
+	)
+	openapi, err := os.ReadFile("test_specs/petstore.openapi.yaml")
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		openapi,
+		bundle.GetFiles()["sources/petstore.openapi.yaml"],
+	)
+
+	var archive bytes.Buffer
+	require.NoError(t, bundle.WriteZip(&archive))
+	reader, err := zip.NewReader(
+		bytes.NewReader(archive.Bytes()),
+		int64(archive.Len()),
+	)
+	require.NoError(t, err)
+	spec, err := NewSpec(
+		root,
+		"main.arazzo.yaml",
+		WithLoader(arazzo.NewLoader(
+			arazzo.AllowLocalLookup(),
+			arazzo.WithFS(reader),
+		)),
+	)
+	require.NoError(t, err)
+	_, err = spec.GetWorkflowByReference(
+		"$sourceDescriptions.shared.login",
+	)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, bundle.WriteDir(dir))
+	_, err = os.Stat(filepath.Join(dir, BundleManifestFile))
+	assert.NoError(t, err)
+	data, err := os.ReadFile(
+		filepath.Join(dir, "sources", "shared.arazzo.yaml"),
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		bundle.GetFiles()["sources/shared.arazzo.yaml"],
+		data,
+	)
+}
+
+func TestNewBundle_Errors(t *testing.T) {
+	_, err := NewBundle("test_specs/cycle_a.arazzo.yaml")
+	assert.ErrorContains(t, err, "circular reference")
+
+	_, err = NewBundle("test_specs/missing.arazzo.yaml")
+	assert.ErrorContains(
+		t,
+		err,
+		`failed to load "test_specs/missing.arazzo.yaml"`,
+	)
+}
+
+func TestNewBundle_OpenAPIReferences(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.arazzo.yaml": &fstest.MapFile{
+			Data: []byte(`arazzo: 1.0.0
+info:
+  title: main
+  version: 1.0.0
+sourceDescriptions:
+  - name: pets
+    url: specs/api.json
+    type: openapi
+workflows:
+  - workflowId: main
+    steps:
+      - stepId: getPet
+        operationId: getPet
+`),
+		},
+		"specs/api.json": &fstest.MapFile{Data: []byte(`{
+  "openapi": "3.1.0",
+  "info": {"title": "Pets <v1>", "version": "1.0.0"},
+  "paths": {
+    "/pet": {
+      "get": {
+        "operationId": "getPet",
+        "responses": {
+          "200": {
+            "description": "successful operation",
+            "content": {
+              "application/json": {
+                "schema": {"$ref": "schemas/pet.yaml#/Pet"}
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`)},
+	}
+	for _, name := range []string{
+		"specs/schemas/pet.yaml",
+		"specs/common.yaml",
+	} {
+		fsys[name] = &fstest.MapFile{
+			Data: []byte(referencesTestFiles[name]),
+		}
+	}
+	bundle, err := NewBundle("main.arazzo.yaml", WithLoader(
+		arazzo.NewLoader(
+			arazzo.AllowLocalLookup(),
+			arazzo.WithFS(fsys),
+		),
+	))
+	require.NoError(t, err)
+
+	paths := []string{}
+	for _, file := range bundle.GetManifest().Files {
+		paths = append(paths, file.Path)
+	}
+	assert.Equal(t, []string{
+		"main.arazzo.yaml",
+		"sources/api.json",
+		"sources/common.yaml",
+		"sources/pet.yaml",
+	}, paths)
+	files := bundle.GetFiles()
+	api := string(files["sources/api.json"])
+	assert.Contains(t, api, `"title": "Pets <v1>"`)
+	assert.Contains(t, api, `"$ref": "pet.yaml#/Pet"`)
+	pet := string(files["sources/pet.yaml"])
+	assert.Contains(t, pet, `$ref: "#/Id"`)
+	assert.Contains(t, pet, `$ref: "common.yaml#/Category"`)
+	assert.Equal(
+		t,
+		referencesTestFiles["specs/common.yaml"],
+		string(files["sources/common.yaml"]),
+	)
+
+	bundled := fstest.MapFS{}
+	for name, data := range files {
+		bundled[name] = &fstest.MapFile{Data: data}
+	}
+	root, err := models.ExtractSpecWithDocumentCheck(
+		files["main.arazzo.yaml"],
+	)
+	require.NoError(t, err)
+	spec, err := NewSpec(
+		root,
+		"main.arazzo.yaml",
+		WithLoader(arazzo.NewLoader(
+			arazzo.AllowLocalLookup(),
+			arazzo.WithFS(bundled),
+		)),
+	)
+	require.NoError(t, err)
+	doc, ok := spec.GetOAIDocument("pets")
+	require.True(t, ok)
+	operation, err := doc.GetOperationById("getPet")
+	require.NoError(t, err)
+	schema := operation.Operation.Responses.Codes.GetOrZero("200").
+		Content.GetOrZero("application/json").Schema.Schema()
+	require.NotNil(t, schema)
+	category := schema.Properties.GetOrZero("category").Schema()
+	require.NotNil(t, category)
+	assert.Equal(t, "The category of the pet.", category.Description)
+}
@@ -18,7 +18,9 @@ import "github.com/bragdonD/arazzo-go/v1/models"
 // workflow referenced in “B”.
 type Components struct {
 	model *models.Components
-	// TODO: ADD a field for the input schema using jsonschema
+	// inputs holds the reusable input schemas by name, they are
+	// compiled along with the input schemas of the workflows.
+	inputs         map[string]any
 	parameters     map[string]*Parameter
 	successActions map[string]*SuccessAction
 	failureActions map[string]*FailureAction
@@ -27,14 +29,23 @@ type Components struct {
 func NewComponents(model *models.Components) *Components {
 	components := &Components{
 		model:          model,
+		inputs:         map[string]any{},
 		parameters:     map[string]*Parameter{},
 		successActions: map[string]*SuccessAction{},
 		failureActions: map[string]*FailureAction{},
 	}
 
-	for _, param := range model.Parameters {
+	if model == nil {
+		return components
+	}
+
+	for name, schema := range model.Inputs {
+		components.inputs[name] = schema
+	}
+
+	for name, param := range model.Parameters {
 		parameter := NewParameter(&param)
-		components.parameters[param.Name] = parameter
+		components.parameters[name] = parameter
 	}
 
 	// TODO: Add success actions
@@ -46,6 +57,11 @@ func (c *Components) GetModel() *models.Components {
 	return c.model
 }
 
+// GetInputs returns the reusable input schemas by name.
+func (c *Components) GetInputs() map[string]any {
+	return c.inputs
+}
+
 func (c *Components) GetParameters() map[string]*Parameter {
 	return c.parameters
 }
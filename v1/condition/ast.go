@@ -0,0 +1,162 @@
+package condition
+
+import "github.com/bragdonD/arazzo-go/v1/expression"
+
+// Visitor interface defines methods for visiting different types of
+// nodes in the AST.
+type Visitor interface {
+	VisitLiteralNode(*LiteralNode) any
+	VisitRuntimeExpressionNode(*RuntimeExpressionNode) any
+	VisitGroupingNode(*GroupingNode) any
+	VisitUnaryNode(*UnaryNode) any
+	VisitBinaryNode(*BinaryNode) any
+	VisitLogicalNode(*LogicalNode) any
+	VisitIndexNode(*IndexNode) any
+	VisitPropertyNode(*PropertyNode) any
+}
+
+// Expr interface defines a method for accepting a visitor.
+type Expr interface {
+	Accept(Visitor) any
+	// Pos returns the position of the node in the condition.
+	Pos() int
+}
+
+// LiteralNode represents a number, string, boolean or null literal.
+type LiteralNode struct {
+	Value    any
+	Position int
+}
+
+// Accept method for LiteralNode to accept a visitor.
+func (n *LiteralNode) Accept(visitor Visitor) any {
+	return visitor.VisitLiteralNode(n)
+}
+
+// Pos method returns the position of the literal.
+func (n *LiteralNode) Pos() int {
+	return n.Position
+}
+
+// RuntimeExpressionNode represents a runtime expression used as an
+// operand.
+type RuntimeExpressionNode struct {
+	// Value is the runtime expression as written in the condition.
+	Value      string
+	Expression expression.Expr
+	Position   int
+}
+
+// Accept method for RuntimeExpressionNode to accept a visitor.
+func (n *RuntimeExpressionNode) Accept(visitor Visitor) any {
+	return visitor.VisitRuntimeExpressionNode(n)
+}
+
+// Pos method returns the position of the runtime expression.
+func (n *RuntimeExpressionNode) Pos() int {
+	return n.Position
+}
+
+// GroupingNode represents a parenthesized condition.
+type GroupingNode struct {
+	Expr     Expr
+	Position int
+}
+
+// Accept method for GroupingNode to accept a visitor.
+func (n *GroupingNode) Accept(visitor Visitor) any {
+	return visitor.VisitGroupingNode(n)
+}
+
+// Pos method returns the position of the opening parenthesis.
+func (n *GroupingNode) Pos() int {
+	return n.Position
+}
+
+// UnaryNode represents the negation of an operand.
+type UnaryNode struct {
+	Operator LexerToken
+	Operand  Expr
+}
+
+// Accept method for UnaryNode to accept a visitor.
+func (n *UnaryNode) Accept(visitor Visitor) any {
+	return visitor.VisitUnaryNode(n)
+}
+
+// Pos method returns the position of the operator.
+func (n *UnaryNode) Pos() int {
+	return n.Operator.Position
+}
+
+// BinaryNode represents a comparison between two operands.
+type BinaryNode struct {
+	Left     Expr
+	Operator LexerToken
+	Right    Expr
+}
+
+// Accept method for BinaryNode to accept a visitor.
+func (n *BinaryNode) Accept(visitor Visitor) any {
+	return visitor.VisitBinaryNode(n)
+}
+
+// Pos method returns the position of the operator.
+func (n *BinaryNode) Pos() int {
+	return n.Operator.Position
+}
+
+// LogicalNode represents a logical "&&" or "||" between two operands.
+// The right operand is only evaluated if the left one does not
+// determine the result.
+type LogicalNode struct {
+	Left     Expr
+	Operator LexerToken
+	Right    Expr
+}
+
+// Accept method for LogicalNode to accept a visitor.
+func (n *LogicalNode) Accept(visitor Visitor) any {
+	return visitor.VisitLogicalNode(n)
+}
+
+// Pos method returns the position of the operator.
+func (n *LogicalNode) Pos() int {
+	return n.Operator.Position
+}
+
+// IndexNode represents the access to an array element or an object
+// member using the "[]" operator.
+type IndexNode struct {
+	Target   Expr
+	Index    Expr
+	Position int
+}
+
+// Accept method for IndexNode to accept a visitor.
+func (n *IndexNode) Accept(visitor Visitor) any {
+	return visitor.VisitIndexNode(n)
+}
+
+// Pos method returns the position of the opening bracket.
+func (n *IndexNode) Pos() int {
+	return n.Position
+}
+
+// PropertyNode represents the access to an object member using the
+// "." operator.
+type PropertyNode struct {
+	Target   Expr
+	Name     string
+	Position int
+}
+
+// Accept method for PropertyNode to accept a visitor.
+func (n *PropertyNode) Accept(visitor Visitor) any {
+	return visitor.VisitPropertyNode(n)
+}
+
+// Pos method returns the position of the property name.
+func (n *PropertyNode) Pos() int {
+	return n.Position
+}
@@ -0,0 +1,370 @@
+package condition
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bragdonD/arazzo-go/v1/expression"
+)
+
+// Resolver resolves the runtime expressions used as operands in a
+// condition.
+type Resolver interface {
+	Resolve(expr expression.Expr) (any, error)
+}
+
+// EvaluationError is returned when a condition cannot be evaluated,
+// for example when an operand has an unexpected type or a runtime
+// expression cannot be resolved.
+type EvaluationError struct {
+	Err      error
+	Position int
+}
+
+// Error returns a formatted error message indicating the position
+// and the cause of the evaluation error.
+func (e *EvaluationError) Error() string {
+	return fmt.Sprintf(
+		"arazzo-go: condition: evaluation error at pos: %d: %v",
+		e.Position,
+		e.Err,
+	)
+}
+
+// Unwrap returns the cause of the evaluation error.
+func (e *EvaluationError) Unwrap() error {
+	return e.Err
+}
+
+// evaluator is a visitor that evaluates a condition. String
+// comparisons are case insensitive, and strings are converted to
+// numbers or booleans when compared to one.
+type evaluator struct {
+	resolver Resolver
+	// err stores any encountered error during traversal.
+	err error
+}
+
+// evaluate evaluates expr. It returns false if an error occurred.
+func (e *evaluator) evaluate(expr Expr) (any, bool) {
+	value := expr.Accept(e)
+	return value, e.err == nil
+}
+
+// fail records an [EvaluationError] positioned at expr and returns
+// nil.
+func (e *evaluator) fail(expr Expr, err error) any {
+	e.err = &EvaluationError{Err: err, Position: expr.Pos()}
+	return nil
+}
+
+// VisitLiteralNode returns the value of the literal.
+func (e *evaluator) VisitLiteralNode(n *LiteralNode) any {
+	return n.Value
+}
+
+// VisitRuntimeExpressionNode resolves the runtime expression.
+func (e *evaluator) VisitRuntimeExpressionNode(
+	n *RuntimeExpressionNode,
+) any {
+	value, err := e.resolver.Resolve(n.Expression)
+	if err != nil {
+		return e.fail(n, err)
+	}
+	return value
+}
+
+// VisitGroupingNode evaluates the parenthesized condition.
+func (e *evaluator) VisitGroupingNode(n *GroupingNode) any {
+	return n.Expr.Accept(e)
+}
+
+// VisitUnaryNode evaluates the negation of a boolean.
+func (e *evaluator) VisitUnaryNode(n *UnaryNode) any {
+	operand, ok := e.evaluate(n.Operand)
+	if !ok {
+		return nil
+	}
+	value, ok := toBool(operand)
+	if !ok {
+		return e.fail(n.Operand, fmt.Errorf(
+			"operand of '!' must be a boolean, got %T",
+			operand,
+		))
+	}
+	return !value
+}
+
+// VisitLogicalNode evaluates the "&&" and "||" operators.
+func (e *evaluator) VisitLogicalNode(n *LogicalNode) any {
+	left, ok := e.evaluateBool(n.Left, n.Operator)
+	if !ok {
+		return nil
+	}
+	if n.Operator.Type == OrToken && left {
+		return true
+	}
+	if n.Operator.Type == AndToken && !left {
+		return false
+	}
+	right, ok := e.evaluateBool(n.Right, n.Operator)
+	if !ok {
+		return nil
+	}
+	return right
+}
+
+// evaluateBool evaluates expr, an operand of operator, which must
+// result in a boolean.
+func (e *evaluator) evaluateBool(
+	expr Expr,
+	operator LexerToken,
+) (bool, bool) {
+	operand, ok := e.evaluate(expr)
+	if !ok {
+		return false, false
+	}
+	value, ok := toBool(operand)
+	if !ok {
+		e.fail(expr, fmt.Errorf(
+			"operands of '%s' must be booleans, got %T",
+			operator.Value,
+			operand,
+		))
+		return false, false
+	}
+	return value, true
+}
+
+// VisitBinaryNode evaluates the comparison operators.
+func (e *evaluator) VisitBinaryNode(n *BinaryNode) any {
+	left, ok := e.evaluate(n.Left)
+	if !ok {
+		return nil
+	}
+	right, ok := e.evaluate(n.Right)
+	if !ok {
+		return nil
+	}
+
+	switch n.Operator.Type {
+	case EqualToken:
+		return equal(left, right)
+	case NotEqualToken:
+		return !equal(left, right)
+	}
+
+	order, err := compare(left, right)
+	if err != nil {
+		return e.fail(n, err)
+	}
+	switch n.Operator.Type {
+	case LessToken:
+		return order < 0
+	case LessEqualToken:
+		return order <= 0
+	case GreaterToken:
+		return order > 0
+	case GreaterEqualToken:
+		return order >= 0
+	}
+	return e.fail(
+		n,
+		fmt.Errorf("unknown operator %q", n.Operator.Value),
+	)
+}
+
+// VisitIndexNode evaluates the access to an array element or an
+// object member.
+func (e *evaluator) VisitIndexNode(n *IndexNode) any {
+	target, ok := e.evaluate(n.Target)
+	if !ok {
+		return nil
+	}
+	index, ok := e.evaluate(n.Index)
+	if !ok {
+		return nil
+	}
+
+	switch t := target.(type) {
+	case []any:
+		number, ok := toNumber(index)
+		if !ok || number != math.Trunc(number) {
+			return e.fail(n.Index, fmt.Errorf(
+				"array index must be an integer, got %v",
+				index,
+			))
+		}
+		i := int(number)
+		if i < 0 || i >= len(t) {
+			return e.fail(n.Index, fmt.Errorf(
+				"index %d is out of range for an array of length %d",
+				i,
+				len(t),
+			))
+		}
+		return t[i]
+	case map[string]any:
+		name, ok := index.(string)
+		if !ok {
+			return e.fail(n.Index, fmt.Errorf(
+				"object index must be a string, got %T",
+				index,
+			))
+		}
+		return e.member(n, t, name)
+	}
+	return e.fail(n, fmt.Errorf("cannot index a %T", target))
+}
+
+// VisitPropertyNode evaluates the access to an object member.
+func (e *evaluator) VisitPropertyNode(n *PropertyNode) any {
+	target, ok := e.evaluate(n.Target)
+	if !ok {
+		return nil
+	}
+	object, ok := target.(map[string]any)
+	if !ok {
+		return e.fail(n, fmt.Errorf(
+			"cannot access property %q of a %T",
+			n.Name,
+			target,
+		))
+	}
+	return e.member(n, object, n.Name)
+}
+
+// member returns the member name of object.
+func (e *evaluator) member(
+	n Expr,
+	object map[string]any,
+	name string,
+) any {
+	value, ok := object[name]
+	if !ok {
+		return e.fail(n, fmt.Errorf("property %q not found", name))
+	}
+	return value
+}
+
+// equal reports whether two operands are equal. Strings are compared
+// case insensitively and converted to numbers or booleans when
+// compared to one.
+func equal(left, right any) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	if l, r, ok := toNumbers(left, right); ok {
+		return l == r
+	}
+	if l, ok := left.(bool); ok {
+		r, ok := toBool(right)
+		return ok && l == r
+	}
+	if r, ok := right.(bool); ok {
+		l, ok := toBool(left)
+		return ok && l == r
+	}
+	if l, ok := left.(string); ok {
+		r, ok := right.(string)
+		return ok && strings.EqualFold(l, r)
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// compare returns the order of two numbers or two strings. Strings
+// are compared case insensitively.
+func compare(left, right any) (int, error) {
+	if l, r, ok := toNumbers(left, right); ok {
+		switch {
+		case l < r:
+			return -1, nil
+		case l > r:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	l, lok := left.(string)
+	r, rok := right.(string)
+	if lok && rok {
+		return strings.Compare(
+			strings.ToLower(l),
+			strings.ToLower(r),
+		), nil
+	}
+	return 0, fmt.Errorf("cannot compare %T with %T", left, right)
+}
+
+// toNumbers converts both operands to numbers. A string is converted
+// only when the other operand is a number.
+func toNumbers(left, right any) (float64, float64, bool) {
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+	if lok && rok {
+		return l, r, true
+	}
+	if lok {
+		if s, ok := right.(string); ok {
+			r, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			return l, r, err == nil
+		}
+	}
+	if rok {
+		if s, ok := left.(string); ok {
+			l, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			return l, r, err == nil
+		}
+	}
+	return 0, 0, false
+}
+
+// toNumber converts a numeric value to a float64.
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toBool converts a boolean, or a string representing a boolean, to a
+// bool.
+func toBool(value any) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.ToLower(v))
+		return b, err == nil
+	}
+	return false, false
+}
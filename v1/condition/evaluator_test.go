@@ -0,0 +1,142 @@
+package condition_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bragdonD/arazzo-go/v1/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate_Operators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		// Precedence and associativity.
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"false && false || true", true},
+		{"!false && false", false},
+		{"!(false && false)", true},
+		{"1 < 2 == true", true},
+		{"2 > 1 == 1 > 2", false},
+		// Short-circuit evaluation of the right operand.
+		{"false && $inputs.unknown", false},
+		{"true || $inputs.unknown", true},
+		// Number comparisons.
+		{"$statusCode == 200.0", true},
+		{"$inputs.count == 3", true},
+		{"$inputs.count > $inputs.limit", true},
+		{"-1 < 0", true},
+		{"1e2 >= 100", true},
+		{"0.1 <= 0.05", false},
+		// Strings are converted to numbers when compared to one.
+		{"'200' == $statusCode", true},
+		{"$statusCode != ' 200 '", false},
+		{"'10' > 9", true},
+		{"'abc' == 1", false},
+		{"'1e2' == 100", true},
+		// Strings are converted to booleans when compared to one.
+		{"$inputs.flag == true", true},
+		{"'False' == false", true},
+		{"'yes' == true", false},
+		{"!$inputs.flag", false},
+		// String comparisons are case insensitive.
+		{"$inputs.name == 'REX'", true},
+		{"'Sold' != 'sold'", false},
+		{"'b' > 'A'", true},
+		{"'abc' < 'ABD'", true},
+		// Null, arrays and objects.
+		{"$inputs.nothing == null", true},
+		{"null == 0", false},
+		{"null != false", true},
+		{"$inputs.list[1] == 'B'", true},
+		{"$inputs.list[1.0] == 'b'", true},
+		{"($inputs.object).key == 'VALUE'", true},
+		{"$inputs.object['key'] == ($inputs.object).key", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := condition.Evaluate(tt.input, testValues)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestEvaluate_Errors(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+		message  string
+	}{
+		{"1", 0, "condition must result in a boolean, got float64"},
+		{
+			"$inputs.name",
+			0,
+			"condition must result in a boolean, got string",
+		},
+		{"'a' < 1", 4, "cannot compare string with float64"},
+		{"null > 1", 5, "cannot compare <nil> with float64"},
+		{
+			"!'maybe'",
+			1,
+			"operand of '!' must be a boolean, got string",
+		},
+		{
+			"1 && true",
+			0,
+			"operands of '&&' must be booleans, got float64",
+		},
+		{
+			"false || 'no'",
+			9,
+			"operands of '||' must be booleans, got string",
+		},
+		{"$inputs.unknown == 1", 0, "unresolved runtime expression"},
+		{
+			"$inputs.list[2] == 'c'",
+			13,
+			"index 2 is out of range for an array of length 2",
+		},
+		{
+			"$inputs.list[0.5] == 'a'",
+			13,
+			"array index must be an integer, got 0.5",
+		},
+		{
+			"$inputs.list['a'] == 'a'",
+			13,
+			"array index must be an integer, got a",
+		},
+		{
+			"$inputs.object[1] == 1",
+			15,
+			"object index must be a string, got float64",
+		},
+		{
+			"($inputs.object).other == 1",
+			17,
+			`property "other" not found`,
+		},
+		{
+			"($inputs.name).length == 3",
+			15,
+			`cannot access property "length" of a string`,
+		},
+		{"$inputs.name[0] == 'R'", 12, "cannot index a string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := condition.Evaluate(tt.input, testValues)
+			var evalErr *condition.EvaluationError
+			require.True(t, errors.As(err, &evalErr), "got %v", err)
+			assert.Equal(t, tt.position, evalErr.Position)
+			assert.EqualError(t, evalErr.Err, tt.message)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package condition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lexer splits a simple condition into tokens.
+type Lexer struct {
+	input string
+}
+
+// NewLexer creates a new Lexer instance.
+func NewLexer(input string) *Lexer {
+	return &Lexer{
+		input: input,
+	}
+}
+
+// LexerTokenType represents the type of tokens recognized by the
+// lexer.
+type LexerTokenType = int
+
+// Token types of the simple condition syntax.
+const (
+	// Token corresponding to a number literal.
+	NumberToken LexerTokenType = iota
+	// Token corresponding to a string literal.
+	StringToken
+	// Token corresponding to the "true" literal.
+	TrueToken
+	// Token corresponding to the "false" literal.
+	FalseToken
+	// Token corresponding to the "null" literal.
+	NullToken
+	// Token corresponding to a runtime expression.
+	RuntimeExpressionToken
+	// Token corresponding to a property name following a ".".
+	IdentifierToken
+	// Token corresponding to "(".
+	LeftParenToken
+	// Token corresponding to ")".
+	RightParenToken
+	// Token corresponding to "[".
+	LeftBracketToken
+	// Token corresponding to "]".
+	RightBracketToken
+	// Token corresponding to ".".
+	DotToken
+	// Token corresponding to "!".
+	NotToken
+	// Token corresponding to "==".
+	EqualToken
+	// Token corresponding to "!=".
+	NotEqualToken
+	// Token corresponding to "<".
+	LessToken
+	// Token corresponding to "<=".
+	LessEqualToken
+	// Token corresponding to ">".
+	GreaterToken
+	// Token corresponding to ">=".
+	GreaterEqualToken
+	// Token corresponding to "&&".
+	AndToken
+	// Token corresponding to "||".
+	OrToken
+	// Token marking the end of the input.
+	EOFToken
+)
+
+// operators maps the operators and punctuation of the syntax to their
+// token type. Two characters operators are listed first so that they
+// take precedence over their one character prefix.
+var operators = []struct {
+	value     string
+	tokenType LexerTokenType
+}{
+	{"==", EqualToken},
+	{"!=", NotEqualToken},
+	{"<=", LessEqualToken},
+	{">=", GreaterEqualToken},
+	{"&&", AndToken},
+	{"||", OrToken},
+	{"<", LessToken},
+	{">", GreaterToken},
+	{"!", NotToken},
+	{"(", LeftParenToken},
+	{")", RightParenToken},
+	{"[", LeftBracketToken},
+	{"]", RightBracketToken},
+	{".", DotToken},
+}
+
+// keywords maps the literal keywords to their token type.
+var keywords = map[string]LexerTokenType{
+	"true":  TrueToken,
+	"false": FalseToken,
+	"null":  NullToken,
+}
+
+// runtimeExpressionDelimiters are the characters ending a runtime
+// expression embedded in a condition, in addition to white spaces.
+const runtimeExpressionDelimiters = "()[]=!<>&|'\""
+
+// LexerToken represents a token identified by the lexer, including
+// its type, value, and position in the input string.
+type LexerToken struct {
+	Type     LexerTokenType
+	Value    string
+	Position int
+}
+
+// SyntaxError is returned when a condition does not conform to the
+// simple condition syntax.
+type SyntaxError struct {
+	Message  string
+	Position int
+}
+
+// Error returns a formatted error message indicating the position
+// and the cause of the syntax error.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf(
+		"arazzo-go: condition: syntax error at pos: %d: %s",
+		e.Position,
+		e.Message,
+	)
+}
+
+// Tokenize splits the input string into a slice of LexerTokens. The
+// slice always ends with an [EOFToken].
+func (l *Lexer) Tokenize() ([]LexerToken, error) {
+	tokens := []LexerToken{}
+	position := 0
+
+lexerStart:
+	for position < len(l.input) {
+		char := l.input[position]
+		switch {
+		case isSpace(char):
+			position++
+			continue
+		case char == '$':
+			end := position + 1
+			for end < len(l.input) &&
+				!isSpace(l.input[end]) &&
+				!strings.ContainsRune(runtimeExpressionDelimiters, rune(l.input[end])) {
+				end++
+			}
+			tokens = append(tokens, LexerToken{
+				Type:     RuntimeExpressionToken,
+				Value:    l.input[position:end],
+				Position: position,
+			})
+			position = end
+			continue
+		case char == '\'' || char == '"':
+			value, end, err := l.string(position)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, LexerToken{
+				Type:     StringToken,
+				Value:    value,
+				Position: position,
+			})
+			position = end
+			continue
+		case isDigit(char) || (char == '-' &&
+			position+1 < len(l.input) && isDigit(l.input[position+1])):
+			end := l.number(position)
+			tokens = append(tokens, LexerToken{
+				Type:     NumberToken,
+				Value:    l.input[position:end],
+				Position: position,
+			})
+			position = end
+			continue
+		case isIdentifierChar(char):
+			end := position
+			for end < len(l.input) && isIdentifierChar(l.input[end]) {
+				end++
+			}
+			value := l.input[position:end]
+			tokenType, ok := keywords[value]
+			if !ok {
+				tokenType = IdentifierToken
+			}
+			tokens = append(tokens, LexerToken{
+				Type:     tokenType,
+				Value:    value,
+				Position: position,
+			})
+			position = end
+			continue
+		}
+
+		for _, operator := range operators {
+			if strings.HasPrefix(l.input[position:], operator.value) {
+				tokens = append(tokens, LexerToken{
+					Type:     operator.tokenType,
+					Value:    operator.value,
+					Position: position,
+				})
+				position += len(operator.value)
+				continue lexerStart
+			}
+		}
+
+		return nil, &SyntaxError{
+			Message:  fmt.Sprintf("unexpected character %q", char),
+			Position: position,
+		}
+	}
+
+	tokens = append(tokens, LexerToken{
+		Type:     EOFToken,
+		Position: position,
+	})
+	return tokens, nil
+}
+
+// string scans the string literal starting at position. It returns
+// the unescaped value of the literal and the position following its
+// closing quote. A backslash escapes the character following it.
+func (l *Lexer) string(position int) (string, int, error) {
+	quote := l.input[position]
+	builder := strings.Builder{}
+	for end := position + 1; end < len(l.input); end++ {
+		switch l.input[end] {
+		case '\\':
+			end++
+			if end < len(l.input) {
+				builder.WriteByte(l.input[end])
+			}
+		case quote:
+			return builder.String(), end + 1, nil
+		default:
+			builder.WriteByte(l.input[end])
+		}
+	}
+	return "", 0, &SyntaxError{
+		Message:  "unterminated string literal",
+		Position: position,
+	}
+}
+
+// number scans the number literal starting at position and returns
+// the position following it.
+func (l *Lexer) number(position int) int {
+	end := position
+	if l.input[end] == '-' {
+		end++
+	}
+	end = l.digits(end)
+	if end+1 < len(l.input) && l.input[end] == '.' &&
+		isDigit(l.input[end+1]) {
+		end = l.digits(end + 1)
+	}
+	if end < len(l.input) &&
+		(l.input[end] == 'e' || l.input[end] == 'E') {
+		exponent := end + 1
+		if exponent < len(l.input) &&
+			(l.input[exponent] == '+' || l.input[exponent] == '-') {
+			exponent++
+		}
+		if exponent < len(l.input) && isDigit(l.input[exponent]) {
+			end = l.digits(exponent)
+		}
+	}
+	return end
+}
+
+// digits returns the position following the digits starting at
+// position.
+func (l *Lexer) digits(position int) int {
+	for position < len(l.input) && isDigit(l.input[position]) {
+		position++
+	}
+	return position
+}
+
+// isSpace reports whether char is a white space.
+func isSpace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r'
+}
+
+// isDigit reports whether char is a decimal digit.
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+// isIdentifierChar reports whether char can be part of a keyword or a
+// property name.
+func isIdentifierChar(char byte) bool {
+	return char >= 'a' && char <= 'z' ||
+		char >= 'A' && char <= 'Z' ||
+		isDigit(char) || char == '_' || char == '-'
+}
@@ -0,0 +1,142 @@
+package condition_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bragdonD/arazzo-go/v1/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// token returns the token of the given type, value and position.
+func token(
+	tokenType condition.LexerTokenType,
+	value string,
+	position int,
+) condition.LexerToken {
+	return condition.LexerToken{
+		Type:     tokenType,
+		Value:    value,
+		Position: position,
+	}
+}
+
+func TestLexer_Tokenize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []condition.LexerToken
+	}{
+		{
+			input: "$statusCode == 200",
+			expected: []condition.LexerToken{
+				token(
+					condition.RuntimeExpressionToken,
+					"$statusCode",
+					0,
+				),
+				token(condition.EqualToken, "==", 12),
+				token(condition.NumberToken, "200", 15),
+				token(condition.EOFToken, "", 18),
+			},
+		},
+		{
+			input: "$response.body#/id!=1",
+			expected: []condition.LexerToken{
+				token(
+					condition.RuntimeExpressionToken,
+					"$response.body#/id",
+					0,
+				),
+				token(condition.NotEqualToken, "!=", 18),
+				token(condition.NumberToken, "1", 20),
+				token(condition.EOFToken, "", 21),
+			},
+		},
+		{
+			input: `'it\'s' "a\"b"`,
+			expected: []condition.LexerToken{
+				token(condition.StringToken, "it's", 0),
+				token(condition.StringToken, `a"b`, 8),
+				token(condition.EOFToken, "", 14),
+			},
+		},
+		{
+			input: "-1.5e3 2E-2 3. -x",
+			expected: []condition.LexerToken{
+				token(condition.NumberToken, "-1.5e3", 0),
+				token(condition.NumberToken, "2E-2", 7),
+				token(condition.NumberToken, "3", 12),
+				token(condition.DotToken, ".", 13),
+				token(condition.IdentifierToken, "-x", 15),
+				token(condition.EOFToken, "", 17),
+			},
+		},
+		{
+			input: "true false null name",
+			expected: []condition.LexerToken{
+				token(condition.TrueToken, "true", 0),
+				token(condition.FalseToken, "false", 5),
+				token(condition.NullToken, "null", 11),
+				token(condition.IdentifierToken, "name", 16),
+				token(condition.EOFToken, "", 20),
+			},
+		},
+		{
+			input: "!(<<=>>=)[]&&||",
+			expected: []condition.LexerToken{
+				token(condition.NotToken, "!", 0),
+				token(condition.LeftParenToken, "(", 1),
+				token(condition.LessToken, "<", 2),
+				token(condition.LessEqualToken, "<=", 3),
+				token(condition.GreaterToken, ">", 5),
+				token(condition.GreaterEqualToken, ">=", 6),
+				token(condition.RightParenToken, ")", 8),
+				token(condition.LeftBracketToken, "[", 9),
+				token(condition.RightBracketToken, "]", 10),
+				token(condition.AndToken, "&&", 11),
+				token(condition.OrToken, "||", 13),
+				token(condition.EOFToken, "", 15),
+			},
+		},
+		{
+			input: " \t\n",
+			expected: []condition.LexerToken{
+				token(condition.EOFToken, "", 3),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := condition.NewLexer(tt.input).Tokenize()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestLexer_Tokenize_Errors(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+		message  string
+	}{
+		{"$statusCode = 200", 12, "unexpected character '='"},
+		{"1 & 2", 2, "unexpected character '&'"},
+		{"1 | 2", 2, "unexpected character '|'"},
+		{"@", 0, "unexpected character '@'"},
+		{"'open", 0, "unterminated string literal"},
+		{`"escaped\"`, 0, "unterminated string literal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := condition.NewLexer(tt.input).Tokenize()
+			var syntaxErr *condition.SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+			assert.Equal(t, tt.position, syntaxErr.Position)
+			assert.Equal(t, tt.message, syntaxErr.Message)
+		})
+	}
+}
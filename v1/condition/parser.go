@@ -0,0 +1,330 @@
+package condition
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/bragdonD/arazzo-go/v1/expression"
+)
+
+// Parser represents a parser for Arazzo simple conditions.
+//
+// The parser implements the following grammar, from the lowest to
+// the highest precedence:
+//
+//	condition  = or
+//	or         = and *( "||" and )
+//	and        = equality *( "&&" equality )
+//	equality   = comparison *( ( "==" / "!=" ) comparison )
+//	comparison = unary *( ( "<" / "<=" / ">" / ">=" ) unary )
+//	unary      = "!" unary / postfix
+//	postfix    = primary *( "[" condition "]" / "." identifier )
+//	primary    = literal / runtime-expression / "(" condition ")"
+//	literal    = number / string / "true" / "false" / "null"
+type Parser struct {
+	// List of tokens to parse.
+	tokens []LexerToken
+	// Current position in the token list.
+	current int
+}
+
+// NewParser creates a new Parser instance. The token list must end
+// with an [EOFToken].
+func NewParser(tokens []LexerToken) *Parser {
+	return &Parser{
+		tokens:  tokens,
+		current: 0,
+	}
+}
+
+// Parse parses the tokens and returns a condition.
+func (p *Parser) Parse() (Expr, error) {
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isAtEnd() {
+		return nil, p.errorAtPeek("unexpected token")
+	}
+	return expr, nil
+}
+
+// or parses a logical "||".
+func (p *Parser) or() (Expr, error) {
+	expr, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.match(OrToken) {
+		operator := p.previous()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		expr = &LogicalNode{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+	return expr, nil
+}
+
+// and parses a logical "&&".
+func (p *Parser) and() (Expr, error) {
+	expr, err := p.equality()
+	if err != nil {
+		return nil, err
+	}
+	for p.match(AndToken) {
+		operator := p.previous()
+		right, err := p.equality()
+		if err != nil {
+			return nil, err
+		}
+		expr = &LogicalNode{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+	return expr, nil
+}
+
+// equality parses the "==" and "!=" comparisons.
+func (p *Parser) equality() (Expr, error) {
+	expr, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.match(EqualToken, NotEqualToken) {
+		operator := p.previous()
+		right, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		expr = &BinaryNode{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+	return expr, nil
+}
+
+// comparison parses the "<", "<=", ">" and ">=" comparisons.
+func (p *Parser) comparison() (Expr, error) {
+	expr, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.match(LessToken, LessEqualToken, GreaterToken, GreaterEqualToken) {
+		operator := p.previous()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		expr = &BinaryNode{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+	return expr, nil
+}
+
+// unary parses the "!" negation.
+func (p *Parser) unary() (Expr, error) {
+	if p.match(NotToken) {
+		operator := p.previous()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryNode{Operator: operator, Operand: operand}, nil
+	}
+	return p.postfix()
+}
+
+// postfix parses the "[]" index and "." property accesses.
+func (p *Parser) postfix() (Expr, error) {
+	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.match(LeftBracketToken) {
+			position := p.previous().Position
+			index, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			if !p.match(RightBracketToken) {
+				return nil, p.errorAtPeek("expected ']'")
+			}
+			expr = &IndexNode{
+				Target:   expr,
+				Index:    index,
+				Position: position,
+			}
+			continue
+		}
+		if p.match(DotToken) {
+			if !p.match(
+				IdentifierToken,
+				TrueToken,
+				FalseToken,
+				NullToken,
+			) {
+				return nil, p.errorAtPeek(
+					"expected a property name after '.'",
+				)
+			}
+			name := p.previous()
+			expr = &PropertyNode{
+				Target:   expr,
+				Name:     name.Value,
+				Position: name.Position,
+			}
+			continue
+		}
+		return expr, nil
+	}
+}
+
+// primary parses literals, runtime expressions and parenthesized
+// conditions.
+func (p *Parser) primary() (Expr, error) {
+	switch {
+	case p.match(NumberToken):
+		token := p.previous()
+		value, err := strconv.ParseFloat(token.Value, 64)
+		if err != nil {
+			return nil, &SyntaxError{
+				Message: fmt.Sprintf(
+					"invalid number %q",
+					token.Value,
+				),
+				Position: token.Position,
+			}
+		}
+		return &LiteralNode{
+			Value:    value,
+			Position: token.Position,
+		}, nil
+	case p.match(StringToken):
+		token := p.previous()
+		return &LiteralNode{
+			Value:    token.Value,
+			Position: token.Position,
+		}, nil
+	case p.match(TrueToken):
+		return &LiteralNode{
+			Value:    true,
+			Position: p.previous().Position,
+		}, nil
+	case p.match(FalseToken):
+		return &LiteralNode{
+			Value:    false,
+			Position: p.previous().Position,
+		}, nil
+	case p.match(NullToken):
+		return &LiteralNode{
+			Value:    nil,
+			Position: p.previous().Position,
+		}, nil
+	case p.match(RuntimeExpressionToken):
+		return p.runtimeExpression()
+	case p.match(LeftParenToken):
+		position := p.previous().Position
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.match(RightParenToken) {
+			return nil, p.errorAtPeek("expected ')'")
+		}
+		return &GroupingNode{Expr: expr, Position: position}, nil
+	}
+	return nil, p.errorAtPeek(
+		"expected a literal, a runtime expression or '('",
+	)
+}
+
+// runtimeExpression parses the runtime expression of the previous
+// token using the [expression] package.
+func (p *Parser) runtimeExpression() (Expr, error) {
+	token := p.previous()
+	expr, err := expression.Parse(token.Value)
+	if err != nil {
+		position := token.Position
+		var unknownToken *expression.UnknownTokenError
+		if errors.As(err, &unknownToken) {
+			position += unknownToken.Position
+		}
+		return nil, &SyntaxError{
+			Message: fmt.Sprintf(
+				"invalid runtime expression %q: %v",
+				token.Value,
+				err,
+			),
+			Position: position,
+		}
+	}
+	return &RuntimeExpressionNode{
+		Value:      token.Value,
+		Expression: expr,
+		Position:   token.Position,
+	}, nil
+}
+
+// errorAtPeek returns a syntax error positioned at the current token.
+func (p *Parser) errorAtPeek(message string) error {
+	token := p.peek()
+	if token.Type == EOFToken {
+		message += ", got end of condition"
+	} else {
+		message += fmt.Sprintf(", got %q", token.Value)
+	}
+	return &SyntaxError{Message: message, Position: token.Position}
+}
+
+// match checks if the current token matches any of the given types.
+func (p *Parser) match(types ...LexerTokenType) bool {
+	for _, t := range types {
+		if p.check(t) {
+			p.advance()
+			return true
+		}
+	}
+	return false
+}
+
+// check checks if the current token is of the given type.
+func (p *Parser) check(t LexerTokenType) bool {
+	return p.peek().Type == t
+}
+
+// advance advances to the next token.
+func (p *Parser) advance() LexerToken {
+	if !p.isAtEnd() {
+		p.current++
+	}
+	return p.previous()
+}
+
+// isAtEnd checks if the parser has reached the end of the tokens.
+func (p *Parser) isAtEnd() bool {
+	return p.peek().Type == EOFToken
+}
+
+// peek returns the current token.
+func (p *Parser) peek() LexerToken {
+	return p.tokens[p.current]
+}
+
+// previous returns the previous token.
+func (p *Parser) previous() LexerToken {
+	return p.tokens[p.current-1]
+}
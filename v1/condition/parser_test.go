@@ -0,0 +1,183 @@
+package condition_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bragdonD/arazzo-go/v1/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// printer is a visitor writing a condition as an s-expression, which
+// makes the structure of its AST explicit.
+type printer struct{}
+
+func (p printer) print(expr condition.Expr) string {
+	return expr.Accept(p).(string)
+}
+
+func (p printer) VisitLiteralNode(n *condition.LiteralNode) any {
+	if s, ok := n.Value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	if n.Value == nil {
+		return "null"
+	}
+	return fmt.Sprint(n.Value)
+}
+
+func (p printer) VisitRuntimeExpressionNode(
+	n *condition.RuntimeExpressionNode,
+) any {
+	return n.Value
+}
+
+func (p printer) VisitGroupingNode(n *condition.GroupingNode) any {
+	return fmt.Sprintf("(group %s)", p.print(n.Expr))
+}
+
+func (p printer) VisitUnaryNode(n *condition.UnaryNode) any {
+	return fmt.Sprintf(
+		"(%s %s)",
+		n.Operator.Value,
+		p.print(n.Operand),
+	)
+}
+
+func (p printer) VisitBinaryNode(n *condition.BinaryNode) any {
+	return fmt.Sprintf(
+		"(%s %s %s)",
+		n.Operator.Value,
+		p.print(n.Left),
+		p.print(n.Right),
+	)
+}
+
+func (p printer) VisitLogicalNode(n *condition.LogicalNode) any {
+	return fmt.Sprintf(
+		"(%s %s %s)",
+		n.Operator.Value,
+		p.print(n.Left),
+		p.print(n.Right),
+	)
+}
+
+func (p printer) VisitIndexNode(n *condition.IndexNode) any {
+	return fmt.Sprintf(
+		"([] %s %s)",
+		p.print(n.Target),
+		p.print(n.Index),
+	)
+}
+
+func (p printer) VisitPropertyNode(n *condition.PropertyNode) any {
+	return fmt.Sprintf("(. %s %s)", p.print(n.Target), n.Name)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"$statusCode == 200", "(== $statusCode 200)"},
+		{"true || false && false", "(|| true (&& false false))"},
+		{"true && false || false", "(|| (&& true false) false)"},
+		{
+			"(true || false) && false",
+			"(&& (group (|| true false)) false)",
+		},
+		{"1 || 2 || 3", "(|| (|| 1 2) 3)"},
+		{"1 == 2 && 3 != 4", "(&& (== 1 2) (!= 3 4))"},
+		{"1 < 2 == true", "(== (< 1 2) true)"},
+		{"1 <= 2 >= 3 > 4", "(> (>= (<= 1 2) 3) 4)"},
+		{"!true == false", "(== (! true) false)"},
+		{
+			"!!($statusCode < 300)",
+			"(! (! (group (< $statusCode 300))))",
+		},
+		{"'a' == \"b\"", `(== "a" "b")`},
+		{"null != -1.5", "(!= null -1.5)"},
+		{
+			"$response.body#/pets[0].name",
+			"(. ([] $response.body#/pets 0) name)",
+		},
+		{
+			"$inputs.map['a b'][1 == 1]",
+			`([] ([] $inputs.map "a b") (== 1 1))`,
+		},
+		{
+			"$inputs.list[$inputs.index].null",
+			"(. ([] $inputs.list $inputs.index) null)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			c, err := condition.Parse(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.input, c.String())
+			assert.Equal(t, tt.expected, printer{}.print(c.Expr()))
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+		message  string
+	}{
+		{
+			"",
+			0,
+			"expected a literal, a runtime expression or '(', got end of condition",
+		},
+		{
+			"name == 1",
+			0,
+			`expected a literal, a runtime expression or '(', got "name"`,
+		},
+		{
+			"1 == )",
+			5,
+			`expected a literal, a runtime expression or '(', got ")"`,
+		},
+		{
+			"$statusCode ==",
+			14,
+			"expected a literal, a runtime expression or '(', got end of condition",
+		},
+		{"(1 == 1", 7, "expected ')', got end of condition"},
+		{"(1 == 1))", 8, `unexpected token, got ")"`},
+		{"1 2", 2, `unexpected token, got "2"`},
+		{"$inputs.list[0", 14, "expected ']', got end of condition"},
+		{
+			"($inputs.list).[0]",
+			15,
+			`expected a property name after '.', got "["`,
+		},
+		{
+			"!",
+			1,
+			"expected a literal, a runtime expression or '(', got end of condition",
+		},
+		{
+			"1 && || 2",
+			5,
+			`expected a literal, a runtime expression or '(', got "||"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := condition.Parse(tt.input)
+			var syntaxErr *condition.SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+			assert.Equal(t, tt.position, syntaxErr.Position)
+			assert.Equal(t, tt.message, syntaxErr.Message)
+			assert.False(t, condition.Validate(tt.input))
+		})
+	}
+}
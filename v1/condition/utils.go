@@ -0,0 +1,79 @@
+package condition
+
+import "fmt"
+
+// Condition is a parsed Arazzo simple condition, ready to be
+// evaluated.
+type Condition struct {
+	input string
+	expr  Expr
+}
+
+// Parse parses the input string and returns a condition. If the input
+// string is not a valid condition, it returns a [SyntaxError].
+func Parse(input string) (*Condition, error) {
+	lexer := NewLexer(input)
+	tokens, err := lexer.Tokenize()
+	if err != nil {
+		return nil, err
+	}
+
+	parser := NewParser(tokens)
+	expr, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Condition{
+		input: input,
+		expr:  expr,
+	}, nil
+}
+
+// Validate checks if the input string is a valid condition. It does
+// not check if the runtime expressions it contains can be resolved.
+func Validate(input string) bool {
+	_, err := Parse(input)
+	return err == nil
+}
+
+// Evaluate parses the input string and evaluates it with resolver.
+func Evaluate(input string, resolver Resolver) (bool, error) {
+	condition, err := Parse(input)
+	if err != nil {
+		return false, err
+	}
+	return condition.Evaluate(resolver)
+}
+
+// String returns the condition as it was written.
+func (c *Condition) String() string {
+	return c.input
+}
+
+// Expr returns the root node of the condition's AST.
+func (c *Condition) Expr() Expr {
+	return c.expr
+}
+
+// Evaluate evaluates the condition, resolving its runtime expressions
+// with resolver. The condition must result in a boolean. If it cannot
+// be evaluated, an [EvaluationError] is returned.
+func (c *Condition) Evaluate(resolver Resolver) (bool, error) {
+	visitor := &evaluator{resolver: resolver}
+	value := c.expr.Accept(visitor)
+	if visitor.err != nil {
+		return false, visitor.err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, &EvaluationError{
+			Err: fmt.Errorf(
+				"condition must result in a boolean, got %T",
+				value,
+			),
+			Position: c.expr.Pos(),
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,163 @@
+package condition_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bragdonD/arazzo-go/v1/condition"
+	"github.com/bragdonD/arazzo-go/v1/expression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errUnresolved is returned by valuesResolver for the runtime
+// expressions it has no value for.
+var errUnresolved = errors.New("unresolved runtime expression")
+
+// valuesResolver resolves the runtime expressions it has a value for,
+// keyed by the expression as written.
+type valuesResolver map[string]any
+
+func (r valuesResolver) Resolve(expr expression.Expr) (any, error) {
+	for input, value := range r {
+		parsed, err := expression.Parse(input)
+		if err == nil && reflect.DeepEqual(parsed, expr) {
+			return value, nil
+		}
+	}
+	return nil, errUnresolved
+}
+
+// testValues are the values the runtime expressions of the condition
+// tests resolve to.
+var testValues = valuesResolver{
+	"$statusCode":                   200,
+	"$response.header.X-Rate-Limit": "100",
+	"$response.body#/status":        "Available",
+	"$response.body#/count":         float64(3),
+	"$response.body#/pets": []any{
+		map[string]any{"name": "Rex", "tags": []any{"dog"}},
+		map[string]any{"name": "Tom"},
+	},
+	"$inputs.minimum": 2,
+	"$inputs.enabled": true,
+	"$inputs.name":    "Rex",
+	"$inputs.count":   json.Number("3"),
+	"$inputs.limit":   float64(2.5),
+	"$inputs.flag":    "TRUE",
+	"$inputs.nothing": nil,
+	"$inputs.list":    []any{"a", "b"},
+	"$inputs.object":  map[string]any{"key": "value"},
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"$statusCode == 200", true},
+		{"$statusCode != 200", false},
+		{"$statusCode >= 200 && $statusCode < 300", true},
+		{"$statusCode == 404 || $statusCode == 200", true},
+		{"$response.body#/status != 'sold'", true},
+		{"$response.body#/status == 'available'", true},
+		{"$response.body#/status == \"AVAILABLE\"", true},
+		{"$response.header.X-Rate-Limit > 10", true},
+		{"$response.body#/count > $inputs.minimum", true},
+		{"$response.body#/count <= 2.5", false},
+		{"!($statusCode == 200)", false},
+		{"!$inputs.enabled || $statusCode == 200", true},
+		{"$response.body#/pets[0].name == 'rex'", true},
+		{"$response.body#/pets[1]['name'] == 'Tom'", true},
+		{"$response.body#/pets[0].tags[0] == 'dog'", true},
+		{"'abc' < 'ABD'", true},
+		{"true && (false || 1e2 == 100)", true},
+		{"$statusCode == -1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := condition.Evaluate(tt.input, testValues)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+		message  string
+	}{
+		{
+			"$statusCode == ",
+			15,
+			"expected a literal, a runtime expression or '(', got end of condition",
+		},
+		{"$statusCode = 200", 12, "unexpected character '='"},
+		{
+			"($statusCode == 200",
+			19,
+			"expected ')', got end of condition",
+		},
+		{"$statusCode == 'ok", 15, "unterminated string literal"},
+		{"$statusCode 200", 12, `unexpected token, got "200"`},
+		{
+			"$response.body#/pets[0",
+			22,
+			"expected ']', got end of condition",
+		},
+		{
+			"($statusCode).",
+			14,
+			"expected a property name after '.', got end of condition",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := condition.Parse(tt.input)
+			var syntaxErr *condition.SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+			assert.Equal(t, tt.position, syntaxErr.Position)
+			assert.Equal(t, tt.message, syntaxErr.Message)
+		})
+	}
+
+	_, err := condition.Parse("$statusCode == 200 && $foo.bar")
+	var syntaxErr *condition.SyntaxError
+	require.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, 22, syntaxErr.Position)
+}
+
+func TestEvaluate_EvaluationError(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+	}{
+		{"$statusCode", 0},
+		{"$statusCode == 200 && 'yes'", 22},
+		{"$response.body#/pets[5] == null", 21},
+		{"$response.body#/pets < 3", 21},
+		{"!$response.body#/count", 1},
+		{"$response.body#/pets[0].age == 1", 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := condition.Evaluate(tt.input, testValues)
+			var evalErr *condition.EvaluationError
+			require.True(t, errors.As(err, &evalErr), "got %v", err)
+			assert.Equal(t, tt.position, evalErr.Position)
+		})
+	}
+
+	_, err := condition.Evaluate(
+		"$response.body#/missing == null",
+		testValues,
+	)
+	assert.ErrorIs(t, err, errUnresolved)
+}
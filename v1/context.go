@@ -0,0 +1,139 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Request holds the data of an HTTP request sent by a step that can
+// be referenced by the $request and $url runtime expressions.
+type Request struct {
+	// URL is the URL the request was sent to.
+	URL *url.URL
+	// Method is the HTTP method of the request.
+	Method string
+	// Header holds the headers of the request.
+	Header http.Header
+	// PathParams holds the values of the path parameters the
+	// operation path was templated with.
+	PathParams map[string]string
+	// Body is the decoded body of the request.
+	Body any
+}
+
+// Response holds the data of an HTTP response received by a step that
+// can be referenced by the $response and $statusCode runtime
+// expressions.
+type Response struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Header holds the headers of the response.
+	Header http.Header
+	// Body is the decoded body of the response.
+	Body any
+}
+
+// WorkflowContext holds the inputs and outputs of a workflow that can
+// be referenced by the $workflows runtime expression.
+type WorkflowContext struct {
+	Inputs  map[string]any
+	Outputs map[string]any
+}
+
+// EvalContext holds every piece of data a runtime expression can be
+// evaluated against. Fields which are not available at the time of
+// the evaluation are left empty, in which case expressions
+// referencing them fail with an [UnresolvedExpressionError].
+type EvalContext struct {
+	// Spec is the Arazzo document used to resolve the
+	// $sourceDescriptions and $components expressions.
+	Spec *Spec
+	// Request is the request of the current step.
+	Request *Request
+	// Response is the response of the current step.
+	Response *Response
+	// Inputs holds the inputs of the current workflow.
+	Inputs map[string]any
+	// CalledOutputs holds the outputs of the workflow called by the
+	// current step, referenced by the $outputs expressions. Unlike
+	// the outputs of a [WorkflowContext], they are only available
+	// while the calling step completes.
+	CalledOutputs map[string]any
+	// Steps holds the outputs of the executed steps of the current
+	// workflow by stepId.
+	Steps map[string]map[string]any
+	// Workflows holds the inputs and outputs of the executed
+	// workflows by workflowId.
+	Workflows map[string]*WorkflowContext
+}
+
+// UnresolvedExpressionError is returned when a runtime expression
+// references data which is missing from the [EvalContext].
+type UnresolvedExpressionError struct {
+	// Expression is the runtime expression being evaluated.
+	Expression string
+	// Reason describes the missing data.
+	Reason string
+}
+
+// Error returns a formatted error message indicating the expression
+// and the reason it could not be resolved.
+func (e *UnresolvedExpressionError) Error() string {
+	return fmt.Sprintf(
+		"arazzo-go: unable to resolve runtime expression %q: %s",
+		e.Expression,
+		e.Reason,
+	)
+}
+
+// DecodeBody decodes an HTTP message body according to its
+// Content-Type. JSON bodies are decoded to their Go representation,
+// XML bodies to an [XMLDocument] and any other body is returned as a
+// string. An empty body decodes to nil.
+func DecodeBody(contentType string, data []byte) (any, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	switch {
+	case IsJSONContentType(contentType):
+		var body any
+		if err := json.Unmarshal(data, &body); err != nil {
+			return nil, fmt.Errorf(
+				"failed to decode json body: %w",
+				err,
+			)
+		}
+		return body, nil
+	case IsXMLContentType(contentType):
+		return ParseXML(data)
+	}
+	return string(data), nil
+}
+
+// IsJSONContentType reports whether contentType designates a JSON
+// media type, such as application/json or application/problem+json.
+func IsJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" ||
+		strings.HasSuffix(mediaType, "+json")
+}
+
+// IsXMLContentType reports whether contentType designates an XML
+// media type, such as application/xml, text/xml or
+// application/soap+xml.
+func IsXMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/xml" ||
+		mediaType == "text/xml" ||
+		strings.HasSuffix(mediaType, "+xml")
+}
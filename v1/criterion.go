@@ -1,9 +1,190 @@
 package v1
 
-import "github.com/bragdonD/arazzo-go/v1/models"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/bragdonD/arazzo-go/v1/condition"
+	"github.com/bragdonD/arazzo-go/v1/expression"
+	"github.com/bragdonD/arazzo-go/v1/models"
+)
 
 // Criterion is a struct that represents an Arazzo specification 1.0.X
 // criterion object.
 type Criterion struct {
 	model *models.Criterion
+	// condition is the parsed condition of a simple criterion.
+	condition *condition.Condition
+	// context is the parsed runtime expression the condition of a
+	// typed criterion is applied to.
+	context expression.Expr
+	// regex is the compiled pattern of a regex criterion.
+	regex *regexp.Regexp
+	// jsonPath is the compiled query of a JSONPath criterion.
+	jsonPath *JSONPath
+	// xpath is the compiled expression of an XPath criterion.
+	xpath *XPath
+}
+
+// NewCriterion creates a new Criterion from its model. The condition
+// of a criterion and its context are parsed upfront so that syntax
+// errors are reported when the document is loaded.
+func NewCriterion(model *models.Criterion) (*Criterion, error) {
+	criterion := &Criterion{
+		model: model,
+	}
+
+	var err error
+	switch criterion.GetType() {
+	case models.CriterionTypeSimple:
+		criterion.condition, err = condition.Parse(model.Condition)
+	case models.CriterionTypeRegex:
+		criterion.context, err = parseCriterionContext(model.Context)
+		if err == nil {
+			criterion.regex, err = regexp.Compile(model.Condition)
+		}
+	case models.CriterionTypeJsonPath:
+		criterion.context, err = parseCriterionContext(model.Context)
+		if err == nil {
+			criterion.jsonPath, err = NewJSONPath(
+				model.Condition,
+				criterion.GetVersion(),
+			)
+		}
+	case models.CriterionTypeXPath:
+		criterion.context, err = parseCriterionContext(model.Context)
+		if err == nil {
+			criterion.xpath, err = NewXPath(
+				model.Condition,
+				criterion.GetVersion(),
+			)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"invalid criterion %q: %w",
+			model.Condition,
+			err,
+		)
+	}
+
+	return criterion, nil
+}
+
+// parseCriterionContext parses the context of a typed criterion,
+// which is required and must be a runtime expression.
+func parseCriterionContext(context *string) (expression.Expr, error) {
+	if context == nil || *context == "" {
+		return nil, errors.New("a context is required")
+	}
+	str := *context
+	if str[0] == '{' {
+		var err error
+		str, err = expression.Extract(str)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid context %q: %w",
+				*context,
+				err,
+			)
+		}
+	}
+	expr, err := expression.Parse(str)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"invalid context %q: %w",
+			*context,
+			err,
+		)
+	}
+	return expr, nil
+}
+
+func (c *Criterion) GetModel() *models.Criterion {
+	return c.model
+}
+
+// GetType returns the type of the criterion. A criterion with no type
+// is a simple criterion.
+func (c *Criterion) GetType() models.CriterionType {
+	criterionType := c.model.Type
+	switch {
+	case criterionType == nil:
+		return models.CriterionTypeSimple
+	case criterionType.CriterionType != nil:
+		return *criterionType.CriterionType
+	case criterionType.CriterionExpressionType != nil:
+		return models.CriterionType(
+			criterionType.CriterionExpressionType.Type,
+		)
+	}
+	return models.CriterionTypeSimple
+}
+
+// GetVersion returns the version of the expression type of the
+// criterion, or an empty string if the default version applies.
+func (c *Criterion) GetVersion() string {
+	if c.model.Type == nil ||
+		c.model.Type.CriterionExpressionType == nil {
+		return ""
+	}
+	return c.model.Type.CriterionExpressionType.Version
+}
+
+// Evaluate reports whether the criterion is satisfied in ctx.
+func (c *Criterion) Evaluate(ctx *EvalContext) (bool, error) {
+	switch c.GetType() {
+	case models.CriterionTypeSimple:
+		return c.condition.Evaluate(ctx)
+	case models.CriterionTypeRegex:
+		value, err := c.resolveContext(ctx)
+		if err != nil {
+			return false, err
+		}
+		str, err := FormatValue(value)
+		if err != nil {
+			return false, fmt.Errorf(
+				"failed to stringify criterion context: %w",
+				err,
+			)
+		}
+		return c.regex.MatchString(str), nil
+	case models.CriterionTypeJsonPath:
+		// A JSONPath criterion is satisfied when the query selects at
+		// least one node.
+		value, err := c.resolveContext(ctx)
+		if err != nil {
+			return false, err
+		}
+		nodes, err := c.jsonPath.Query(value)
+		if err != nil {
+			return false, err
+		}
+		return len(nodes) > 0, nil
+	case models.CriterionTypeXPath:
+		// An XPath criterion is satisfied when its result converts to
+		// true, e.g. a non-empty node set.
+		value, err := c.resolveContext(ctx)
+		if err != nil {
+			return false, err
+		}
+		return c.xpath.Test(value)
+	}
+	return false, fmt.Errorf(
+		"criterion type %q is not supported",
+		c.GetType(),
+	)
+}
+
+// resolveContext resolves the context of a typed criterion in ctx.
+func (c *Criterion) resolveContext(ctx *EvalContext) (any, error) {
+	value, err := ResolveRuntimeExpression(c.context, ctx)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to resolve criterion context: %w",
+			err,
+		)
+	}
+	return value, nil
 }
@@ -0,0 +1,343 @@
+package v1
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/bragdonD/arazzo-go/internal/testutil"
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCriterion_Evaluate(t *testing.T) {
+	tests := []struct {
+		model    models.Criterion
+		expected bool
+	}{
+		{models.Criterion{Condition: "$statusCode == 200"}, true},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$statusCode"),
+				Condition: "^2[0-9]{2}$",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionType: models.CriterionTypeRegex.ToPtr(),
+				},
+			},
+			true,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: `"status":"sold"`,
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionType: models.CriterionTypeRegex.ToPtr(),
+				},
+			},
+			false,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("{$response.body#/status}"),
+				Condition: "^avail",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionExpressionType: &models.CriterionExpressionType{
+						Type: "regex",
+					},
+				},
+			},
+			true,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "$[?count(@.pets) > 0]",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionType: models.CriterionTypeJsonPath.ToPtr(),
+				},
+			},
+			true,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "$.store.pets[?@.age > 5]",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionType: models.CriterionTypeJsonPath.ToPtr(),
+				},
+			},
+			false,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body#/store"),
+				Condition: "$.pets[?(@.age > 2)].name",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionExpressionType: &models.CriterionExpressionType{
+						Type:    models.CriterionExpressionTypeTypeJsonPath,
+						Version: models.JSONPathVersionGoessner,
+					},
+				},
+			},
+			true,
+		},
+		{
+			models.Criterion{
+				Condition: "$response.body#/status == 'sold'",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionType: models.CriterionTypeSimple.ToPtr(),
+				},
+			},
+			false,
+		},
+	}
+
+	ctx := &EvalContext{
+		Response: &Response{
+			StatusCode: http.StatusOK,
+			Body: map[string]any{
+				"status": "available",
+				"store": map[string]any{
+					"pets": []any{
+						map[string]any{
+							"name": "rex",
+							"age":  float64(3),
+						},
+						map[string]any{
+							"name": "tom",
+							"age":  float64(1),
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		criterion, err := NewCriterion(&tt.model)
+		require.NoError(t, err)
+
+		result, err := criterion.Evaluate(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, result)
+	}
+}
+
+func TestNewCriterion_Invalid(t *testing.T) {
+	regex := &models.CriterionTypeOrCriterionExpressionType{
+		CriterionType: models.CriterionTypeRegex.ToPtr(),
+	}
+	tests := []struct {
+		model    models.Criterion
+		expected string
+	}{
+		{
+			models.Criterion{Condition: "$statusCode = 200"},
+			`invalid criterion "$statusCode = 200": arazzo-go: condition: syntax error at pos: 12: unexpected character '='`,
+		},
+		{
+			models.Criterion{Condition: "^2", Type: regex},
+			`invalid criterion "^2": a context is required`,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("statusCode"),
+				Condition: "^2",
+				Type:      regex,
+			},
+			`invalid criterion "^2": invalid context "statusCode": ` +
+				`failed to parse tokens: token at 0 should be an ` +
+				`expression token, instead it is a '' token`,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$statusCode"),
+				Condition: "^2(",
+				Type:      regex,
+			},
+			"invalid criterion \"^2(\": error parsing regexp: " +
+				"missing closing ): `^2(`",
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "$.pets",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionExpressionType: &models.CriterionExpressionType{
+						Type:    models.CriterionExpressionTypeTypeJsonPath,
+						Version: "draft-01",
+					},
+				},
+			},
+			`invalid criterion "$.pets": unsupported JSONPath version "draft-01"`,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "$.pets[",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionExpressionType: &models.CriterionExpressionType{
+						Type:    models.CriterionExpressionTypeTypeJsonPath,
+						Version: models.JSONPathVersionGoessner,
+					},
+				},
+			},
+			`invalid criterion "$.pets[": invalid JSONPath "$.pets[": ` +
+				`unmatched [ at position 7, following ".pets["`,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "//pet",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionExpressionType: &models.CriterionExpressionType{
+						Type:    models.CriterionExpressionTypeTypeXPath,
+						Version: "xpath-31",
+					},
+				},
+			},
+			`invalid criterion "//pet": unsupported XPath version "xpath-31"`,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "//pet[age eq 3]",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionExpressionType: &models.CriterionExpressionType{
+						Type:    models.CriterionExpressionTypeTypeXPath,
+						Version: models.XPathVersion20,
+					},
+				},
+			},
+			`invalid criterion "//pet[age eq 3]": arazzo-go: XPath ` +
+				`"//pet[age eq 3]" uses the "eq" operator, only XPath 1.0 ` +
+				`expressions are supported`,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "//pet[",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionType: models.CriterionTypeXPath.ToPtr(),
+				},
+			},
+			`invalid criterion "//pet[": invalid XPath "//pet[": ` +
+				`expression must evaluate to a node-set`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model.Condition, func(t *testing.T) {
+			_, err := NewCriterion(&tt.model)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestCriterion_Evaluate_XPath(t *testing.T) {
+	data, err := os.ReadFile("test_specs/pets.xml")
+	require.NoError(t, err)
+	body, err := DecodeBody(
+		"application/soap+xml; charset=utf-8",
+		data,
+	)
+	require.NoError(t, err)
+	require.IsType(t, &XMLDocument{}, body)
+
+	xpath := func(version string) *models.CriterionTypeOrCriterionExpressionType {
+		return &models.CriterionTypeOrCriterionExpressionType{
+			CriterionExpressionType: &models.CriterionExpressionType{
+				Type:    models.CriterionExpressionTypeTypeXPath,
+				Version: version,
+			},
+		}
+	}
+	tests := []struct {
+		model    models.Criterion
+		expected bool
+	}{
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "//pet[@status = 'available']",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionType: models.CriterionTypeXPath.ToPtr(),
+				},
+			},
+			true,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "//pet[@status = 'pending']",
+				Type:      xpath(models.XPathVersion10),
+			},
+			false,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "count(//pet) = 2 and //pet[age > 2]/name = 'rex'",
+				Type:      xpath(models.XPathVersion30),
+			},
+			true,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "sum(//pet/age) div 4",
+				Type:      xpath(models.XPathVersion20),
+			},
+			true,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "string(//pet[3]/name)",
+				Type:      xpath(models.XPathVersion10),
+			},
+			false,
+		},
+		{
+			models.Criterion{
+				Context:   testutil.Ptr("$response.body"),
+				Condition: "<name>tom</name>",
+				Type: &models.CriterionTypeOrCriterionExpressionType{
+					CriterionType: models.CriterionTypeRegex.ToPtr(),
+				},
+			},
+			true,
+		},
+	}
+
+	ctx := &EvalContext{
+		Response: &Response{StatusCode: http.StatusOK, Body: body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model.Condition, func(t *testing.T) {
+			criterion, err := NewCriterion(&tt.model)
+			require.NoError(t, err)
+
+			result, err := criterion.Evaluate(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCriterion_Evaluate_UnresolvedContext(t *testing.T) {
+	criterion, err := NewCriterion(&models.Criterion{
+		Context:   testutil.Ptr("$response.body#/missing"),
+		Condition: ".*",
+		Type: &models.CriterionTypeOrCriterionExpressionType{
+			CriterionType: models.CriterionTypeRegex.ToPtr(),
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = criterion.Evaluate(&EvalContext{
+		Response: &Response{Body: map[string]any{}},
+	})
+	var unresolved *UnresolvedExpressionError
+	assert.ErrorAs(t, err, &unresolved)
+}
@@ -0,0 +1,339 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"slices"
+)
+
+const (
+	// mediaTypeJSON is the media type of JSON message bodies.
+	mediaTypeJSON = "application/json"
+	// mediaTypeForm is the media type of URL encoded form message
+	// bodies.
+	mediaTypeForm = "application/x-www-form-urlencoded"
+	// mediaTypeMultipartForm is the media type of multipart form
+	// message bodies.
+	mediaTypeMultipartForm = "multipart/form-data"
+	// mediaTypeText is the media type of plain text message bodies.
+	mediaTypeText = "text/plain"
+	// mediaTypeOctetStream is the media type of the files of
+	// multipart forms which do not declare theirs.
+	mediaTypeOctetStream = "application/octet-stream"
+)
+
+// EncodeBody serializes payload as an HTTP message body of the given
+// Content-Type. It returns the body and the Content-Type it must be
+// sent with, which carries the boundary of multipart bodies.
+//
+// String payloads are sent as is whatever the Content-Type. Objects
+// are encoded as URL encoded or multipart forms, or as XML with
+// [EncodeXML], XML documents are serialized and any other payload is
+// encoded as JSON unless the Content-Type is text/plain, in which case
+// scalars are written as text. A nil payload encodes to a nil body.
+//
+// The multipart form fields sent as files are byte slices and objects
+// holding a filename and a content string, along with an optional
+// contentType and a contentEncoding of base64 for binary content,
+// e.g. in YAML:
+//
+//	file:
+//	  filename: pet.png
+//	  contentType: image/png
+//	  contentEncoding: base64
+//	  content: iVBORw0KGgo=
+func EncodeBody(
+	contentType string,
+	payload any,
+) ([]byte, string, error) {
+	if payload == nil {
+		return nil, contentType, nil
+	}
+	if str, ok := payload.(string); ok {
+		return []byte(str), contentType, nil
+	}
+	if doc, ok := payload.(*XMLDocument); ok {
+		return []byte(doc.String()), contentType, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == mediaTypeForm:
+		data, err := encodeForm(payload)
+		return data, contentType, err
+	case mediaType == mediaTypeMultipartForm:
+		return encodeMultipartForm(payload)
+	case IsXMLContentType(contentType):
+		data, err := EncodeXML(payload)
+		return data, contentType, err
+	case mediaType == mediaTypeText:
+		str, err := FormatValue(payload)
+		return []byte(str), contentType, err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf(
+			"failed to encode json body: %w",
+			err,
+		)
+	}
+	return data, contentType, nil
+}
+
+// decodePayload decodes a string payload of the given Content-Type so
+// that payload replacements can be applied to it. JSON payloads are
+// decoded to their Go representation, XML payloads to an
+// [XMLDocument] and URL encoded forms to an object.
+func decodePayload(contentType string, payload string) (any, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case contentType == "" || IsJSONContentType(contentType):
+		var decoded any
+		if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+			return nil, fmt.Errorf(
+				"failed to decode json payload: %w",
+				err,
+			)
+		}
+		return decoded, nil
+	case IsXMLContentType(contentType):
+		return ParseXML([]byte(payload))
+	case mediaType == mediaTypeForm:
+		values, err := url.ParseQuery(payload)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to decode form payload: %w",
+				err,
+			)
+		}
+		decoded := map[string]any{}
+		for name, value := range values {
+			if len(value) == 1 {
+				decoded[name] = value[0]
+				continue
+			}
+			items := make([]any, len(value))
+			for i, item := range value {
+				items[i] = item
+			}
+			decoded[name] = items
+		}
+		return decoded, nil
+	}
+	return nil, fmt.Errorf(
+		"payload replacements cannot be applied to a %q string payload",
+		contentType,
+	)
+}
+
+// encodeForm encodes the fields of an object as a URL encoded form.
+// Array fields are repeated once per item.
+func encodeForm(payload any) ([]byte, error) {
+	fields, ok := payload.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf(
+			"a form payload must be an object, got %T",
+			payload,
+		)
+	}
+	values := url.Values{}
+	for name, value := range fields {
+		for _, item := range formItems(value) {
+			str, err := FormatValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("form field %q: %w", name, err)
+			}
+			values.Add(name, str)
+		}
+	}
+	return []byte(values.Encode()), nil
+}
+
+// encodeMultipartForm encodes the fields of an object as a multipart
+// form. Array fields are repeated once per item, files are sent as
+// such, see [EncodeBody], byte slices being named after their field,
+// and other objects as JSON parts.
+func encodeMultipartForm(payload any) ([]byte, string, error) {
+	fields, ok := payload.(map[string]any)
+	if !ok {
+		return nil, "", fmt.Errorf(
+			"a multipart form payload must be an object, got %T",
+			payload,
+		)
+	}
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	// Fields are written in a stable order so that the same payload
+	// always produces the same parts.
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		for _, item := range formItems(fields[name]) {
+			if err := writeMultipartField(writer, name, item); err != nil {
+				return nil, "", fmt.Errorf(
+					"multipart form field %q: %w",
+					name,
+					err,
+				)
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf(
+			"failed to encode multipart form: %w",
+			err,
+		)
+	}
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
+// writeMultipartField writes value as the part of the form field
+// name.
+func writeMultipartField(
+	writer *multipart.Writer,
+	name string,
+	value any,
+) error {
+	switch v := value.(type) {
+	case []byte:
+		return writeMultipartFile(writer, name, &multipartFile{
+			filename:    name,
+			contentType: mediaTypeOctetStream,
+			content:     v,
+		})
+	case map[string]any:
+		file, err := parseMultipartFile(v)
+		if err != nil {
+			return err
+		}
+		if file != nil {
+			return writeMultipartFile(writer, name, file)
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		header := textproto.MIMEHeader{}
+		header.Set(
+			"Content-Disposition",
+			mime.FormatMediaType("form-data", map[string]string{
+				"name": name,
+			}),
+		)
+		header.Set("Content-Type", mediaTypeJSON)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		_, err = part.Write(data)
+		return err
+	}
+	str, err := FormatValue(value)
+	if err != nil {
+		return err
+	}
+	return writer.WriteField(name, str)
+}
+
+// multipartFile is a file sent as a part of a multipart form.
+type multipartFile struct {
+	filename    string
+	contentType string
+	content     []byte
+}
+
+// multipartFileFields are the fields of the objects describing a
+// file, see encodeMultipartForm.
+var multipartFileFields = []string{
+	"filename",
+	"content",
+	"contentType",
+	"contentEncoding",
+}
+
+// parseMultipartFile returns the file described by value, or nil if
+// value does not describe a file: it lacks the filename or the
+// content string, or has other fields.
+func parseMultipartFile(
+	value map[string]any,
+) (*multipartFile, error) {
+	for name := range value {
+		if !slices.Contains(multipartFileFields, name) {
+			return nil, nil
+		}
+	}
+	filename, ok := value["filename"].(string)
+	if !ok {
+		return nil, nil
+	}
+	content, ok := value["content"].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	file := &multipartFile{
+		filename:    filename,
+		contentType: mediaTypeOctetStream,
+		content:     []byte(content),
+	}
+	if contentType, ok := value["contentType"].(string); ok {
+		file.contentType = contentType
+	}
+	switch encoding := value["contentEncoding"]; encoding {
+	case nil:
+	case "base64":
+		data, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to decode file %q: %w",
+				filename,
+				err,
+			)
+		}
+		file.content = data
+	default:
+		return nil, fmt.Errorf(
+			"file %q: unsupported content encoding %v",
+			filename,
+			encoding,
+		)
+	}
+	return file, nil
+}
+
+// writeMultipartFile writes file as the part of the form field name.
+func writeMultipartFile(
+	writer *multipart.Writer,
+	name string,
+	file *multipartFile,
+) error {
+	header := textproto.MIMEHeader{}
+	header.Set(
+		"Content-Disposition",
+		mime.FormatMediaType("form-data", map[string]string{
+			"name":     name,
+			"filename": file.filename,
+		}),
+	)
+	header.Set("Content-Type", file.contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(file.content)
+	return err
+}
+
+// formItems returns the items of an array form field, or the value
+// itself for any other field.
+func formItems(value any) []any {
+	if items, ok := value.([]any); ok {
+		return items
+	}
+	return []any{value}
+}
@@ -0,0 +1,283 @@
+package v1
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"testing"
+
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestEncodeBody(t *testing.T) {
+	tests := []struct {
+		contentType string
+		payload     any
+		expected    string
+	}{
+		{"application/json", map[string]any{"id": 1}, `{"id":1}`},
+		{"application/json", `{"id": 1}`, `{"id": 1}`},
+		{"", []any{"a", true}, `["a",true]`},
+		{
+			"application/x-www-form-urlencoded",
+			map[string]any{
+				"name": "Rex Junior",
+				"tags": []any{"dog", 1},
+				"meta": map[string]any{"age": 3},
+			},
+			"meta=%7B%22age%22%3A3%7D&name=Rex+Junior&tags=dog&tags=1",
+		},
+		{"text/plain; charset=utf-8", 42, "42"},
+		{"text/plain", float64(10000000), "10000000"},
+		{"text/plain", "hello", "hello"},
+		{"application/xml", "<pet/>", "<pet/>"},
+		{
+			"application/xml",
+			map[string]any{"pet": "Rex"},
+			"<pet>Rex</pet>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			data, contentType, err := EncodeBody(
+				tt.contentType,
+				tt.payload,
+			)
+			require.NoError(t, err)
+			assert.Equal(t, tt.contentType, contentType)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestEncodeBody_Multipart(t *testing.T) {
+	data, contentType, err := EncodeBody(
+		"multipart/form-data",
+		map[string]any{
+			"petId":    10,
+			"file":     []byte("\x89PNG"),
+			"metadata": map[string]any{"kind": "photo"},
+			"tags":     []any{"a", "b"},
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []multipartPart{
+		{"file", "file", "application/octet-stream", "\x89PNG"},
+		{"metadata", "", "application/json", `{"kind":"photo"}`},
+		{"petId", "", "", "10"},
+		{"tags", "", "", "a"},
+		{"tags", "", "", "b"},
+	}, readMultipartParts(t, contentType, data))
+}
+
+func TestEncodeBody_MultipartFiles(t *testing.T) {
+	var model models.RequestBody
+	require.NoError(
+		t,
+		yaml.Unmarshal([]byte(`contentType: multipart/form-data
+payload:
+  petId: 10
+  photo:
+    filename: pet.png
+    contentType: image/png
+    contentEncoding: base64
+    content: iVBORw==
+  notes:
+    filename: notes.txt
+    content: Good boy
+  metadata:
+    filename: pet.png
+    size: 4
+`), &model),
+	)
+	body, err := NewRequestBody(&model)
+	require.NoError(t, err)
+
+	data, contentType, err := body.Encode("", &EvalContext{})
+	require.NoError(t, err)
+	assert.Equal(t, []multipartPart{
+		{
+			"metadata",
+			"",
+			"application/json",
+			`{"filename":"pet.png","size":4}`,
+		},
+		{
+			"notes",
+			"notes.txt",
+			"application/octet-stream",
+			"Good boy",
+		},
+		{"petId", "", "", "10"},
+		{"photo", "pet.png", "image/png", "\x89PNG"},
+	}, readMultipartParts(t, contentType, data))
+
+	_, _, err = EncodeBody("multipart/form-data", map[string]any{
+		"photo": map[string]any{
+			"filename":        "pet.png",
+			"contentEncoding": "gzip",
+			"content":         "",
+		},
+	})
+	assert.EqualError(
+		t,
+		err,
+		`multipart form field "photo": file "pet.png": unsupported content encoding gzip`,
+	)
+}
+
+// multipartPart is a part of a multipart form read back by a test.
+type multipartPart struct {
+	name        string
+	fileName    string
+	contentType string
+	content     string
+}
+
+// readMultipartParts returns the parts of the multipart form data sent
+// with contentType.
+func readMultipartParts(
+	t *testing.T,
+	contentType string,
+	data []byte,
+) []multipartPart {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+
+	var parts []multipartPart
+	reader := multipart.NewReader(
+		bytes.NewReader(data),
+		params["boundary"],
+	)
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(p)
+		require.NoError(t, err)
+		parts = append(parts, multipartPart{
+			name:        p.FormName(),
+			fileName:    p.FileName(),
+			contentType: p.Header.Get("Content-Type"),
+			content:     string(content),
+		})
+	}
+	return parts
+}
+
+func TestEncodeBody_Invalid(t *testing.T) {
+	tests := []struct {
+		contentType string
+		payload     any
+		expected    string
+	}{
+		{
+			"application/x-www-form-urlencoded",
+			[]any{"a"},
+			"a form payload must be an object, got []interface {}",
+		},
+		{
+			"multipart/form-data",
+			42,
+			"a multipart form payload must be an object, got int",
+		},
+		{
+			"application/soap+xml",
+			map[string]any{},
+			"an xml payload must be an object with a single member, got map[string]interface {}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			_, _, err := EncodeBody(tt.contentType, tt.payload)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestEncodeXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  any
+		expected string
+	}{
+		{
+			name:     "scalar",
+			payload:  map[string]any{"id": float64(10000000)},
+			expected: "<id>10000000</id>",
+		},
+		{
+			name: "object",
+			payload: map[string]any{"pet": map[string]any{
+				"name":   "Rex & co",
+				"id":     float64(10),
+				"status": nil,
+			}},
+			expected: "<pet><id>10</id><name>Rex &amp; co</name><status></status></pet>",
+		},
+		{
+			name: "array",
+			payload: map[string]any{"pet": map[string]any{
+				"tags": []any{
+					"dog",
+					map[string]any{"#text": "small"},
+				},
+			}},
+			expected: "<pet><tags>dog</tags><tags>small</tags></pet>",
+		},
+		{
+			name: "attributes",
+			payload: map[string]any{"pet": map[string]any{
+				"@id":    float64(10),
+				"@kind":  `"dog"`,
+				"#text":  "Rex",
+				"status": "available",
+			}},
+			expected: `<pet id="10" kind="&#34;dog&#34;">Rex<status>available</status></pet>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeXML(tt.payload)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestEncodeXML_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload any
+	}{
+		{"array", []any{map[string]any{"pet": "Rex"}}},
+		{
+			"several roots",
+			map[string]any{"pet": "Rex", "store": "Main"},
+		},
+		{"invalid name", map[string]any{"a b": "c"}},
+		{
+			"empty name",
+			map[string]any{"pet": map[string]any{"": "c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EncodeXML(tt.payload)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -2,10 +2,414 @@ package v1
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/bragdonD/arazzo-go/v1/expression"
 )
 
-func ResolveRuntimeExpression(expr expression.Expr, spec *Spec) (any, error) {
-	return nil, fmt.Errorf("resolveRuntimeExpression is not yet implemented")
+// ResolveRuntimeExpression evaluates the runtime expression expr
+// against ctx and returns the value it references. The type of the
+// referenced value is preserved. If the referenced data is missing
+// from ctx, an [UnresolvedExpressionError] is returned.
+func ResolveRuntimeExpression(
+	expr expression.Expr,
+	ctx *EvalContext,
+) (any, error) {
+	if ctx == nil {
+		ctx = &EvalContext{}
+	}
+	visitor := &runtimeExpressionVisitor{
+		ctx:        ctx,
+		expression: expressionString(expr),
+	}
+	value := expr.Accept(visitor)
+	if visitor.err != nil {
+		return nil, visitor.err
+	}
+	return value, nil
+}
+
+// Resolve evaluates the runtime expression expr against the context.
+// It is a shorthand for [ResolveRuntimeExpression].
+func (ctx *EvalContext) Resolve(expr expression.Expr) (any, error) {
+	return ResolveRuntimeExpression(expr, ctx)
+}
+
+// runtimeExpressionVisitor is a visitor that resolves runtime
+// expressions against an evaluation context.
+type runtimeExpressionVisitor struct {
+	ctx *EvalContext
+	// expression is the textual representation of the expression
+	// being resolved, used to report errors.
+	expression string
+	// source is the message, $request. or $response., referenced by
+	// the source node being visited.
+	source string
+	// err stores any encountered error during traversal.
+	err error
+}
+
+// fail records an [UnresolvedExpressionError] and returns nil.
+func (v *runtimeExpressionVisitor) fail(
+	format string,
+	args ...any,
+) any {
+	v.err = &UnresolvedExpressionError{
+		Expression: v.expression,
+		Reason:     fmt.Sprintf(format, args...),
+	}
+	return nil
+}
+
+// VisitSingleExpressionNode resolves the $url, $method and
+// $statusCode expressions.
+func (v *runtimeExpressionVisitor) VisitSingleExpressionNode(
+	n *expression.SingleExpressionNode,
+) any {
+	switch n.Value {
+	case expression.ABNFExpressionURL:
+		if v.ctx.Request == nil || v.ctx.Request.URL == nil {
+			return v.fail("no request is available")
+		}
+		return v.ctx.Request.URL.String()
+	case expression.ABNFExpressionMethod:
+		if v.ctx.Request == nil {
+			return v.fail("no request is available")
+		}
+		return v.ctx.Request.Method
+	case expression.ABNFExpressionStatusCode:
+		if v.ctx.Response == nil {
+			return v.fail("no response is available")
+		}
+		return v.ctx.Response.StatusCode
+	}
+	return v.fail("unknown expression")
+}
+
+// VisitExpressionWithNameNode resolves the expressions referencing
+// the workflow, the steps or the Arazzo document.
+func (v *runtimeExpressionVisitor) VisitExpressionWithNameNode(
+	n *expression.ExpressionWithNameNode,
+) any {
+	name := n.Name.Value
+	switch n.Value {
+	case expression.ABNFExpressionInputs:
+		return v.lookup(v.ctx.Inputs, name, "input")
+	case expression.ABNFExpressionOutputs:
+		return v.lookup(v.ctx.CalledOutputs, name, "output")
+	case expression.ABNFExpressionSteps:
+		return v.resolveStep(name)
+	case expression.ABNFExpressionWorkflows:
+		return v.resolveWorkflow(name)
+	case expression.ABNFExpressionSourceDescriptions:
+		return v.resolveSourceDescription(name)
+	case expression.ABNFExpressionComponents:
+		kind, name, _ := strings.Cut(name, ".")
+		return v.resolveComponent(kind, name)
+	case expression.ABNFExpressionComponentsInputs:
+		return v.resolveComponent("inputs", name)
+	case expression.ABNFExpressionComponentsParameters:
+		return v.resolveComponent("parameters", name)
+	case expression.ABNFExpressionComponentsSuccessActions:
+		return v.resolveComponent("successActions", name)
+	case expression.ABNFExpressionComponentsFailureActions:
+		return v.resolveComponent("failureActions", name)
+	}
+	return v.fail("unknown expression")
+}
+
+// VisitExpressionWithSourceNode resolves the $request. and $response.
+// expressions by visiting their source.
+func (v *runtimeExpressionVisitor) VisitExpressionWithSourceNode(
+	n *expression.ExpressionWithSourceNode,
+) any {
+	v.source = n.Value
+	return n.Source.Accept(v)
+}
+
+// VisitHeaderReferenceNode resolves a header of the request or the
+// response. Only the first value of the header is available.
+func (v *runtimeExpressionVisitor) VisitHeaderReferenceNode(
+	n *expression.HeaderReferenceNode,
+) any {
+	var header http.Header
+	switch v.source {
+	case expression.ABNFExpressionRequest:
+		if v.ctx.Request == nil {
+			return v.fail("no request is available")
+		}
+		header = v.ctx.Request.Header
+	case expression.ABNFExpressionResponse:
+		if v.ctx.Response == nil {
+			return v.fail("no response is available")
+		}
+		header = v.ctx.Response.Header
+	}
+	values := header.Values(n.Token.Value)
+	if len(values) == 0 {
+		return v.fail("header %q not found", n.Token.Value)
+	}
+	return values[0]
+}
+
+// VisitQueryReferenceNode resolves a query parameter of the request.
+func (v *runtimeExpressionVisitor) VisitQueryReferenceNode(
+	n *expression.QueryReferenceNode,
+) any {
+	if v.source != expression.ABNFExpressionRequest {
+		return v.fail(
+			"query parameters are only available on requests",
+		)
+	}
+	if v.ctx.Request == nil || v.ctx.Request.URL == nil {
+		return v.fail("no request is available")
+	}
+	values, ok := v.ctx.Request.URL.Query()[n.Name.Value]
+	if !ok || len(values) == 0 {
+		return v.fail("query parameter %q not found", n.Name.Value)
+	}
+	return values[0]
+}
+
+// VisitPathReferenceNode resolves a path parameter of the request.
+func (v *runtimeExpressionVisitor) VisitPathReferenceNode(
+	n *expression.PathReferenceNode,
+) any {
+	if v.source != expression.ABNFExpressionRequest {
+		return v.fail(
+			"path parameters are only available on requests",
+		)
+	}
+	if v.ctx.Request == nil {
+		return v.fail("no request is available")
+	}
+	value, ok := v.ctx.Request.PathParams[n.Name.Value]
+	if !ok {
+		return v.fail("path parameter %q not found", n.Name.Value)
+	}
+	return value
+}
+
+// VisitBodyReferenceNode resolves the body of the request or the
+// response, or the part of it referenced by a JSON pointer.
+func (v *runtimeExpressionVisitor) VisitBodyReferenceNode(
+	n *expression.BodyReferenceNode,
+) any {
+	var body any
+	switch v.source {
+	case expression.ABNFExpressionRequest:
+		if v.ctx.Request == nil {
+			return v.fail("no request is available")
+		}
+		body = v.ctx.Request.Body
+	case expression.ABNFExpressionResponse:
+		if v.ctx.Response == nil {
+			return v.fail("no response is available")
+		}
+		body = v.ctx.Response.Body
+	}
+	if n.JSONPointer == nil {
+		return body
+	}
+	value, err := resolveJSONPointer(body, n.JSONPointer.Value)
+	if err != nil {
+		return v.fail("%v", err)
+	}
+	return value
+}
+
+// VisitNameNode returns the value of the name.
+func (v *runtimeExpressionVisitor) VisitNameNode(
+	n *expression.NameNode,
+) any {
+	return n.Value
+}
+
+// VisitTokenNode returns the value of the token.
+func (v *runtimeExpressionVisitor) VisitTokenNode(
+	n *expression.TokenNode,
+) any {
+	return n.Value
+}
+
+// VisitJSONPointerNode returns the value of the JSON pointer.
+func (v *runtimeExpressionVisitor) VisitJSONPointerNode(
+	n *expression.JSONPointerNode,
+) any {
+	return n.Value
+}
+
+// lookup returns the value stored under name in values. Names may
+// contain dots, in which case nested objects are traversed when no
+// value is stored under the full name.
+func (v *runtimeExpressionVisitor) lookup(
+	values map[string]any,
+	name string,
+	kind string,
+) any {
+	value, ok := lookupName(values, name)
+	if !ok {
+		return v.fail("%s %q not found", kind, name)
+	}
+	return value
+}
+
+// lookupName returns the value stored under name in values, walking
+// nested objects along the dot separated segments of name.
+func lookupName(values map[string]any, name string) (any, bool) {
+	if value, ok := values[name]; ok {
+		return value, true
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] != '.' {
+			continue
+		}
+		nested, ok := values[name[:i]].(map[string]any)
+		if !ok {
+			continue
+		}
+		if value, ok := lookupName(nested, name[i+1:]); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// resolveStep resolves a $steps.<stepId>.outputs[.<name>] expression.
+func (v *runtimeExpressionVisitor) resolveStep(name string) any {
+	stepId, field, name := splitQualifiedName(name)
+	outputs, ok := v.ctx.Steps[stepId]
+	if !ok {
+		return v.fail("step %q has not been executed", stepId)
+	}
+	if field != "outputs" {
+		return v.fail("steps only expose their outputs")
+	}
+	if name == "" {
+		return outputs
+	}
+	return v.lookup(outputs, name, "output")
+}
+
+// resolveWorkflow resolves a $workflows.<workflowId>.inputs[.<name>]
+// or $workflows.<workflowId>.outputs[.<name>] expression.
+func (v *runtimeExpressionVisitor) resolveWorkflow(name string) any {
+	workflowId, field, name := splitQualifiedName(name)
+	workflow, ok := v.ctx.Workflows[workflowId]
+	if !ok {
+		return v.fail("workflow %q has not been executed", workflowId)
+	}
+	var values map[string]any
+	var kind string
+	switch field {
+	case "inputs":
+		values, kind = workflow.Inputs, "input"
+	case "outputs":
+		values, kind = workflow.Outputs, "output"
+	default:
+		return v.fail(
+			"workflows only expose their inputs and outputs",
+		)
+	}
+	if name == "" {
+		return values
+	}
+	return v.lookup(values, name, kind)
+}
+
+// resolveSourceDescription resolves a $sourceDescriptions.<name>
+// expression and the fields of the source description.
+func (v *runtimeExpressionVisitor) resolveSourceDescription(
+	name string,
+) any {
+	if v.ctx.Spec == nil {
+		return v.fail("no arazzo document is available")
+	}
+	sourceName, field, _ := splitQualifiedName(name)
+	for _, source := range v.ctx.Spec.model.SourcesDescriptions {
+		if source.Name != sourceName {
+			continue
+		}
+		switch field {
+		case "":
+			return source
+		case "name":
+			return source.Name
+		case "url":
+			return source.Url
+		case "type":
+			if source.Type == nil {
+				return nil
+			}
+			return string(*source.Type)
+		}
+		return v.fail("unknown source description field %q", field)
+	}
+	return v.fail("source description %q not found", sourceName)
+}
+
+// resolveComponent resolves a component of the given kind within the
+// components object of the Arazzo document.
+func (v *runtimeExpressionVisitor) resolveComponent(
+	kind string,
+	name string,
+) any {
+	if v.ctx.Spec == nil || v.ctx.Spec.model.Components == nil {
+		return v.fail("no components are available")
+	}
+	components := v.ctx.Spec.model.Components
+	var value any
+	var ok bool
+	switch kind {
+	case "inputs":
+		value, ok = components.Inputs[name]
+	case "parameters":
+		value, ok = components.Parameters[name]
+	case "successActions":
+		value, ok = components.SuccessActions[name]
+	case "failureActions":
+		value, ok = components.FailureActions[name]
+	default:
+		return v.fail("unknown component type %q", kind)
+	}
+	if !ok {
+		return v.fail("component %q not found in %s", name, kind)
+	}
+	return value
+}
+
+// splitQualifiedName splits a name of the form <id>.<field>.<name>.
+// The field and the name are empty when missing.
+func splitQualifiedName(name string) (string, string, string) {
+	parts := strings.SplitN(name, ".", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	return parts[0], parts[1], parts[2]
+}
+
+// expressionString returns the textual representation of a runtime
+// expression.
+func expressionString(expr expression.Expr) string {
+	switch n := expr.(type) {
+	case *expression.SingleExpressionNode:
+		return n.Value
+	case *expression.ExpressionWithNameNode:
+		return n.Value + n.Name.Value
+	case *expression.ExpressionWithSourceNode:
+		return n.Value + expressionString(n.Source)
+	case *expression.HeaderReferenceNode:
+		return n.Value + n.Token.Value
+	case *expression.QueryReferenceNode:
+		return n.Value + n.Name.Value
+	case *expression.PathReferenceNode:
+		return n.Value + n.Name.Value
+	case *expression.BodyReferenceNode:
+		if n.JSONPointer == nil {
+			return n.Value
+		}
+		return n.Value + n.JSONPointerStart + n.JSONPointer.Value
+	}
+	return (&expression.ASTPrinter{}).Stringify(expr)
 }
@@ -176,8 +176,23 @@ func (p *Parser) pathReference() (SourceNode, error) {
 // bodyReference parses a body reference.
 func (p *Parser) bodyReference() (SourceNode, error) {
 	body := p.previous().Value
+	// The JSON pointer is optional, without it the reference targets
+	// the whole body.
+	if p.isAtEnd() {
+		return &BodyReferenceNode{
+			Value: body,
+		}, nil
+	}
 	if p.match(JSONPointerStartToken) {
 		jsonPointerStart := p.previous().Value
+		// An empty JSON pointer references the whole document.
+		if p.isAtEnd() {
+			return &BodyReferenceNode{
+				Value:            body,
+				JSONPointerStart: jsonPointerStart,
+				JSONPointer:      &JSONPointerNode{},
+			}, nil
+		}
 		if p.match(JSONPointerReferenceToken) {
 			jsonPointer, err := p.jsonPointer()
 			if err != nil {
@@ -265,8 +280,17 @@ func (p *Parser) isAtEnd() bool {
 	return p.current == len(p.tokens)
 }
 
-// peek returns the current token.
+// peek returns the current token. At the end of the token list, it
+// returns a token with no type positioned right after the last token.
 func (p *Parser) peek() LexerToken {
+	if p.isAtEnd() {
+		end := LexerToken{Type: -1}
+		if len(p.tokens) > 0 {
+			last := p.tokens[len(p.tokens)-1]
+			end.Position = last.Position + len(last.Value)
+		}
+		return end
+	}
 	return p.tokens[p.current]
 }
 
@@ -28,6 +28,9 @@ func TestParser(t *testing.T) {
 			"($response. (body # /status))",
 			false,
 		},
+		{"$response.body", "($response. body)", false},
+		{"$response.body#", "($response. (body # ))", false},
+		{"$response.query", "", true},
 		{
 			"$response.header.Server",
 			"($response. (header. Server))",
@@ -0,0 +1,208 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/bragdonD/arazzo-go/v1/expression"
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEvalContext returns an evaluation context populated with a
+// request, a response and the state of a workflow execution.
+func newTestEvalContext() *EvalContext {
+	requestURL, _ := url.Parse(
+		"https://petstore.example.com/pet/42?status=available",
+	)
+	return &EvalContext{
+		Spec: &Spec{
+			model: &models.Spec{
+				SourcesDescriptions: []models.SourceDescription{
+					{
+						Name: "petstore",
+						Url:  "./petstore.yaml",
+						Type: models.SourceDescriptionTypeOpenAPI.ToPtr(),
+					},
+				},
+				Components: &models.Components{
+					Parameters: map[string]models.Parameter{
+						"page": {Name: "page", Value: "1"},
+					},
+				},
+			},
+		},
+		Request: &Request{
+			URL:        requestURL,
+			Method:     http.MethodPost,
+			Header:     http.Header{"Accept": {"application/json"}},
+			PathParams: map[string]string{"petId": "42"},
+			Body: map[string]any{
+				"user": map[string]any{"uuid": "u-1"},
+			},
+		},
+		Response: &Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"X-Rate-Limit": {"100", "200"},
+			},
+			Body: map[string]any{
+				"status": "available",
+				"tags":   []any{"dog", "cute"},
+			},
+		},
+		Inputs: map[string]any{
+			"username": "john",
+			"pet":      map[string]any{"name": "Rex"},
+		},
+		CalledOutputs: map[string]any{"bar": true},
+		Steps: map[string]map[string]any{
+			"loginStep": {"sessionToken": "token"},
+		},
+		Workflows: map[string]*WorkflowContext{
+			"login": {
+				Inputs:  map[string]any{"username": "jane"},
+				Outputs: map[string]any{"token": "abc"},
+			},
+		},
+	}
+}
+
+func TestResolveRuntimeExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{
+			"$url",
+			"https://petstore.example.com/pet/42?status=available",
+		},
+		{"$method", http.MethodPost},
+		{"$statusCode", http.StatusOK},
+		{"$request.header.accept", "application/json"},
+		{"$request.query.status", "available"},
+		{"$request.path.petId", "42"},
+		{"$request.body#/user/uuid", "u-1"},
+		{"$response.header.X-Rate-Limit", "100"},
+		{"$response.body#/status", "available"},
+		{"$response.body#/tags/1", "cute"},
+		{
+			"$response.body",
+			map[string]any{
+				"status": "available",
+				"tags":   []any{"dog", "cute"},
+			},
+		},
+		{"$inputs.username", "john"},
+		{"$inputs.pet.name", "Rex"},
+		{"$outputs.bar", true},
+		{"$steps.loginStep.outputs.sessionToken", "token"},
+		{"$workflows.login.inputs.username", "jane"},
+		{"$workflows.login.outputs.token", "abc"},
+		{"$sourceDescriptions.petstore.url", "./petstore.yaml"},
+		{
+			"$components.parameters.page",
+			models.Parameter{Name: "page", Value: "1"},
+		},
+	}
+
+	ctx := newTestEvalContext()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := expression.Parse(tt.input)
+			require.NoError(t, err)
+
+			value, err := ResolveRuntimeExpression(expr, ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestResolveRuntimeExpression_Unresolved(t *testing.T) {
+	tests := []struct {
+		input  string
+		reason string
+	}{
+		{"$request.header.X-Missing", `header "X-Missing" not found`},
+		{
+			"$response.query.status",
+			"query parameters are only available on requests",
+		},
+		{
+			"$response.body#/owner",
+			`json pointer "/owner": member "owner" not found`,
+		},
+		{"$inputs.password", `input "password" not found`},
+		{
+			"$steps.getPet.outputs.pet",
+			`step "getPet" has not been executed`,
+		},
+		{
+			"$workflows.login.steps",
+			"workflows only expose their inputs and outputs",
+		},
+		{
+			"$sourceDescriptions.unknown.url",
+			`source description "unknown" not found`,
+		},
+	}
+
+	ctx := newTestEvalContext()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := expression.Parse(tt.input)
+			require.NoError(t, err)
+
+			_, err = ResolveRuntimeExpression(expr, ctx)
+			var unresolved *UnresolvedExpressionError
+			require.True(t, errors.As(err, &unresolved))
+			assert.Equal(t, tt.input, unresolved.Expression)
+			assert.Equal(t, tt.reason, unresolved.Reason)
+		})
+	}
+
+	expr, err := expression.Parse("$statusCode")
+	require.NoError(t, err)
+	_, err = ResolveRuntimeExpression(expr, &EvalContext{})
+	assert.EqualError(
+		t,
+		err,
+		`arazzo-go: unable to resolve runtime expression "$statusCode": no response is available`,
+	)
+}
+
+func TestValue_Evaluate(t *testing.T) {
+	tests := []struct {
+		input    any
+		constant bool
+		expected any
+	}{
+		{"available", true, "available"},
+		{42, true, 42},
+		{"$inputs.username", false, "john"},
+		{"{$response.body#/status}", false, "available"},
+		{"Bearer {$inputs.username}", false, "Bearer john"},
+		{
+			"{$inputs.username}:{$response.body#/tags/0} {x}",
+			false,
+			"john:dog {x}",
+		},
+		{"{$inputs.pet}", false, map[string]any{"name": "Rex"}},
+		{"pet {$inputs.pet}", false, `pet {"name":"Rex"}`},
+		{"{not an expression}", true, "{not an expression}"},
+	}
+
+	ctx := newTestEvalContext()
+	for _, tt := range tests {
+		value := NewValue(tt.input)
+		assert.Equal(t, tt.constant, value.IsConstant())
+
+		result, err := value.Evaluate(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, result)
+	}
+}
@@ -1,6 +1,12 @@
 package v1
 
-import "github.com/bragdonD/arazzo-go/v1/models"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bragdonD/arazzo-go/v1/models"
+)
 
 // FailureAction is a struct that represents an Arazzo specification
 // 1.0.X failure action object.
@@ -14,3 +20,117 @@ type FailureAction struct {
 	stepId     *string
 	criteria   []*Criterion
 }
+
+func NewFailureAction(
+	model *models.FailureAction,
+) (*FailureAction, error) {
+	action := &FailureAction{
+		model:      model,
+		name:       model.Name,
+		workflowId: model.WorkflowId,
+		stepId:     model.StepId,
+		criteria:   []*Criterion{},
+	}
+
+	var err error
+	switch model.Type {
+	case models.FailureActionTypeEnd:
+	case models.FailureActionTypeGoto:
+		err = checkGotoTarget(model.StepId, model.WorkflowId)
+	case models.FailureActionTypeRetry:
+		// The step or workflow executed before retrying is optional.
+		if model.StepId != nil && model.WorkflowId != nil {
+			err = errors.New(
+				"stepId and workflowId are mutually exclusive",
+			)
+		}
+		if model.RetryDelay != nil && *model.RetryDelay < 0 {
+			err = errors.New("retryDelay must not be negative")
+		}
+		if model.RetryLimit != nil && *model.RetryLimit < 0 {
+			err = errors.New("retryLimit must not be negative")
+		}
+	default:
+		err = fmt.Errorf("unknown type %q", model.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failure action %q: %w",
+			action.name,
+			err,
+		)
+	}
+
+	for i := range model.Criteria {
+		criterion, err := NewCriterion(&model.Criteria[i])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failure action %q: %w",
+				action.name,
+				err,
+			)
+		}
+		action.criteria = append(action.criteria, criterion)
+	}
+
+	return action, nil
+}
+
+func (f *FailureAction) GetModel() *models.FailureAction {
+	return f.model
+}
+
+func (f *FailureAction) GetName() string {
+	return f.name
+}
+
+func (f *FailureAction) GetType() models.FailureActionType {
+	return f.model.Type
+}
+
+// GetStepId returns the step a goto action transfers control to, or
+// a retry action executes before retrying. It returns an empty
+// string if the action does not target a step.
+func (f *FailureAction) GetStepId() string {
+	if f.stepId == nil {
+		return ""
+	}
+	return *f.stepId
+}
+
+// GetWorkflowId returns the workflow a goto action transfers control
+// to, or a retry action executes before retrying. It returns an empty
+// string if the action does not target a workflow.
+func (f *FailureAction) GetWorkflowId() string {
+	if f.workflowId == nil {
+		return ""
+	}
+	return *f.workflowId
+}
+
+// GetRetryDelay returns the delay to wait before retrying the step.
+func (f *FailureAction) GetRetryDelay() time.Duration {
+	if f.model.RetryDelay == nil {
+		return 0
+	}
+	return time.Duration(*f.model.RetryDelay * float64(time.Second))
+}
+
+// GetRetryLimit returns the maximum number of times the step is
+// retried. A single retry is attempted when the limit is omitted.
+func (f *FailureAction) GetRetryLimit() int {
+	if f.model.RetryLimit == nil {
+		return 1
+	}
+	return *f.model.RetryLimit
+}
+
+func (f *FailureAction) GetCriteria() []*Criterion {
+	return f.criteria
+}
+
+// Matches reports whether every criterion of the action is satisfied
+// in ctx. An action without criteria always matches.
+func (f *FailureAction) Matches(ctx *EvalContext) (bool, error) {
+	return matchCriteria(f.criteria, ctx)
+}
@@ -0,0 +1,276 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const (
+	// inputsResourceURL is the URL of the document the input schemas
+	// are compiled from. It holds the reusable schemas of the
+	// components object so that $ref such as
+	// "#/components/inputs/<name>" resolve as in the Arazzo document.
+	inputsResourceURL = "arazzo-go://inputs.json"
+	// componentsInputsRef is the prefix of the references to the
+	// reusable input schemas of the components object.
+	componentsInputsRef = "#/components/inputs/"
+)
+
+// inputErrorPrinter prints the messages of the input validation
+// errors.
+var inputErrorPrinter = message.NewPrinter(language.English)
+
+// InputSchema is a compiled JSON Schema 2020-12 describing the inputs
+// of a workflow.
+type InputSchema struct {
+	schema map[string]any
+	// components holds the reusable input schemas of the components
+	// object by name.
+	components map[string]any
+	compiled   *jsonschema.Schema
+}
+
+// InputError describes an input which does not conform to the input
+// schema of a workflow.
+type InputError struct {
+	// Path is the JSON pointer of the invalid input.
+	Path string
+	// Message describes why the input is invalid.
+	Message string
+}
+
+// InputValidationError is returned when the inputs of a workflow do
+// not conform to its input schema.
+type InputValidationError struct {
+	// Errors lists every invalid input.
+	Errors []InputError
+}
+
+// Error returns a formatted error message listing the invalid inputs.
+func (e *InputValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = fmt.Sprintf("%s: %s", err.Path, err.Message)
+	}
+	return fmt.Sprintf(
+		"arazzo-go: invalid inputs: %s",
+		strings.Join(messages, "; "),
+	)
+}
+
+// NewInputSchema compiles schema, the inputs of a workflow. References
+// to the reusable input schemas of components are resolved.
+func NewInputSchema(
+	schema map[string]any,
+	components *Components,
+) (*InputSchema, error) {
+	inputSchema := &InputSchema{
+		schema:     schema,
+		components: components.GetInputs(),
+	}
+
+	doc, err := toJSONValue(map[string]any{
+		"schema": schema,
+		"components": map[string]any{
+			"inputs": inputSchema.components,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	if err := compiler.AddResource(inputsResourceURL, doc); err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	inputSchema.compiled, err = compiler.Compile(
+		inputsResourceURL + "#/schema",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	return inputSchema, nil
+}
+
+func (s *InputSchema) GetSchema() map[string]any {
+	return s.schema
+}
+
+// Validate applies the default values declared by the schema to the
+// missing inputs and validates the result. It returns a copy of
+// inputs holding the defaults, inputs being left untouched. The
+// returned error is an [InputValidationError] if an input is invalid.
+func (s *InputSchema) Validate(
+	inputs map[string]any,
+) (map[string]any, error) {
+	withDefaults, _ := s.applyDefaults(
+		s.schema,
+		deepCopyJSON(inputs),
+	).(map[string]any)
+	if withDefaults == nil {
+		withDefaults = map[string]any{}
+	}
+
+	instance, err := toJSONValue(withDefaults)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inputs: %w", err)
+	}
+	err = s.compiled.Validate(instance)
+	if err == nil {
+		return withDefaults, nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+	return nil, &InputValidationError{
+		Errors: inputErrors(validationErr),
+	}
+}
+
+// Filter returns the inputs declared by the properties of the schema,
+// including the ones of its allOf subschemas, the other inputs being
+// dropped. inputs is left untouched.
+func (s *InputSchema) Filter(inputs map[string]any) map[string]any {
+	filtered := map[string]any{}
+	for name := range s.properties(s.schema) {
+		if value, ok := inputs[name]; ok {
+			filtered[name] = value
+		}
+	}
+	return filtered
+}
+
+// properties returns the schemas of the properties declared by
+// schema and its allOf subschemas by name.
+func (s *InputSchema) properties(schema any) map[string]any {
+	properties := map[string]any{}
+	obj := s.resolve(schema)
+	if obj == nil {
+		return properties
+	}
+	if allOf, ok := obj["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			maps.Copy(properties, s.properties(sub))
+		}
+	}
+	if declared, ok := obj["properties"].(map[string]any); ok {
+		maps.Copy(properties, declared)
+	}
+	return properties
+}
+
+// applyDefaults sets the default values of the properties declared by
+// schema which are missing from value, recursively, and returns the
+// updated value.
+func (s *InputSchema) applyDefaults(schema any, value any) any {
+	obj := s.resolve(schema)
+	if obj == nil {
+		return value
+	}
+	if allOf, ok := obj["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			value = s.applyDefaults(sub, value)
+		}
+	}
+	properties, ok := obj["properties"].(map[string]any)
+	fields, isObject := value.(map[string]any)
+	if !ok || !isObject {
+		return value
+	}
+	for name, property := range properties {
+		field, present := fields[name]
+		if present {
+			fields[name] = s.applyDefaults(property, field)
+			continue
+		}
+		if def, ok := s.resolve(property)["default"]; ok {
+			fields[name] = deepCopyJSON(def)
+		}
+	}
+	return fields
+}
+
+// resolve returns schema as an object, following a reference to a
+// reusable input schema. It returns nil if schema is not an object.
+func (s *InputSchema) resolve(schema any) map[string]any {
+	// The number of references followed is bounded so that circular
+	// references do not loop forever.
+	for range len(s.components) + 1 {
+		obj, ok := schema.(map[string]any)
+		if !ok {
+			return nil
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj
+		}
+		name, ok := strings.CutPrefix(ref, componentsInputsRef)
+		if !ok {
+			return obj
+		}
+		schema = s.components[name]
+	}
+	return nil
+}
+
+// inputErrors flattens a validation error into the errors of every
+// invalid input. A missing required property is reported at the path
+// of the property.
+func inputErrors(err *jsonschema.ValidationError) []InputError {
+	if len(err.Causes) > 0 {
+		errs := []InputError{}
+		for _, cause := range err.Causes {
+			errs = append(errs, inputErrors(cause)...)
+		}
+		return errs
+	}
+	if required, ok := err.ErrorKind.(*kind.Required); ok {
+		errs := make([]InputError, len(required.Missing))
+		for i, name := range required.Missing {
+			errs[i] = InputError{
+				Path: inputPath(
+					append(err.InstanceLocation, name),
+				),
+				Message: "is required",
+			}
+		}
+		return errs
+	}
+	return []InputError{{
+		Path:    inputPath(err.InstanceLocation),
+		Message: err.ErrorKind.LocalizedString(inputErrorPrinter),
+	}}
+}
+
+// inputPath returns the JSON pointer made of tokens.
+func inputPath(tokens []string) string {
+	if len(tokens) == 0 {
+		return "/"
+	}
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		token = strings.ReplaceAll(token, "~", "~0")
+		sb.WriteString(strings.ReplaceAll(token, "/", "~1"))
+	}
+	return sb.String()
+}
+
+// toJSONValue converts value to the representation of JSON values
+// expected by the JSON Schema validator.
+func toJSONValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+}
@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputSchema_Validate(t *testing.T) {
+	components := NewComponents(&models.Components{
+		Inputs: map[string]any{
+			"credentials": map[string]any{
+				"type":     "object",
+				"required": []any{"username"},
+				"properties": map[string]any{
+					"username": map[string]any{"type": "string"},
+					"password": map[string]any{
+						"type":    "string",
+						"default": "secret",
+					},
+				},
+			},
+		},
+	})
+	schema, err := NewInputSchema(map[string]any{
+		"type":     "object",
+		"required": []any{"petId", "credentials"},
+		"properties": map[string]any{
+			"petId": map[string]any{"type": "integer", "minimum": 1},
+			"status": map[string]any{
+				"type":    "string",
+				"enum":    []any{"available", "sold"},
+				"default": "available",
+			},
+			"credentials": map[string]any{
+				"$ref": "#/components/inputs/credentials",
+			},
+		},
+	}, components)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		inputs   map[string]any
+		expected map[string]any
+		errors   []InputError
+	}{
+		{
+			name: "defaults",
+			inputs: map[string]any{
+				"petId":       7,
+				"credentials": map[string]any{"username": "john"},
+			},
+			expected: map[string]any{
+				"petId":  7,
+				"status": "available",
+				"credentials": map[string]any{
+					"username": "john",
+					"password": "secret",
+				},
+			},
+		},
+		{
+			name: "provided values are kept",
+			inputs: map[string]any{
+				"petId":  7,
+				"status": "sold",
+				"credentials": map[string]any{
+					"username": "john",
+					"password": "p4ss",
+				},
+			},
+			expected: map[string]any{
+				"petId":  7,
+				"status": "sold",
+				"credentials": map[string]any{
+					"username": "john",
+					"password": "p4ss",
+				},
+			},
+		},
+		{
+			name:   "missing inputs",
+			inputs: nil,
+			errors: []InputError{
+				{Path: "/petId", Message: "is required"},
+				{Path: "/credentials", Message: "is required"},
+			},
+		},
+		{
+			name: "invalid inputs",
+			inputs: map[string]any{
+				"petId":       0,
+				"status":      "lost",
+				"credentials": map[string]any{"username": 42},
+			},
+			errors: []InputError{
+				{Path: "/petId", Message: "minimum: got 0, want 1"},
+				{
+					Path:    "/status",
+					Message: "value must be one of 'available', 'sold'",
+				},
+				{
+					Path:    "/credentials/username",
+					Message: "got number, want string",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs, err := schema.Validate(tt.inputs)
+			if tt.errors == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, inputs)
+				return
+			}
+			var validationErr *InputValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.ElementsMatch(t, tt.errors, validationErr.Errors)
+		})
+	}
+}
+
+func TestNewInputSchema_Invalid(t *testing.T) {
+	_, err := NewInputSchema(map[string]any{
+		"$ref": "#/components/inputs/missing",
+	}, NewComponents(nil))
+	assert.ErrorContains(t, err, "invalid input schema")
+}
@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/speakeasy-api/jsonpath/pkg/jsonpath"
+	"github.com/vmware-labs/yaml-jsonpath/pkg/yamlpath"
+	"gopkg.in/yaml.v3"
+)
+
+// JSONPath is a compiled JSONPath query. Two dialects are supported:
+// [RFC9535], which is the default, and
+// [draft-goessner-dispatch-jsonpath-00].
+//
+// [RFC9535]: https://tools.ietf.org/html/rfc9535
+// [draft-goessner-dispatch-jsonpath-00]: https://datatracker.ietf.org/doc/html/draft-goessner-dispatch-jsonpath-00
+type JSONPath struct {
+	input   string
+	version string
+	query   func(root *yaml.Node) ([]*yaml.Node, error)
+}
+
+// NewJSONPath compiles the query input in the dialect selected by
+// version. An empty version selects RFC 9535.
+func NewJSONPath(input string, version string) (*JSONPath, error) {
+	path := &JSONPath{
+		input:   input,
+		version: version,
+	}
+
+	switch version {
+	case "":
+		compiled, err := jsonpath.NewPath(input)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid JSONPath %q: %w",
+				input,
+				err,
+			)
+		}
+		path.query = func(root *yaml.Node) ([]*yaml.Node, error) {
+			return compiled.Query(root), nil
+		}
+	case models.JSONPathVersionGoessner:
+		compiled, err := yamlpath.NewPath(input)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid JSONPath %q: %w",
+				input,
+				err,
+			)
+		}
+		path.query = compiled.Find
+	default:
+		return nil, fmt.Errorf(
+			"unsupported JSONPath version %q",
+			version,
+		)
+	}
+
+	return path, nil
+}
+
+func (p *JSONPath) String() string {
+	return p.input
+}
+
+func (p *JSONPath) GetVersion() string {
+	return p.version
+}
+
+// Query runs the query against value, a decoded JSON document, and
+// returns the values of the selected nodes.
+func (p *JSONPath) Query(value any) ([]any, error) {
+	root, err := toYAMLNode(value)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := p.query(root)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to query JSONPath %q: %w",
+			p.input,
+			err,
+		)
+	}
+	values := make([]any, 0, len(nodes))
+	for _, node := range nodes {
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return nil, fmt.Errorf(
+				"failed to decode JSONPath result: %w",
+				err,
+			)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// toYAMLNode converts a decoded JSON document to the YAML node tree
+// the JSONPath implementations operate on.
+func toYAMLNode(value any) (*yaml.Node, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to encode JSONPath argument: %w",
+			err,
+		)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf(
+			"failed to decode JSONPath argument: %w",
+			err,
+		)
+	}
+	if document.Kind == yaml.DocumentNode &&
+		len(document.Content) == 1 {
+		return document.Content[0], nil
+	}
+	return &document, nil
+}
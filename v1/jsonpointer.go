@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	jsonpointergo "github.com/bragdond/jsonpointer-go"
+)
+
+// jsonPointerTokens splits a JSON pointer into its decoded reference
+// tokens. An empty pointer references the whole document and has no
+// tokens.
+func jsonPointerTokens(pointer string) ([]string, error) {
+	if pointer == jsonpointergo.JSONPointerEmptyPointer {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(
+		pointer,
+		jsonpointergo.JSONPointerSeparatorToken,
+	) {
+		return nil, fmt.Errorf(
+			"json pointer %q must start with %q",
+			pointer,
+			jsonpointergo.JSONPointerSeparatorToken,
+		)
+	}
+	tokens := strings.Split(
+		pointer[1:],
+		jsonpointergo.JSONPointerSeparatorToken,
+	)
+	for i, token := range tokens {
+		token = strings.ReplaceAll(
+			token,
+			jsonpointergo.JSONPointerSlashEncoded,
+			jsonpointergo.JSONPointerSeparatorToken,
+		)
+		tokens[i] = strings.ReplaceAll(
+			token,
+			jsonpointergo.JSONPointerTildaEncoded,
+			jsonpointergo.JSONPointerEscapeToken,
+		)
+	}
+	return tokens, nil
+}
+
+// resolveJSONPointer returns the value referenced by pointer within
+// document. Unlike [jsonpointergo.JSONPointer.GetValue], the document
+// does not have to be a JSON object.
+func resolveJSONPointer(document any, pointer string) (any, error) {
+	tokens, err := jsonPointerTokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+	value := document
+	for _, token := range tokens {
+		switch v := value.(type) {
+		case map[string]any:
+			child, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf(
+					"json pointer %q: member %q not found",
+					pointer,
+					token,
+				)
+			}
+			value = child
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf(
+					"json pointer %q: invalid index %q for an array of length %d",
+					pointer,
+					token,
+					len(v),
+				)
+			}
+			value = v[index]
+		default:
+			return nil, fmt.Errorf(
+				"json pointer %q: cannot reference %q within a %T",
+				pointer,
+				token,
+				value,
+			)
+		}
+	}
+	return value, nil
+}
@@ -29,5 +29,23 @@ type Components struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (c *Components) UnmarshalJSON(data []byte) error {
+	type components Components
+	extensions, err := unmarshalWithExtensions(data, (*components)(c))
+	if err != nil {
+		return err
+	}
+	c.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (c Components) MarshalJSON() ([]byte, error) {
+	type components Components
+	return marshalWithExtensions(components(c), c.Extensions)
 }
@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 )
 
 // Criterion is a struct that represents an Arazzo specification 1.0.X
@@ -39,7 +40,25 @@ type Criterion struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (c *Criterion) UnmarshalJSON(data []byte) error {
+	type criterion Criterion
+	extensions, err := unmarshalWithExtensions(data, (*criterion)(c))
+	if err != nil {
+		return err
+	}
+	c.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (c Criterion) MarshalJSON() ([]byte, error) {
+	type criterion Criterion
+	return marshalWithExtensions(criterion(c), c.Extensions)
 }
 
 // CriterionTypeOrCriterionExpressionType allows a criterion to use
@@ -49,28 +68,51 @@ type CriterionTypeOrCriterionExpressionType struct {
 	CriterionExpressionType *CriterionExpressionType `json:",omitempty"`
 }
 
-// UnmarshalJSON implements json.Unmarshaler interface.
+// UnmarshalJSON implements json.Unmarshaler interface. A string is a
+// [CriterionType] and an object having a type and a version is a
+// [CriterionExpressionType].
 func (c *CriterionTypeOrCriterionExpressionType) UnmarshalJSON(
 	data []byte,
 ) error {
 	var criterionType CriterionType
 	if err := json.Unmarshal(data, &criterionType); err == nil {
-		c.CriterionType = &criterionType
+		*c = CriterionTypeOrCriterionExpressionType{
+			CriterionType: &criterionType,
+		}
 		return nil
 	}
 
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return errors.New(
+			"criterion type must be a string or a criterion expression type object",
+		)
+	}
+	for _, key := range []string{"type", "version"} {
+		if _, ok := fields[key]; !ok {
+			return fmt.Errorf(
+				"criterion expression type object must have a %s",
+				key,
+			)
+		}
+	}
 	var criterionExpressionType CriterionExpressionType
-	if err := json.Unmarshal(data, &criterionExpressionType); err == nil {
-		c.CriterionExpressionType = &criterionExpressionType
-		return nil
+	if err := json.Unmarshal(data, &criterionExpressionType); err != nil {
+		return err
 	}
-	return errors.New(
-		"data does not match any of the allowed types (CriterionType, CriterionExpressionType)",
-	)
+	*c = CriterionTypeOrCriterionExpressionType{
+		CriterionExpressionType: &criterionExpressionType,
+	}
+	return nil
 }
 
 // MarshalJSON implements json.Marshaler interface.
 func (c CriterionTypeOrCriterionExpressionType) MarshalJSON() ([]byte, error) {
+	if c.CriterionType != nil && c.CriterionExpressionType != nil {
+		return nil, errors.New(
+			"CriterionType and CriterionExpressionType are mutually exclusive",
+		)
+	}
 	if c.CriterionType != nil {
 		return json.Marshal(c.CriterionType)
 	}
@@ -125,7 +167,31 @@ type CriterionExpressionType struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (c *CriterionExpressionType) UnmarshalJSON(data []byte) error {
+	type criterionExpressionType CriterionExpressionType
+	extensions, err := unmarshalWithExtensions(
+		data,
+		(*criterionExpressionType)(c),
+	)
+	if err != nil {
+		return err
+	}
+	c.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (c CriterionExpressionType) MarshalJSON() ([]byte, error) {
+	type criterionExpressionType CriterionExpressionType
+	return marshalWithExtensions(
+		criterionExpressionType(c),
+		c.Extensions,
+	)
 }
 
 // CriterionExpressionTypeType is a string that represents the type of
@@ -141,3 +207,21 @@ const (
 	// expression type.
 	CriterionExpressionTypeTypeXPath CriterionExpressionTypeType = "xpath"
 )
+
+const (
+	// JSONPathVersionGoessner is the version of a JSONPath criterion
+	// expression type selecting the JSONPath dialect described by
+	// [draft-goessner-dispatch-jsonpath-00].
+	//
+	// [draft-goessner-dispatch-jsonpath-00]: https://datatracker.ietf.org/doc/html/draft-goessner-dispatch-jsonpath-00
+	JSONPathVersionGoessner = "draft-goessner-dispatch-jsonpath-00"
+	// XPathVersion10 is the version of an XPath criterion expression
+	// type selecting XML Path Language 1.0.
+	XPathVersion10 = "xpath-10"
+	// XPathVersion20 is the version of an XPath criterion expression
+	// type selecting XML Path Language 2.0.
+	XPathVersion20 = "xpath-20"
+	// XPathVersion30 is the version of an XPath criterion expression
+	// type selecting XML Path Language 3.0.
+	XPathVersion30 = "xpath-30"
+)
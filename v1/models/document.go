@@ -0,0 +1,264 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a YAML or JSON Arazzo document parsed along with the
+// position of its nodes, so that the problems found in the document
+// can be located.
+type Document struct {
+	data []byte
+	root *yaml.Node
+}
+
+// ParseDocument parses a YAML or JSON document, JSON being parsed as
+// YAML.
+func ParseDocument(data []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, errors.New(
+			"failed to parse document: empty document",
+		)
+	}
+	return &Document{data: data, root: root.Content[0]}, nil
+}
+
+// Spec unmarshals the document as a Spec. Unlike
+// [ExtractSpecWithDocumentCheck], the Arazzo version is not checked.
+//
+// The numbers and booleans set to string fields are unmarshalled as
+// written in the document, e.g. `workflowId: 1` as "1", YAML not
+// requiring strings to be quoted.
+func (d *Document) Spec() (*Spec, error) {
+	value, err := nodeValue(d.root, reflect.TypeFor[Spec]())
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spec: %w", err)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spec: %w", err)
+	}
+	spec := &Spec{}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spec: %w", err)
+	}
+	return spec, nil
+}
+
+// Value returns the document as a decoded JSON value, numbers being
+// decoded as [json.Number].
+func (d *Document) Value() (any, error) {
+	return nodeValue(d.root, nil)
+}
+
+// Position returns the 1-based line and column of the value designated
+// by pointer, a JSON pointer. The key of an object member is located
+// rather than its value. If pointer designates a value which does not
+// exist, its closest existing ancestor is located.
+func (d *Document) Position(pointer string) (line int, column int) {
+	node := d.root
+	line, column = node.Line, node.Column
+	if pointer == "" {
+		return line, column
+	}
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = strings.NewReplacer("~1", "/", "~0", "~").
+			Replace(token)
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		var key *yaml.Node
+		node, key = childNode(node, token)
+		if node == nil {
+			return line, column
+		}
+		line, column = node.Line, node.Column
+		if key != nil {
+			line, column = key.Line, key.Column
+		}
+	}
+	return line, column
+}
+
+// childNode returns the child of node designated by token, along with
+// its key if node is a mapping. It returns a nil node if there is no
+// such child.
+func childNode(
+	node *yaml.Node,
+	token string,
+) (*yaml.Node, *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == token {
+				return node.Content[i+1], node.Content[i]
+			}
+		}
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(token)
+		if err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index], nil
+		}
+	}
+	return nil, nil
+}
+
+// mergeTag is the tag of the YAML merge key "<<".
+const mergeTag = "!!merge"
+
+// mergeValue merges the mappings of value, a mapping or a sequence of
+// mappings, into merged. The first mappings take precedence.
+func mergeValue(merged map[string]any, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if _, ok := merged[key]; !ok {
+				merged[key] = child
+			}
+		}
+	case []any:
+		for _, item := range v {
+			mergeValue(merged, item)
+		}
+	}
+}
+
+// nodeValue returns the decoded JSON value of node. If t, the type
+// the value is to be unmarshalled into, is not nil, the numbers and
+// booleans set to its string fields are decoded as strings.
+func nodeValue(node *yaml.Node, t reflect.Type) (any, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return nodeValue(node.Alias, t)
+	case yaml.MappingNode:
+		value := make(map[string]any, len(node.Content)/2)
+		merged := map[string]any{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			childType := t
+			if key.ShortTag() != mergeTag {
+				childType = fieldType(t, key.Value)
+			}
+			child, err := nodeValue(node.Content[i+1], childType)
+			if err != nil {
+				return nil, err
+			}
+			if key.ShortTag() == mergeTag {
+				mergeValue(merged, child)
+				continue
+			}
+			value[key.Value] = child
+		}
+		// Merged keys never override the keys of the mapping.
+		for key, child := range merged {
+			if _, ok := value[key]; !ok {
+				value[key] = child
+			}
+		}
+		return value, nil
+	case yaml.SequenceNode:
+		value := make([]any, len(node.Content))
+		for i, item := range node.Content {
+			child, err := nodeValue(item, elemType(t))
+			if err != nil {
+				return nil, err
+			}
+			value[i] = child
+		}
+		return value, nil
+	}
+
+	if isString(t) && node.ShortTag() != "!!null" {
+		return node.Value, nil
+	}
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var value bool
+		err := node.Decode(&value)
+		return value, err
+	case "!!int", "!!float":
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"line %d: invalid number %q",
+				node.Line,
+				node.Value,
+			)
+		}
+		return json.Number(data), nil
+	}
+	return node.Value, nil
+}
+
+// fieldType returns the type of the field of t, a struct or a map
+// type, named name in JSON, or nil if there is none. The fields of the
+// objects of a union type, such as [ParameterOrReusable], are looked
+// up as well.
+func fieldType(t reflect.Type, name string) reflect.Type {
+	t = indirectType(t)
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem()
+	case reflect.Struct:
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if tag == name {
+				return field.Type
+			}
+			if tag != "" {
+				continue
+			}
+			if fieldType := fieldType(field.Type, name); fieldType != nil {
+				return fieldType
+			}
+		}
+	}
+	return nil
+}
+
+// elemType returns the type of the items of t, a slice type, or nil.
+func elemType(t reflect.Type) reflect.Type {
+	t = indirectType(t)
+	if t == nil || t.Kind() != reflect.Slice {
+		return nil
+	}
+	return t.Elem()
+}
+
+// isString reports whether t is a string type.
+func isString(t reflect.Type) bool {
+	t = indirectType(t)
+	return t != nil && t.Kind() == reflect.String
+}
+
+// indirectType returns the type t points to, if t is a pointer type.
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_Position(t *testing.T) {
+	doc, err := ParseDocument([]byte(`{
+  "arazzo": "1.0.0",
+  "workflows": [
+    {"workflowId": "a/b", "steps": []}
+  ]
+}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		pointer string
+		line    int
+		column  int
+	}{
+		{pointer: "", line: 1, column: 1},
+		{pointer: "/arazzo", line: 2, column: 3},
+		{pointer: "/workflows/0", line: 4, column: 5},
+		{pointer: "/workflows/0/steps", line: 4, column: 27},
+		{pointer: "/workflows/0/missing", line: 4, column: 5},
+		{pointer: "/workflows/3", line: 3, column: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			line, column := doc.Position(tt.pointer)
+			assert.Equal(t, tt.line, line)
+			assert.Equal(t, tt.column, column)
+		})
+	}
+}
+
+func TestDocument_Value(t *testing.T) {
+	doc, err := ParseDocument([]byte(`
+base: &base
+  retryLimit: 3
+  retryDelay: 1.5
+action:
+  <<: *base
+list: [true, null, text]
+`))
+	require.NoError(t, err)
+	value, err := doc.Value()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"base": map[string]any{
+			"retryLimit": json.Number("3"),
+			"retryDelay": json.Number("1.5"),
+		},
+		"action": map[string]any{
+			"retryLimit": json.Number("3"),
+			"retryDelay": json.Number("1.5"),
+		},
+		"list": []any{true, nil, "text"},
+	}, value)
+}
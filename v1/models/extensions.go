@@ -0,0 +1,101 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// extensionPrefix is the prefix of the name of the fields extending
+// the Arazzo Specification.
+const extensionPrefix = "x-"
+
+// unmarshalWithExtensions unmarshals data, a JSON object, into v and
+// returns the specification extensions of the object, nil if it has
+// none. v must not implement [json.Unmarshaler] itself, which is why
+// the models unmarshal into a type defined from their own.
+//
+// The fields of the object are decoded as raw JSON to collect the
+// extensions, so that only the values of the extensions are decoded a
+// second time.
+func unmarshalWithExtensions(
+	data []byte,
+	v any,
+) (map[string]any, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	var extensions map[string]any
+	for name, field := range fields {
+		if !strings.HasPrefix(name, extensionPrefix) {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal(field, &value); err != nil {
+			return nil, err
+		}
+		if extensions == nil {
+			extensions = map[string]any{}
+		}
+		extensions[name] = value
+	}
+	return extensions, nil
+}
+
+// marshalWithExtensions marshals v, which must marshal as a JSON
+// object, and appends the specification extensions to its fields,
+// sorted by name. v must not implement [json.Marshaler] itself.
+func marshalWithExtensions(
+	v any,
+	extensions map[string]any,
+) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extensions) == 0 {
+		return data, err
+	}
+	if len(data) < 2 || data[0] != '{' {
+		return nil, fmt.Errorf("cannot add extensions to %s", data)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	empty := len(data) == 2
+	for _, name := range slices.Sorted(maps.Keys(extensions)) {
+		if !strings.HasPrefix(name, extensionPrefix) {
+			return nil, fmt.Errorf(
+				"extension %q must begin with %s",
+				name,
+				extensionPrefix,
+			)
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(extensions[name])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to marshal extension %q: %w",
+				name,
+				err,
+			)
+		}
+		if !empty {
+			buf.WriteByte(',')
+		}
+		empty = false
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
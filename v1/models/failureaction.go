@@ -59,7 +59,28 @@ type FailureAction struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (f *FailureAction) UnmarshalJSON(data []byte) error {
+	type failureAction FailureAction
+	extensions, err := unmarshalWithExtensions(
+		data,
+		(*failureAction)(f),
+	)
+	if err != nil {
+		return err
+	}
+	f.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (f FailureAction) MarshalJSON() ([]byte, error) {
+	type failureAction FailureAction
+	return marshalWithExtensions(failureAction(f), f.Extensions)
 }
 
 // FailureActionType is a string that represents the type of failure
@@ -90,26 +111,43 @@ type FailureActionOrReusable struct {
 	Reusable      *Reusable      `json:",omitempty"`
 }
 
-// UnmarshalJSON implements json.Unmarshaler interface.
+// UnmarshalJSON implements json.Unmarshaler interface. The object is
+// a [Reusable] object if it has a reference, and a [FailureAction] object
+// if it has any of name or type.
 func (f *FailureActionOrReusable) UnmarshalJSON(data []byte) error {
-	var failureAction FailureAction
-	if err := json.Unmarshal(data, &failureAction); err == nil {
-		f.FailureAction = &failureAction
+	useReusable, err := isReusable(
+		data,
+		"failure action",
+		"name",
+		"type",
+	)
+	if err != nil {
+		return err
+	}
+	if useReusable {
+		var reusable Reusable
+		if err := json.Unmarshal(data, &reusable); err != nil {
+			return err
+		}
+		*f = FailureActionOrReusable{Reusable: &reusable}
 		return nil
 	}
 
-	var reusable Reusable
-	if err := json.Unmarshal(data, &reusable); err == nil {
-		f.Reusable = &reusable
-		return nil
+	var failureAction FailureAction
+	if err := json.Unmarshal(data, &failureAction); err != nil {
+		return err
 	}
-	return errors.New(
-		"data does not match any of the allowed types (FailureAction, Reusable)",
-	)
+	*f = FailureActionOrReusable{FailureAction: &failureAction}
+	return nil
 }
 
 // MarshalJSON implements json.Marshaler interface.
 func (f FailureActionOrReusable) MarshalJSON() ([]byte, error) {
+	if f.FailureAction != nil && f.Reusable != nil {
+		return nil, errors.New(
+			"FailureAction and Reusable are mutually exclusive",
+		)
+	}
 	if f.FailureAction != nil {
 		return json.Marshal(f.FailureAction)
 	}
@@ -118,3 +156,19 @@ func (f FailureActionOrReusable) MarshalJSON() ([]byte, error) {
 	}
 	return nil, errors.New("no data to marshal")
 }
+
+// ToFailureAction returns the failure action, resolving it within
+// components if it is a reusable object.
+func (f *FailureActionOrReusable) ToFailureAction(
+	components *Components,
+) (*FailureAction, error) {
+	if f.FailureAction != nil {
+		return f.FailureAction, nil
+	}
+
+	if f.Reusable == nil {
+		return nil, errors.New("no data to marshal")
+	}
+
+	return f.Reusable.ToFailureAction(components)
+}
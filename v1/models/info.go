@@ -22,7 +22,25 @@ type Info struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (i *Info) UnmarshalJSON(data []byte) error {
+	type info Info
+	extensions, err := unmarshalWithExtensions(data, (*info)(i))
+	if err != nil {
+		return err
+	}
+	i.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (i Info) MarshalJSON() ([]byte, error) {
+	type info Info
+	return marshalWithExtensions(info(i), i.Extensions)
 }
 
 func ExtractSpecInfoWithDocumentCheck(doc []byte) (*Info, error) {
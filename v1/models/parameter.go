@@ -29,7 +29,25 @@ type Parameter struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (p *Parameter) UnmarshalJSON(data []byte) error {
+	type parameter Parameter
+	extensions, err := unmarshalWithExtensions(data, (*parameter)(p))
+	if err != nil {
+		return err
+	}
+	p.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	type parameter Parameter
+	return marshalWithExtensions(parameter(p), p.Extensions)
 }
 
 // ParameterLocation is a string that represents the location of a
@@ -70,26 +88,38 @@ type ParameterOrReusable struct {
 	Reusable  *Reusable  `json:",omitempty"`
 }
 
-// UnmarshalJSON implements json.Unmarshaler interface.
+// UnmarshalJSON implements json.Unmarshaler interface. The object is
+// a [Reusable] object if it has a reference, and a [Parameter] object
+// if it has any of name or in.
 func (p *ParameterOrReusable) UnmarshalJSON(data []byte) error {
-	var param Parameter
-	if err := json.Unmarshal(data, &param); err == nil {
-		p.Parameter = &param
+	useReusable, err := isReusable(data, "parameter", "name", "in")
+	if err != nil {
+		return err
+	}
+	if useReusable {
+		var reusable Reusable
+		if err := json.Unmarshal(data, &reusable); err != nil {
+			return err
+		}
+		*p = ParameterOrReusable{Reusable: &reusable}
 		return nil
 	}
 
-	var reusable Reusable
-	if err := json.Unmarshal(data, &reusable); err == nil {
-		p.Reusable = &reusable
-		return nil
+	var parameter Parameter
+	if err := json.Unmarshal(data, &parameter); err != nil {
+		return err
 	}
-	return errors.New(
-		"data does not match any of the allowed types (Parameter, Reusable)",
-	)
+	*p = ParameterOrReusable{Parameter: &parameter}
+	return nil
 }
 
 // MarshalJSON implements json.Marshaler interface.
 func (p ParameterOrReusable) MarshalJSON() ([]byte, error) {
+	if p.Parameter != nil && p.Reusable != nil {
+		return nil, errors.New(
+			"Parameter and Reusable are mutually exclusive",
+		)
+	}
 	if p.Parameter != nil {
 		return json.Marshal(p.Parameter)
 	}
@@ -99,7 +129,9 @@ func (p ParameterOrReusable) MarshalJSON() ([]byte, error) {
 	return nil, errors.New("no data to marshal")
 }
 
-func (pr *ParameterOrReusable) ToParameter(components *Components) (*Parameter, error) {
+func (pr *ParameterOrReusable) ToParameter(
+	components *Components,
+) (*Parameter, error) {
 	if pr.Parameter != nil {
 		return pr.Parameter, nil
 	}
@@ -23,5 +23,26 @@ type PayloadReplacement struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (p *PayloadReplacement) UnmarshalJSON(data []byte) error {
+	type payloadReplacement PayloadReplacement
+	extensions, err := unmarshalWithExtensions(
+		data,
+		(*payloadReplacement)(p),
+	)
+	if err != nil {
+		return err
+	}
+	p.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (p PayloadReplacement) MarshalJSON() ([]byte, error) {
+	type payloadReplacement PayloadReplacement
+	return marshalWithExtensions(payloadReplacement(p), p.Extensions)
 }
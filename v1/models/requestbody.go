@@ -24,5 +24,26 @@ type RequestBody struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (r *RequestBody) UnmarshalJSON(data []byte) error {
+	type requestBody RequestBody
+	extensions, err := unmarshalWithExtensions(
+		data,
+		(*requestBody)(r),
+	)
+	if err != nil {
+		return err
+	}
+	r.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (r RequestBody) MarshalJSON() ([]byte, error) {
+	type requestBody RequestBody
+	return marshalWithExtensions(requestBody(r), r.Extensions)
 }
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/bragdonD/arazzo-go/v1/expression"
 )
@@ -19,118 +22,186 @@ type Reusable struct {
 	// Sets a value of the referenced parameter. This is only
 	// applicable for parameter object references.
 	Value string `json:"value,omitempty"`
+	// Allows extensions to the Arazzo Specification. The field name
+	// MUST begin with x-, for example, x-internal-id. Field names
+	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
+	// defined by the OpenAPI Initiative. The value MAY be null, a
+	// primitive, an array or an object.
+	Extensions map[string]any `json:"-"`
 }
 
-// reusableToParameterVisitor is a struct that helps in resolving
-// references from Reusable objects to Parameter objects within
-// the Components object.
-type reusableToParameterVisitor struct {
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (r *Reusable) UnmarshalJSON(data []byte) error {
+	type reusable Reusable
+	extensions, err := unmarshalWithExtensions(data, (*reusable)(r))
+	if err != nil {
+		return err
+	}
+	r.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (r Reusable) MarshalJSON() ([]byte, error) {
+	type reusable Reusable
+	return marshalWithExtensions(reusable(r), r.Extensions)
+}
+
+// isReusable reports whether data, a JSON object, is a [Reusable]
+// object rather than the object named kind, which is identified by
+// any of keys. Objects having a reference as well as any of keys, or
+// none of them, are ambiguous and rejected with an error.
+func isReusable(
+	data []byte,
+	kind string,
+	keys ...string,
+) (bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false, fmt.Errorf(
+			"%s or reusable object must be an object: %w",
+			kind,
+			err,
+		)
+	}
+
+	_, reusable := fields["reference"]
+	var found []string
+	for _, key := range keys {
+		if _, ok := fields[key]; ok {
+			found = append(found, key)
+		}
+	}
+	switch {
+	case reusable && len(found) > 0:
+		return false, fmt.Errorf(
+			"ambiguous %s or reusable object: reference cannot be used along with %s",
+			kind,
+			strings.Join(found, ", "),
+		)
+	case !reusable && len(found) == 0:
+		return false, fmt.Errorf(
+			"%s or reusable object must have either reference or %s",
+			kind,
+			strings.Join(keys, ", "),
+		)
+	}
+	return reusable, nil
+}
+
+// reusableReferenceVisitor is a struct that helps in resolving
+// references from Reusable objects to the objects of a given kind
+// within the Components object.
+type reusableReferenceVisitor struct {
+	// prefix is the runtime expression prefix of the expected kind
+	// of component, e.g. $components.parameters.
+	prefix string
 	// err stores any encountered error during traversal.
 	err error
-	// parameters holds the resolved parameter if successful.
-	parameter *Parameter
+	// name holds the name of the referenced component if successful.
+	name string
+}
+
+// fail records that the reference does not match the expected kind
+// of component.
+func (r *reusableReferenceVisitor) fail() any {
+	r.err = fmt.Errorf("expected %s<name>", r.prefix)
+	r.name = ""
+	return nil
 }
 
 // VisitExpressionWithNameNode implements the Visitor interface for
 // expression.
-func (r *reusableToParameterVisitor) VisitExpressionWithNameNode(expr *expression.ExpressionWithNameNode) any {
-	if expr.Value != expression.ABNFExpressionComponentsParameters {
-		r.err = errors.New("expected $components.parameters.<name>")
-		r.parameter = nil
-		return nil
+func (r *reusableReferenceVisitor) VisitExpressionWithNameNode(
+	expr *expression.ExpressionWithNameNode,
+) any {
+	if expr.Value != r.prefix {
+		return r.fail()
 	}
 
-	r.parameter = &Parameter{
-		Name: expr.Name.Value,
-	}
+	r.name = expr.Name.Value
 	return nil
 }
 
 // VisitSingleExpressionNode implements the Visitor interface for
 // expression.
-func (r *reusableToParameterVisitor) VisitSingleExpressionNode(*expression.SingleExpressionNode) any {
-	r.err = errors.New("expected $components.parameters.<name>")
-	r.parameter = nil
-	return nil
+func (r *reusableReferenceVisitor) VisitSingleExpressionNode(
+	*expression.SingleExpressionNode,
+) any {
+	return r.fail()
 }
 
 // VisitExpressionWithSourceNode implements the Visitor interface for
 // expression.
-func (r *reusableToParameterVisitor) VisitExpressionWithSourceNode(*expression.ExpressionWithSourceNode) any {
-	r.err = errors.New("expected $components.parameters.<name>")
-	r.parameter = nil
-	return nil
+func (r *reusableReferenceVisitor) VisitExpressionWithSourceNode(
+	*expression.ExpressionWithSourceNode,
+) any {
+	return r.fail()
 }
 
 // VisitHeaderReferenceNode implements the Visitor interface for
 // expression.
-func (r *reusableToParameterVisitor) VisitHeaderReferenceNode(*expression.HeaderReferenceNode) any {
-	r.err = errors.New("expected $components.parameters.<name>")
-	r.parameter = nil
-	return nil
+func (r *reusableReferenceVisitor) VisitHeaderReferenceNode(
+	*expression.HeaderReferenceNode,
+) any {
+	return r.fail()
 }
 
 // VisitQueryReferenceNode implements the Visitor interface for
 // expression.
-func (r *reusableToParameterVisitor) VisitQueryReferenceNode(*expression.QueryReferenceNode) any {
-	r.err = errors.New("expected $components.parameters.<name>")
-	r.parameter = nil
-	return nil
+func (r *reusableReferenceVisitor) VisitQueryReferenceNode(
+	*expression.QueryReferenceNode,
+) any {
+	return r.fail()
 }
 
 // VisitPathReferenceNode implements the Visitor interface for
 // expression.
-func (r *reusableToParameterVisitor) VisitPathReferenceNode(*expression.PathReferenceNode) any {
-	r.err = errors.New("expected $components.parameters.<name>")
-	r.parameter = nil
-	return nil
+func (r *reusableReferenceVisitor) VisitPathReferenceNode(
+	*expression.PathReferenceNode,
+) any {
+	return r.fail()
 }
 
 // VisitBodyReferenceNode implements the Visitor interface for
 // expression.
-func (r *reusableToParameterVisitor) VisitBodyReferenceNode(*expression.BodyReferenceNode) any {
-	r.err = errors.New("expected $components.parameters.<name>")
-	r.parameter = nil
-	return nil
+func (r *reusableReferenceVisitor) VisitBodyReferenceNode(
+	*expression.BodyReferenceNode,
+) any {
+	return r.fail()
 }
 
 // VisitNameNode implements the Visitor interface for
 // expression.
-func (r *reusableToParameterVisitor) VisitNameNode(*expression.NameNode) any {
-	r.err = errors.New("expected $components.parameters.<name>")
-	r.parameter = nil
-	return nil
+func (r *reusableReferenceVisitor) VisitNameNode(
+	*expression.NameNode,
+) any {
+	return r.fail()
 }
 
 // VisitTokenNode implements the Visitor interface for
 // expression.
-func (r *reusableToParameterVisitor) VisitTokenNode(*expression.TokenNode) any {
-	r.err = errors.New("expected $components.parameters.<name>")
-	r.parameter = nil
-	return nil
+func (r *reusableReferenceVisitor) VisitTokenNode(
+	*expression.TokenNode,
+) any {
+	return r.fail()
 }
 
 // VisitJSONPointerNode implements the Visitor interface for
 // expression.
-func (r *reusableToParameterVisitor) VisitJSONPointerNode(*expression.JSONPointerNode) any {
-	r.err = errors.New("expected $components.parameters.<name>")
-	r.parameter = nil
-	return nil
+func (r *reusableReferenceVisitor) VisitJSONPointerNode(
+	*expression.JSONPointerNode,
+) any {
+	return r.fail()
 }
 
-// ToParameter resolves a reusable object reference to a Parameter object
-// within the Components object.
-//
-// This function parses the reference expression, verifies that it conforms
-// to the expected format, and retrieves the corresponding parameter from
-// the Components object. If a value is set in the reusable object, it is
-// assigned to the parameter.
-func (r *Reusable) ToParameter(components *Components) (*Parameter, error) {
-	if components == nil {
-		return nil, errors.New("components is nil")
-	}
+// referencedName parses the reference of the reusable object and
+// returns the name of the referenced component, which must be of the
+// kind designated by prefix.
+func (r *Reusable) referencedName(prefix string) (string, error) {
 	if r.Reference == "" {
-		return nil, errors.New("reference is empty")
+		return "", errors.New("reference is empty")
 	}
 
 	// Extract the expression from the reference.
@@ -139,20 +210,42 @@ func (r *Reusable) ToParameter(components *Components) (*Parameter, error) {
 	if exprStr[0] == '{' {
 		exprStr, err = expression.Extract(exprStr)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 	}
 	expr, err := expression.Parse(exprStr)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	// Resolve the parameter from the expression.
-	visitor := &reusableToParameterVisitor{}
+	visitor := &reusableReferenceVisitor{prefix: prefix}
 	if expr.Accept(visitor); visitor.err != nil {
-		return nil, visitor.err
+		return "", visitor.err
 	}
-	parameter, ok := components.Parameters[visitor.parameter.Name]
+	return visitor.name, nil
+}
+
+// ToParameter resolves a reusable object reference to a Parameter object
+// within the Components object.
+//
+// This function parses the reference expression, verifies that it conforms
+// to the expected format, and retrieves the corresponding parameter from
+// the Components object. If a value is set in the reusable object, it is
+// assigned to the parameter.
+func (r *Reusable) ToParameter(
+	components *Components,
+) (*Parameter, error) {
+	if components == nil {
+		return nil, errors.New("components is nil")
+	}
+	// Resolve the parameter from the expression.
+	name, err := r.referencedName(
+		expression.ABNFExpressionComponentsParameters,
+	)
+	if err != nil {
+		return nil, err
+	}
+	parameter, ok := components.Parameters[name]
 	if !ok {
 		return nil, errors.New("parameter not found")
 	}
@@ -163,3 +256,45 @@ func (r *Reusable) ToParameter(components *Components) (*Parameter, error) {
 	}
 	return &parameter, nil
 }
+
+// ToSuccessAction resolves a reusable object reference to a
+// SuccessAction object within the Components object.
+func (r *Reusable) ToSuccessAction(
+	components *Components,
+) (*SuccessAction, error) {
+	if components == nil {
+		return nil, errors.New("components is nil")
+	}
+	name, err := r.referencedName(
+		expression.ABNFExpressionComponentsSuccessActions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	successAction, ok := components.SuccessActions[name]
+	if !ok {
+		return nil, errors.New("success action not found")
+	}
+	return &successAction, nil
+}
+
+// ToFailureAction resolves a reusable object reference to a
+// FailureAction object within the Components object.
+func (r *Reusable) ToFailureAction(
+	components *Components,
+) (*FailureAction, error) {
+	if components == nil {
+		return nil, errors.New("components is nil")
+	}
+	name, err := r.referencedName(
+		expression.ABNFExpressionComponentsFailureActions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	failureAction, ok := components.FailureActions[name]
+	if !ok {
+		return nil, errors.New("failure action not found")
+	}
+	return &failureAction, nil
+}
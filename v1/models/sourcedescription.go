@@ -34,7 +34,28 @@ type SourceDescription struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (s *SourceDescription) UnmarshalJSON(data []byte) error {
+	type sourceDescription SourceDescription
+	extensions, err := unmarshalWithExtensions(
+		data,
+		(*sourceDescription)(s),
+	)
+	if err != nil {
+		return err
+	}
+	s.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (s SourceDescription) MarshalJSON() ([]byte, error) {
+	type sourceDescription SourceDescription
+	return marshalWithExtensions(sourceDescription(s), s.Extensions)
 }
 
 // SourceDescriptionType is a string that represents the type of
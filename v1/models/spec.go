@@ -3,8 +3,6 @@ package models
 import (
 	"fmt"
 	"regexp"
-
-	"sigs.k8s.io/yaml"
 )
 
 // Spec is a struct that represents an [Arazzo 1.0.X] specification.
@@ -37,7 +35,25 @@ type Spec struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (s *Spec) UnmarshalJSON(data []byte) error {
+	type spec Spec
+	extensions, err := unmarshalWithExtensions(data, (*spec)(s))
+	if err != nil {
+		return err
+	}
+	s.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (s Spec) MarshalJSON() ([]byte, error) {
+	type spec Spec
+	return marshalWithExtensions(spec(s), s.Extensions)
 }
 
 const (
@@ -47,11 +63,14 @@ const (
 // ExtractSpecWithDocumentCheck extracts a Spec object from a YAML
 // document and checks if the Arazzo version is valid.
 func ExtractSpecWithDocumentCheck(doc []byte) (*Spec, error) {
-	spec := &Spec{}
-
-	if err := yaml.Unmarshal(doc, spec); err != nil {
+	document, err := ParseDocument(doc)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal spec: %w", err)
 	}
+	spec, err := document.Spec()
+	if err != nil {
+		return nil, err
+	}
 
 	versionRe, err := regexp.Compile(VersionRegex)
 	if err != nil {
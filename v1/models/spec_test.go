@@ -2,18 +2,16 @@ package models_test
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/bragdonD/arazzo-go/internal/testutil"
 	v1 "github.com/bragdonD/arazzo-go/v1/models"
 	"github.com/go-test/deep"
 	"sigs.k8s.io/yaml"
 )
 
-// strPtr is a helper function to create string pointers.
-func strPtr(s string) *string {
-	return &s
-}
-
 // yamlEqual is a helper function to compare two YAML strings.
 func yamlEqual(yaml1, yaml2 string) (bool, error) {
 	var obj1, obj2 interface{}
@@ -109,10 +107,10 @@ workflows:
 		Arazzo: "1.0.0",
 		Info: v1.Info{
 			Title: "A pet purchasing workflow",
-			Summary: strPtr(
+			Summary: testutil.Ptr(
 				"This Arazzo Description showcases the workflow for how to purchase a pet through a sequence of API calls.",
 			),
-			Description: strPtr(
+			Description: testutil.Ptr(
 				"This Arazzo Description walks you through the workflow and steps of searching for, selecting, and purchasing an available pet.",
 			),
 			Version: "1.0.1",
@@ -127,10 +125,10 @@ workflows:
 		Workflows: []v1.Workflow{
 			{
 				WorkflowId: "loginUserAndRetrievePet",
-				Summary: strPtr(
+				Summary: testutil.Ptr(
 					"Login User and then retrieve pets",
 				),
-				Description: strPtr(
+				Description: testutil.Ptr(
 					"This workflow lays out the steps to login a user and then retrieve pets",
 				),
 				Inputs: map[string]interface{}{
@@ -143,10 +141,10 @@ workflows:
 				Steps: []v1.Step{
 					{
 						StepId: "loginStep",
-						Description: strPtr(
+						Description: testutil.Ptr(
 							"This step demonstrates the user login step",
 						),
-						OperationId: strPtr("loginUser"),
+						OperationId: testutil.Ptr("loginUser"),
 						Parameters: []v1.ParameterOrReusable{
 							{
 								Parameter: &v1.Parameter{
@@ -174,10 +172,10 @@ workflows:
 					},
 					{
 						StepId: "getPetStep",
-						Description: strPtr(
+						Description: testutil.Ptr(
 							"Retrieve a pet by status from the GET pets endpoint",
 						),
-						OperationPath: strPtr(
+						OperationPath: testutil.Ptr(
 							"{$sourceDescriptions.petstoreDescription.url}#/paths/~1pet~1findByStatus/get",
 						),
 						Parameters: []v1.ParameterOrReusable{
@@ -291,10 +289,10 @@ workflows:
 		Arazzo: "1.0.0",
 		Info: v1.Info{
 			Title: "A pet purchasing workflow",
-			Summary: strPtr(
+			Summary: testutil.Ptr(
 				"This Arazzo Description showcases the workflow for how to purchase a pet through a sequence of API calls.",
 			),
-			Description: strPtr(
+			Description: testutil.Ptr(
 				"This Arazzo Description walks you through the workflow and steps of searching for, selecting, and purchasing an available pet.",
 			),
 			Version: "1.0.1",
@@ -309,10 +307,10 @@ workflows:
 		Workflows: []v1.Workflow{
 			{
 				WorkflowId: "loginUserAndRetrievePet",
-				Summary: strPtr(
+				Summary: testutil.Ptr(
 					"Login User and then retrieve pets",
 				),
-				Description: strPtr(
+				Description: testutil.Ptr(
 					"This workflow lays out the steps to login a user and then retrieve pets",
 				),
 				Inputs: map[string]interface{}{
@@ -325,10 +323,10 @@ workflows:
 				Steps: []v1.Step{
 					{
 						StepId: "loginStep",
-						Description: strPtr(
+						Description: testutil.Ptr(
 							"This step demonstrates the user login step",
 						),
-						OperationId: strPtr("loginUser"),
+						OperationId: testutil.Ptr("loginUser"),
 						Parameters: []v1.ParameterOrReusable{
 							{
 								Parameter: &v1.Parameter{
@@ -356,10 +354,10 @@ workflows:
 					},
 					{
 						StepId: "getPetStep",
-						Description: strPtr(
+						Description: testutil.Ptr(
 							"Retrieve a pet by status from the GET pets endpoint",
 						),
-						OperationPath: strPtr(
+						OperationPath: testutil.Ptr(
 							"{$sourceDescriptions.petstoreDescription.url}#/paths/~1pet~1findByStatus/get",
 						),
 						Parameters: []v1.ParameterOrReusable{
@@ -504,10 +502,10 @@ func TestSpec_UnmarshalJSON(t *testing.T) {
 		Arazzo: "1.0.0",
 		Info: v1.Info{
 			Title: "A pet purchasing workflow",
-			Summary: strPtr(
+			Summary: testutil.Ptr(
 				"This Arazzo Description showcases the workflow for how to purchase a pet through a sequence of API calls.",
 			),
-			Description: strPtr(
+			Description: testutil.Ptr(
 				"This Arazzo Description walks you through the workflow and steps of searching for, selecting, and purchasing an available pet.",
 			),
 			Version: "1.0.1",
@@ -522,10 +520,10 @@ func TestSpec_UnmarshalJSON(t *testing.T) {
 		Workflows: []v1.Workflow{
 			{
 				WorkflowId: "loginUserAndRetrievePet",
-				Summary: strPtr(
+				Summary: testutil.Ptr(
 					"Login User and then retrieve pets",
 				),
-				Description: strPtr(
+				Description: testutil.Ptr(
 					"This workflow lays out the steps to login a user and then retrieve pets",
 				),
 				Inputs: map[string]interface{}{
@@ -538,10 +536,10 @@ func TestSpec_UnmarshalJSON(t *testing.T) {
 				Steps: []v1.Step{
 					{
 						StepId: "loginStep",
-						Description: strPtr(
+						Description: testutil.Ptr(
 							"This step demonstrates the user login step",
 						),
-						OperationId: strPtr("loginUser"),
+						OperationId: testutil.Ptr("loginUser"),
 						Parameters: []v1.ParameterOrReusable{
 							{
 								Parameter: &v1.Parameter{
@@ -569,10 +567,10 @@ func TestSpec_UnmarshalJSON(t *testing.T) {
 					},
 					{
 						StepId: "getPetStep",
-						Description: strPtr(
+						Description: testutil.Ptr(
 							"Retrieve a pet by status from the GET pets endpoint",
 						),
-						OperationPath: strPtr(
+						OperationPath: testutil.Ptr(
 							"{$sourceDescriptions.petstoreDescription.url}#/paths/~1pet~1findByStatus/get",
 						),
 						Parameters: []v1.ParameterOrReusable{
@@ -718,10 +716,10 @@ func TestSpec_MarshalJSON(t *testing.T) {
 		Arazzo: "1.0.0",
 		Info: v1.Info{
 			Title: "A pet purchasing workflow",
-			Summary: strPtr(
+			Summary: testutil.Ptr(
 				"This Arazzo Description showcases the workflow for how to purchase a pet through a sequence of API calls.",
 			),
-			Description: strPtr(
+			Description: testutil.Ptr(
 				"This Arazzo Description walks you through the workflow and steps of searching for, selecting, and purchasing an available pet.",
 			),
 			Version: "1.0.1",
@@ -736,10 +734,10 @@ func TestSpec_MarshalJSON(t *testing.T) {
 		Workflows: []v1.Workflow{
 			{
 				WorkflowId: "loginUserAndRetrievePet",
-				Summary: strPtr(
+				Summary: testutil.Ptr(
 					"Login User and then retrieve pets",
 				),
-				Description: strPtr(
+				Description: testutil.Ptr(
 					"This workflow lays out the steps to login a user and then retrieve pets",
 				),
 				Inputs: map[string]interface{}{
@@ -752,10 +750,10 @@ func TestSpec_MarshalJSON(t *testing.T) {
 				Steps: []v1.Step{
 					{
 						StepId: "loginStep",
-						Description: strPtr(
+						Description: testutil.Ptr(
 							"This step demonstrates the user login step",
 						),
-						OperationId: strPtr("loginUser"),
+						OperationId: testutil.Ptr("loginUser"),
 						Parameters: []v1.ParameterOrReusable{
 							{
 								Parameter: &v1.Parameter{
@@ -783,10 +781,10 @@ func TestSpec_MarshalJSON(t *testing.T) {
 					},
 					{
 						StepId: "getPetStep",
-						Description: strPtr(
+						Description: testutil.Ptr(
 							"Retrieve a pet by status from the GET pets endpoint",
 						),
-						OperationPath: strPtr(
+						OperationPath: testutil.Ptr(
 							"{$sourceDescriptions.petstoreDescription.url}#/paths/~1pet~1findByStatus/get",
 						),
 						Parameters: []v1.ParameterOrReusable{
@@ -833,3 +831,365 @@ func TestSpec_MarshalJSON(t *testing.T) {
 		t.Fatalf("expected and actual JSON strings are not equal")
 	}
 }
+
+func TestSpec_Extensions(t *testing.T) {
+	yamlSpecData := `
+arazzo: 1.0.0
+x-owner: payments
+info:
+  title: Extensions
+  version: 1.0.0
+  x-audience: internal
+sourceDescriptions:
+  - name: petstore
+    url: petstore.openapi.yaml
+    x-environment: staging
+workflows:
+  - workflowId: buyPet
+    x-environments: [staging, production]
+    parameters:
+      - reference: $components.parameters.token
+        x-required: true
+    steps:
+      - stepId: placeOrder
+        operationId: placeOrder
+        x-timeout: 30
+        parameters:
+          - name: quantity
+            in: query
+            value: 2
+            x-internal-id: 7
+        requestBody:
+          payload: {}
+          x-template: order
+          replacements:
+            - target: /petId
+              value: $inputs.petId
+              x-source: inputs
+        successCriteria:
+          - condition: $.id
+            context: $response.body
+            type:
+              type: jsonpath
+              version: draft-goessner-dispatch-jsonpath-00
+              x-dialect: goessner
+            x-severity: high
+        onSuccess:
+          - name: done
+            type: end
+            x-reason: ordered
+        onFailure:
+          - name: retry
+            type: retry
+            x-reason: transient
+components:
+  x-generated: true
+  parameters:
+    token:
+      name: token
+      in: header
+      value: $inputs.token
+      x-secret: true
+`
+	document, err := v1.ParseDocument([]byte(yamlSpecData))
+	if err != nil {
+		t.Fatalf("could not parse YAML data: %v", err)
+	}
+	parsed, err := document.Spec()
+	if err != nil {
+		t.Fatalf("could not unmarshal YAML data: %v", err)
+	}
+	spec := *parsed
+
+	workflow := spec.Workflows[0]
+	step := workflow.Steps[0]
+	extensions := []map[string]any{
+		spec.Extensions,
+		spec.Info.Extensions,
+		spec.SourcesDescriptions[0].Extensions,
+		workflow.Extensions,
+		workflow.Parameters[0].Reusable.Extensions,
+		step.Extensions,
+		step.Parameters[0].Parameter.Extensions,
+		step.RequestBody.Extensions,
+		step.RequestBody.Replacements[0].Extensions,
+		step.SuccessCriteria[0].Extensions,
+		step.SuccessCriteria[0].Type.CriterionExpressionType.Extensions,
+		step.OnSuccess[0].SuccessAction.Extensions,
+		step.OnFailure[0].FailureAction.Extensions,
+		spec.Components.Extensions,
+		spec.Components.Parameters["token"].Extensions,
+	}
+	expectedExtensions := []map[string]any{
+		{"x-owner": "payments"},
+		{"x-audience": "internal"},
+		{"x-environment": "staging"},
+		{"x-environments": []any{"staging", "production"}},
+		{"x-required": true},
+		{"x-timeout": float64(30)},
+		{"x-internal-id": float64(7)},
+		{"x-template": "order"},
+		{"x-source": "inputs"},
+		{"x-severity": "high"},
+		{"x-dialect": "goessner"},
+		{"x-reason": "ordered"},
+		{"x-reason": "transient"},
+		{"x-generated": true},
+		{"x-secret": true},
+	}
+	if diff := deep.Equal(expectedExtensions, extensions); diff != nil {
+		t.Fatalf("unexpected extensions: %v", diff)
+	}
+	if step.Parameters[0].Parameter.Value != "2" {
+		t.Fatalf(
+			"expected parameter value %q, got %q",
+			"2",
+			step.Parameters[0].Parameter.Value,
+		)
+	}
+
+	yamlData, err := yaml.Marshal(spec)
+	if err != nil {
+		t.Fatalf("could not marshal the Spec struct: %v", err)
+	}
+	equal, err := yamlEqual(
+		strings.Replace(yamlSpecData, "value: 2", `value: "2"`, 1),
+		string(yamlData),
+	)
+	if err != nil {
+		t.Fatalf("could not compare YAML strings: %v", err)
+	}
+	if !equal {
+		t.Fatalf("extensions were not preserved:\n%s", yamlData)
+	}
+
+	spec.Info.Extensions = map[string]any{"owner": "payments"}
+	if _, err := json.Marshal(spec); err == nil {
+		t.Fatalf(
+			"expected an error for an extension without x- prefix",
+		)
+	}
+}
+
+func TestDocument_Spec_Scalars(t *testing.T) {
+	data := `arazzo: 1.0.0
+workflows:
+  - workflowId: 1
+    dependsOn: [2, three]
+    steps:
+      - stepId: check
+        successCriteria:
+          - condition: true
+        x-retries: 3
+`
+	document, err := v1.ParseDocument([]byte(data))
+	if err != nil {
+		t.Fatalf("could not parse YAML data: %v", err)
+	}
+	spec, err := document.Spec()
+	if err != nil {
+		t.Fatalf("could not unmarshal YAML data: %v", err)
+	}
+	want := []v1.Workflow{{
+		WorkflowId: "1",
+		DependsOn:  []string{"2", "three"},
+		Steps: []v1.Step{{
+			StepId:          "check",
+			SuccessCriteria: []v1.Criterion{{Condition: "true"}},
+			Extensions:      map[string]any{"x-retries": float64(3)},
+		}},
+	}}
+	if diff := deep.Equal(want, spec.Workflows); diff != nil {
+		t.Fatalf("unexpected workflows: %v", diff)
+	}
+
+	// JSON documents are decoded as they are by encoding/json.
+	workflow := v1.Workflow{}
+	err = json.Unmarshal([]byte(`{"workflowId": 1}`), &workflow)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field != "workflowId" {
+		t.Fatalf("expected a type error for workflowId, got %v", err)
+	}
+}
+
+func TestOrReusable_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		// value is a pointer to the zero value of the union type.
+		value any
+		want  any
+	}{
+		{
+			name:  "success action",
+			data:  `{"name":"done","type":"end"}`,
+			value: &v1.SuccessActionOrReusable{},
+			want: &v1.SuccessActionOrReusable{
+				SuccessAction: &v1.SuccessAction{
+					Name: "done",
+					Type: v1.SuccessActionTypeEnd,
+				},
+			},
+		},
+		{
+			name:  "reusable success action",
+			data:  `{"reference":"$components.successActions.done"}`,
+			value: &v1.SuccessActionOrReusable{},
+			want: &v1.SuccessActionOrReusable{
+				Reusable: &v1.Reusable{
+					Reference: "$components.successActions.done",
+				},
+			},
+		},
+		{
+			name:  "failure action",
+			data:  `{"name":"retry","type":"retry","retryLimit":3}`,
+			value: &v1.FailureActionOrReusable{},
+			want: &v1.FailureActionOrReusable{
+				FailureAction: &v1.FailureAction{
+					Name:       "retry",
+					Type:       v1.FailureActionTypeRetry,
+					RetryLimit: func() *int { i := 3; return &i }(),
+				},
+			},
+		},
+		{
+			name:  "reusable failure action",
+			data:  `{"reference":"$components.failureActions.retry"}`,
+			value: &v1.FailureActionOrReusable{},
+			want: &v1.FailureActionOrReusable{
+				Reusable: &v1.Reusable{
+					Reference: "$components.failureActions.retry",
+				},
+			},
+		},
+		{
+			name:  "parameter",
+			data:  `{"name":"token","in":"header","value":"$inputs.token"}`,
+			value: &v1.ParameterOrReusable{},
+			want: &v1.ParameterOrReusable{
+				Parameter: &v1.Parameter{
+					Name:  "token",
+					In:    v1.ParameterLocationHeader.ToPtr(),
+					Value: "$inputs.token",
+				},
+			},
+		},
+		{
+			name:  "reusable parameter",
+			data:  `{"reference":"$components.parameters.token","value":"abc"}`,
+			value: &v1.ParameterOrReusable{},
+			want: &v1.ParameterOrReusable{
+				Reusable: &v1.Reusable{
+					Reference: "$components.parameters.token",
+					Value:     "abc",
+				},
+			},
+		},
+		{
+			name:  "criterion type",
+			data:  `"regex"`,
+			value: &v1.CriterionTypeOrCriterionExpressionType{},
+			want: &v1.CriterionTypeOrCriterionExpressionType{
+				CriterionType: v1.CriterionTypeRegex.ToPtr(),
+			},
+		},
+		{
+			name:  "criterion expression type",
+			data:  `{"type":"xpath","version":"xpath-30"}`,
+			value: &v1.CriterionTypeOrCriterionExpressionType{},
+			want: &v1.CriterionTypeOrCriterionExpressionType{
+				CriterionExpressionType: &v1.CriterionExpressionType{
+					Type:    v1.CriterionExpressionTypeTypeXPath,
+					Version: v1.XPathVersion30,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.data), tt.value); err != nil {
+				t.Fatalf("could not unmarshal JSON data: %v", err)
+			}
+			if diff := deep.Equal(tt.want, tt.value); diff != nil {
+				t.Fatalf("unexpected value: %v", diff)
+			}
+
+			jsonData, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("could not marshal the value: %v", err)
+			}
+			equal, err := jsonEqual(tt.data, string(jsonData))
+			if err != nil {
+				t.Fatalf("could not compare JSON strings: %v", err)
+			}
+			if !equal {
+				t.Fatalf("expected %s, got %s", tt.data, jsonData)
+			}
+		})
+	}
+}
+
+func TestOrReusable_UnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		value any
+		want  string
+	}{
+		{
+			name:  "ambiguous success action",
+			data:  `{"reference":"$components.successActions.done","name":"done"}`,
+			value: &v1.SuccessActionOrReusable{},
+			want:  "ambiguous success action or reusable object: reference cannot be used along with name",
+		},
+		{
+			name:  "ambiguous failure action",
+			data:  `{"reference":"$components.failureActions.retry","name":"retry","type":"retry"}`,
+			value: &v1.FailureActionOrReusable{},
+			want:  "ambiguous failure action or reusable object: reference cannot be used along with name, type",
+		},
+		{
+			name:  "ambiguous parameter",
+			data:  `{"reference":"$components.parameters.token","in":"header"}`,
+			value: &v1.ParameterOrReusable{},
+			want:  "ambiguous parameter or reusable object: reference cannot be used along with in",
+		},
+		{
+			name:  "neither parameter nor reusable",
+			data:  `{"value":"abc"}`,
+			value: &v1.ParameterOrReusable{},
+			want:  "parameter or reusable object must have either reference or name, in",
+		},
+		{
+			name:  "not an object",
+			data:  `"done"`,
+			value: &v1.SuccessActionOrReusable{},
+			want:  "success action or reusable object must be an object",
+		},
+		{
+			name:  "incomplete criterion expression type",
+			data:  `{"type":"jsonpath"}`,
+			value: &v1.CriterionTypeOrCriterionExpressionType{},
+			want:  "criterion expression type object must have a version",
+		},
+		{
+			name:  "invalid criterion type",
+			data:  `42`,
+			value: &v1.CriterionTypeOrCriterionExpressionType{},
+			want:  "criterion type must be a string or a criterion expression type object",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tt.data), tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf(
+					"expected error containing %q, got %v",
+					tt.want,
+					err,
+				)
+			}
+		})
+	}
+}
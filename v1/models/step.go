@@ -102,5 +102,23 @@ type Step struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (s *Step) UnmarshalJSON(data []byte) error {
+	type step Step
+	extensions, err := unmarshalWithExtensions(data, (*step)(s))
+	if err != nil {
+		return err
+	}
+	s.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (s Step) MarshalJSON() ([]byte, error) {
+	type step Step
+	return marshalWithExtensions(step(s), s.Extensions)
 }
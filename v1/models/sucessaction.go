@@ -41,7 +41,28 @@ type SuccessAction struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (s *SuccessAction) UnmarshalJSON(data []byte) error {
+	type successAction SuccessAction
+	extensions, err := unmarshalWithExtensions(
+		data,
+		(*successAction)(s),
+	)
+	if err != nil {
+		return err
+	}
+	s.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (s SuccessAction) MarshalJSON() ([]byte, error) {
+	type successAction SuccessAction
+	return marshalWithExtensions(successAction(s), s.Extensions)
 }
 
 // SuccessActionType is a string that represents the type of success
@@ -70,26 +91,43 @@ type SuccessActionOrReusable struct {
 	Reusable      *Reusable      `json:",omitempty"`
 }
 
-// UnmarshalJSON implements json.Unmarshaler interface.
+// UnmarshalJSON implements json.Unmarshaler interface. The object is
+// a [Reusable] object if it has a reference, and a [SuccessAction] object
+// if it has any of name or type.
 func (s *SuccessActionOrReusable) UnmarshalJSON(data []byte) error {
-	var successAction SuccessAction
-	if err := json.Unmarshal(data, &successAction); err == nil {
-		s.SuccessAction = &successAction
+	useReusable, err := isReusable(
+		data,
+		"success action",
+		"name",
+		"type",
+	)
+	if err != nil {
+		return err
+	}
+	if useReusable {
+		var reusable Reusable
+		if err := json.Unmarshal(data, &reusable); err != nil {
+			return err
+		}
+		*s = SuccessActionOrReusable{Reusable: &reusable}
 		return nil
 	}
 
-	var reusable Reusable
-	if err := json.Unmarshal(data, &reusable); err == nil {
-		s.Reusable = &reusable
-		return nil
+	var successAction SuccessAction
+	if err := json.Unmarshal(data, &successAction); err != nil {
+		return err
 	}
-	return errors.New(
-		"data does not match any of the allowed types (SuccessAction, Reusable)",
-	)
+	*s = SuccessActionOrReusable{SuccessAction: &successAction}
+	return nil
 }
 
 // MarshalJSON implements json.Marshaler interface.
 func (s SuccessActionOrReusable) MarshalJSON() ([]byte, error) {
+	if s.SuccessAction != nil && s.Reusable != nil {
+		return nil, errors.New(
+			"SuccessAction and Reusable are mutually exclusive",
+		)
+	}
 	if s.SuccessAction != nil {
 		return json.Marshal(s.SuccessAction)
 	}
@@ -98,3 +136,19 @@ func (s SuccessActionOrReusable) MarshalJSON() ([]byte, error) {
 	}
 	return nil, errors.New("no data to marshal")
 }
+
+// ToSuccessAction returns the success action, resolving it within
+// components if it is a reusable object.
+func (s *SuccessActionOrReusable) ToSuccessAction(
+	components *Components,
+) (*SuccessAction, error) {
+	if s.SuccessAction != nil {
+		return s.SuccessAction, nil
+	}
+
+	if s.Reusable == nil {
+		return nil, errors.New("no data to marshal")
+	}
+
+	return s.Reusable.ToSuccessAction(components)
+}
@@ -76,5 +76,23 @@ type Workflow struct {
 	// beginning x-oai-, x-oas-, and x-arazzo are reserved for uses
 	// defined by the OpenAPI Initiative. The value MAY be null, a
 	// primitive, an array or an object.
-	Extensions map[string]any `json:"-,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Specification
+// extensions are stored in Extensions.
+func (w *Workflow) UnmarshalJSON(data []byte) error {
+	type workflow Workflow
+	extensions, err := unmarshalWithExtensions(data, (*workflow)(w))
+	if err != nil {
+		return err
+	}
+	w.Extensions = extensions
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (w Workflow) MarshalJSON() ([]byte, error) {
+	type workflow Workflow
+	return marshalWithExtensions(workflow(w), w.Extensions)
 }
@@ -4,8 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"net/url"
+	"path/filepath"
+	"strings"
 
+	arazzo "github.com/bragdonD/arazzo-go"
 	"github.com/pb33f/libopenapi"
 	"github.com/pb33f/libopenapi/datamodel"
 	oai31 "github.com/pb33f/libopenapi/datamodel/high/v3"
@@ -39,33 +42,139 @@ type OAIOperation struct {
 	Path      string
 	Method    HTTPMethod
 	Operation *oai31.Operation
+	// PathItem is the path item the operation is defined in.
+	PathItem *oai31.PathItem
+	// Document is the OpenAPI document the operation is defined in.
+	Document *OAIDocument
 }
 
 // OAIDocument holds an OpenAPI document model and its operations.
 type OAIDocument struct {
-	model      *oai31.Document
+	model *oai31.Document
+	name  string
+	// url is the location the document was loaded from.
+	url        string
 	operations []*OAIOperation
+	// references are the files referenced by the document.
+	references []oaiReference
 }
 
-// NewOAIDocument creates a new OAIDocument from the given source URL.
-// TODO: Allow remote sources to be loaded from a URL or file path.
-func NewOAIDocument(source string) (*OAIDocument, error) {
-	// Load the file
-	file, err := os.ReadFile(source)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+// OAIDocumentOption defines a functional option for configuring the
+// loading of an OAIDocument.
+type OAIDocumentOption func(*oaiDocumentOptions)
+
+// oaiDocumentOptions holds the configuration of the loading of an
+// OAIDocument.
+type oaiDocumentOptions struct {
+	loader *arazzo.Loader
+	config *datamodel.DocumentConfiguration
+}
+
+// WithOAILoader sets the [arazzo.Loader] the OpenAPI document is
+// fetched with. By default, only local files can be loaded.
+func WithOAILoader(loader *arazzo.Loader) OAIDocumentOption {
+	return func(o *oaiDocumentOptions) {
+		o.loader = loader
 	}
+}
+
+// WithOAIDocumentConfiguration sets the configuration the OpenAPI
+// document is built with. The base path or URL used to resolve the
+// references of the document default to the location of the
+// document.
+func WithOAIDocumentConfiguration(
+	config *datamodel.DocumentConfiguration,
+) OAIDocumentOption {
+	return func(o *oaiDocumentOptions) {
+		o.config = config
+	}
+}
 
-	// TODO: Make this configurable by the user
-	config := datamodel.DocumentConfiguration{
+// DefaultOAIDocumentConfiguration returns the configuration OpenAPI
+// documents are built with by default, which allows both file and
+// remote references. Referenced files are loaded with the
+// [arazzo.Loader] of the document, see [NewOAIDocument].
+func DefaultOAIDocumentConfiguration() *datamodel.DocumentConfiguration {
+	return &datamodel.DocumentConfiguration{
 		AllowFileReferences:   true,
 		AllowRemoteReferences: true,
-		BasePath:              source,
+	}
+}
+
+// NewOAIDocument creates a new OAIDocument from the given source URL,
+// either a local file path or a remote URL.
+//
+// The files referenced by the document are loaded with the
+// [arazzo.Loader] as well, following its lookup policy, file system,
+// in-memory documents and cache, unless the configuration sets its own
+// LocalFS or RemoteURLHandler.
+func NewOAIDocument(
+	source string,
+	opts ...OAIDocumentOption,
+) (*OAIDocument, error) {
+	options := &oaiDocumentOptions{
+		loader: arazzo.NewLoader(arazzo.AllowLocalLookup()),
+		config: DefaultOAIDocumentConfiguration(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	file, err := options.loader.LoadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %q: %w", source, err)
+	}
+
+	// The configuration is copied as its base location is specific
+	// to the document.
+	config := *options.config
+	if arazzo.IsRemoteFile(source) {
+		if config.BaseURL == nil {
+			config.BaseURL, _ = url.Parse(source)
+		}
+	} else if config.BasePath == "" {
+		config.BasePath = filepath.Dir(localPath(source))
+	}
+
+	references := &oaiReferenceLoader{
+		loader: options.loader,
+		allowLocal: config.LocalFS == nil &&
+			(config.BasePath != "" || config.AllowFileReferences),
+		allowRemote: config.RemoteURLHandler == nil &&
+			(config.BaseURL != nil || config.AllowRemoteReferences),
+		seen: map[string]bool{documentKey(source): true},
+	}
+	if err := references.load(source, file); err != nil {
+		return nil, err
+	}
+	if config.LocalFS == nil {
+		config.LocalFS, err = newReferenceFS(
+			source,
+			file,
+			references.references,
+			config.Logger,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to load %q: %w",
+				source,
+				err,
+			)
+		}
+	}
+	if config.RemoteURLHandler == nil {
+		config.RemoteURLHandler = referenceURLHandler(
+			options.loader,
+			references.references,
+		)
 	}
 
 	doc, err := libopenapi.NewDocumentWithConfiguration(file, &config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create OpenAPI document: %w", err)
+		return nil, fmt.Errorf(
+			"failed to create OpenAPI document: %w",
+			err,
+		)
 	}
 
 	model, errs := doc.BuildV3Model()
@@ -78,13 +187,22 @@ func NewOAIDocument(source string) (*OAIDocument, error) {
 
 	operations, err := extractOperationsFromOpenAPI(&model.Model)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract operations: %w", err)
+		return nil, fmt.Errorf(
+			"failed to extract operations: %w",
+			err,
+		)
 	}
 
-	return &OAIDocument{
+	document := &OAIDocument{
 		model:      &model.Model,
+		url:        source,
 		operations: operations,
-	}, nil
+		references: references.references,
+	}
+	for _, operation := range operations {
+		operation.Document = document
+	}
+	return document, nil
 }
 
 // extractOperationsFromOpenAPI extracts API operations from an
@@ -119,6 +237,7 @@ func extractOperationsFromOpenAPI(
 					Path:      path,
 					Method:    method,
 					Operation: operation,
+					PathItem:  pathItem,
 				})
 			}
 		}
@@ -127,16 +246,117 @@ func extractOperationsFromOpenAPI(
 	return operations, nil
 }
 
+// GetModel returns the underlying OpenAPI document model.
+func (d *OAIDocument) GetModel() *oai31.Document {
+	return d.model
+}
+
+// GetName returns the name of the source description the document
+// was loaded from.
+func (d *OAIDocument) GetName() string {
+	return d.name
+}
+
+// GetOperations returns every operation defined in the document.
+func (d *OAIDocument) GetOperations() []*OAIOperation {
+	return d.operations
+}
+
+// GetServerURL returns the URL of the first server declared by the
+// document, or an empty string if none is declared. See
+// [ResolveServerURL] for how the URL is resolved.
+func (d *OAIDocument) GetServerURL() (string, error) {
+	return d.resolveServerURL(d.model.Servers)
+}
+
+// resolveServerURL resolves the URL of the first of servers against
+// the location of the document.
+func (d *OAIDocument) resolveServerURL(
+	servers []*oai31.Server,
+) (string, error) {
+	if len(servers) == 0 || servers[0] == nil {
+		return "", nil
+	}
+	return ResolveServerURL(d.url, servers[0])
+}
+
 // GetOperationById searches for an OpenAPI operation by its
 // OperationId.
-// func (idx *Index) GetOperationById(
-// 	operationId string,
-// ) (*OAIOperation, error) {
-// 	for _, operation := range idx.operations {
-// 		if operation.Operation.OperationId == operationId {
-// 			return operation, nil
-// 		}
-// 	}
-
-// 	return nil, fmt.Errorf("operation not found")
-// }
+func (d *OAIDocument) GetOperationById(
+	operationId string,
+) (*OAIOperation, error) {
+	for _, operation := range d.operations {
+		if operation.Operation.OperationId == operationId {
+			return operation, nil
+		}
+	}
+
+	return nil, fmt.Errorf("operation not found")
+}
+
+// GetOperationByPath searches for the OpenAPI operation of the given
+// path template and HTTP method.
+func (d *OAIDocument) GetOperationByPath(
+	path string,
+	method HTTPMethod,
+) (*OAIOperation, error) {
+	for _, operation := range d.operations {
+		if operation.Path == path && operation.Method == method {
+			return operation, nil
+		}
+	}
+
+	return nil, fmt.Errorf("operation not found")
+}
+
+// GetServerURL returns the URL of the server the operation is sent
+// to. The servers of the operation take precedence over the servers
+// of its path item, which take precedence over the servers of the
+// document. It returns an empty string if none is declared.
+func (o *OAIOperation) GetServerURL() (string, error) {
+	if o.Document == nil {
+		return "", fmt.Errorf("operation is not bound to a document")
+	}
+	servers := o.Document.model.Servers
+	if o.PathItem != nil && len(o.PathItem.Servers) > 0 {
+		servers = o.PathItem.Servers
+	}
+	if o.Operation != nil && len(o.Operation.Servers) > 0 {
+		servers = o.Operation.Servers
+	}
+	return o.Document.resolveServerURL(servers)
+}
+
+// ResolveServerURL returns the URL of server, its variables replaced
+// by their default value, resolved against base, the location of the
+// OpenAPI document declaring it. A relative server URL such as
+// "/api/v3" is thus relative to where the document is served from.
+func ResolveServerURL(
+	base string,
+	server *oai31.Server,
+) (string, error) {
+	serverURL := server.URL
+	if server.Variables != nil {
+		for name, variable := range server.Variables.FromOldest() {
+			if variable == nil {
+				continue
+			}
+			serverURL = strings.ReplaceAll(
+				serverURL,
+				"{"+name+"}",
+				variable.Default,
+			)
+		}
+	}
+	if strings.ContainsAny(serverURL, "{}") {
+		return "", fmt.Errorf(
+			"server url %q: undefined variable",
+			serverURL,
+		)
+	}
+	resolved, err := ResolveSourceURL(base, serverURL)
+	if err != nil {
+		return "", fmt.Errorf("server url %q: %w", server.URL, err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,336 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	arazzo "github.com/bragdonD/arazzo-go"
+	oai31 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// referencesTestFiles are an OpenAPI document whose schema is split
+// across files, some of them referenced from a subdirectory.
+var referencesTestFiles = map[string]string{
+	"specs/api.yaml": `openapi: 3.1.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pet:
+    get:
+      operationId: getPet
+      responses:
+        "200":
+          description: successful operation
+          content:
+            application/json:
+              schema:
+                $ref: "schemas/pet.yaml#/Pet"
+`,
+	"specs/schemas/pet.yaml": `Pet:
+  type: object
+  properties:
+    id:
+      $ref: "#/Id"
+    category:
+      $ref: "../common.yaml#/Category"
+Id:
+  type: integer
+`,
+	"specs/common.yaml": `Category:
+  type: string
+  description: The category of the pet.
+`,
+}
+
+func TestNewOAIDocument_References(t *testing.T) {
+	fsys := fstest.MapFS{}
+	documents := map[string][]byte{}
+	for name, data := range referencesTestFiles {
+		fsys[name] = &fstest.MapFile{Data: []byte(data)}
+		documents[name] = []byte(data)
+	}
+
+	tests := []struct {
+		name    string
+		loader  *arazzo.Loader
+		wantErr string
+	}{
+		{
+			name: "fs",
+			loader: arazzo.NewLoader(
+				arazzo.AllowLocalLookup(),
+				arazzo.WithFS(fsys),
+			),
+		},
+		{
+			name:   "documents",
+			loader: arazzo.NewLoader(arazzo.WithDocuments(documents)),
+		},
+		{
+			name: "local lookup not allowed",
+			loader: arazzo.NewLoader(arazzo.WithDocuments(
+				map[string][]byte{
+					"specs/api.yaml": documents["specs/api.yaml"],
+				},
+			)),
+			wantErr: `failed to load "specs/schemas/pet.yaml" referenced by "specs/api.yaml": local file lookup is not allowed`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewOAIDocument(
+				"specs/api.yaml",
+				WithOAILoader(tt.loader),
+			)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			operation, err := doc.GetOperationById("getPet")
+			require.NoError(t, err)
+			schema := operation.Operation.Responses.Codes.GetOrZero(
+				"200",
+			).
+				Content.GetOrZero(
+				"application/json",
+			).Schema.Schema()
+			require.NotNil(t, schema)
+			assert.Equal(t, []string{"object"}, schema.Type)
+			id := schema.Properties.GetOrZero("id").Schema()
+			require.NotNil(t, id)
+			assert.Equal(t, []string{"integer"}, id.Type)
+			category := schema.Properties.GetOrZero("category").
+				Schema()
+			require.NotNil(t, category)
+			assert.Equal(
+				t,
+				"The category of the pet.",
+				category.Description,
+			)
+
+			urls := []string{}
+			for _, reference := range doc.references {
+				urls = append(urls, reference.URL)
+			}
+			assert.Equal(
+				t,
+				[]string{
+					"specs/schemas/pet.yaml",
+					"specs/common.yaml",
+				},
+				urls,
+			)
+		})
+	}
+}
+
+// roundTripperFunc is an [http.RoundTripper] calling itself.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements the http.RoundTripper interface.
+func (f roundTripperFunc) RoundTrip(
+	req *http.Request,
+) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewOAIDocument_Offline(t *testing.T) {
+	api := strings.Replace(
+		referencesTestFiles["specs/api.yaml"],
+		"schemas/pet.yaml",
+		"https://schemas.example.com/pet.yaml",
+		1,
+	)
+	pet := `Pet:
+  type: object
+  properties:
+    category:
+      type: string
+      description: The category of the pet.
+`
+	seed := t.TempDir()
+	client := &http.Client{Transport: roundTripperFunc(
+		func(req *http.Request) (*http.Response, error) {
+			t.Errorf("unexpected request to %s", req.URL)
+			return nil, errors.New("offline")
+		},
+	)}
+	newLoader := func() *arazzo.Loader {
+		cache := arazzo.NewCache(t.TempDir())
+		require.NoError(t, cache.Seed(seed))
+		return arazzo.NewLoader(
+			arazzo.AllowRemoteLookup(),
+			arazzo.WithHTTPClient(client),
+			arazzo.WithCache(cache),
+			arazzo.Offline(),
+			arazzo.WithDocuments(map[string][]byte{
+				"specs/api.yaml": []byte(api),
+			}),
+		)
+	}
+
+	_, err := NewOAIDocument(
+		"specs/api.yaml",
+		WithOAILoader(newLoader()),
+	)
+	var missErr *arazzo.CacheMissError
+	require.ErrorAs(t, err, &missErr)
+	assert.Equal(
+		t,
+		"https://schemas.example.com/pet.yaml",
+		missErr.URL,
+	)
+
+	file := filepath.Join(
+		seed,
+		"https",
+		"schemas.example.com",
+		"pet.yaml",
+	)
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+	require.NoError(t, os.WriteFile(file, []byte(pet), 0o644))
+	doc, err := NewOAIDocument(
+		"specs/api.yaml",
+		WithOAILoader(newLoader()),
+	)
+	require.NoError(t, err)
+	operation, err := doc.GetOperationById("getPet")
+	require.NoError(t, err)
+	schema := operation.Operation.Responses.Codes.GetOrZero("200").
+		Content.GetOrZero("application/json").Schema.Schema()
+	require.NotNil(t, schema)
+	category := schema.Properties.GetOrZero("category").Schema()
+	require.NotNil(t, category)
+	assert.Equal(t, "The category of the pet.", category.Description)
+}
+
+func TestOAIOperation_GetServerURL(t *testing.T) {
+	api := `openapi: 3.1.0
+info:
+  title: Servers
+  version: 1.0.0
+servers:
+  - url: https://{env}.example.com/{version}
+    variables:
+      env:
+        default: api
+      version:
+        default: v1
+paths:
+  /pet:
+    servers:
+      - url: https://pets.example.com
+    get:
+      operationId: getPet
+      servers:
+        - url: https://get.example.com
+      responses:
+        "200":
+          description: successful operation
+    post:
+      operationId: addPet
+      responses:
+        "200":
+          description: successful operation
+  /store:
+    get:
+      operationId: getStore
+      responses:
+        "200":
+          description: successful operation
+`
+	doc, err := NewOAIDocument(
+		"specs/api.yaml",
+		WithOAILoader(arazzo.NewLoader(arazzo.WithDocuments(
+			map[string][]byte{"specs/api.yaml": []byte(api)},
+		))),
+	)
+	require.NoError(t, err)
+
+	serverURL, err := doc.GetServerURL()
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/v1", serverURL)
+
+	tests := []struct {
+		operationId string
+		expected    string
+	}{
+		{"getPet", "https://get.example.com"},
+		{"addPet", "https://pets.example.com"},
+		{"getStore", "https://api.example.com/v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.operationId, func(t *testing.T) {
+			operation, err := doc.GetOperationById(tt.operationId)
+			require.NoError(t, err)
+			serverURL, err := operation.GetServerURL()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, serverURL)
+		})
+	}
+}
+
+func TestResolveServerURL(t *testing.T) {
+	base := "https://petstore3.swagger.io/api/v3/openapi.json"
+	tests := []struct {
+		name     string
+		base     string
+		url      string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "absolute",
+			base:     base,
+			url:      "https://petstore.example.com/api/v3",
+			expected: "https://petstore.example.com/api/v3",
+		},
+		{
+			name:     "absolute path",
+			base:     base,
+			url:      "/api/v3",
+			expected: "https://petstore3.swagger.io/api/v3",
+		},
+		{
+			name:     "relative path",
+			base:     base,
+			url:      "v2",
+			expected: "https://petstore3.swagger.io/api/v3/v2",
+		},
+		{
+			name:     "no base",
+			url:      "/api/v3",
+			expected: "/api/v3",
+		},
+		{
+			name:    "undefined variable",
+			base:    base,
+			url:     "https://{env}.example.com",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverURL, err := ResolveServerURL(
+				tt.base,
+				&oai31.Server{URL: tt.url},
+			)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, serverURL)
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	arazzo "github.com/bragdonD/arazzo-go"
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// oaiReference is a file referenced by an OpenAPI document, directly
+// or through other referenced files.
+type oaiReference struct {
+	// URL is the location of the file, resolved against the location
+	// of the file referencing it.
+	URL string
+	// Data is the content of the file.
+	Data []byte
+}
+
+// oaiReferenceLoader loads the files referenced by an OpenAPI
+// document with an [arazzo.Loader], so that libopenapi never reads
+// the file system or the network by itself.
+type oaiReferenceLoader struct {
+	loader *arazzo.Loader
+	// allowLocal and allowRemote tell whether local and remote
+	// references are followed.
+	allowLocal  bool
+	allowRemote bool
+	// references lists the loaded files in the order they are first
+	// referenced.
+	references []oaiReference
+	// seen holds the document keys of the loaded files.
+	seen map[string]bool
+}
+
+// load loads the files referenced by data, the document located at
+// url, and then the files they reference in turn.
+func (r *oaiReferenceLoader) load(url string, data []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		// Files which are neither YAML nor JSON, such as examples,
+		// do not reference other files.
+		return nil
+	}
+	for _, ref := range collectReferences(&root, nil) {
+		file, _, _ := strings.Cut(ref.Value, "#")
+		if file == "" {
+			continue
+		}
+		fileURL, err := ResolveSourceURL(url, file)
+		if err != nil {
+			return fmt.Errorf("%q: %w", url, err)
+		}
+		remote := arazzo.IsRemoteFile(fileURL)
+		if remote && !r.allowRemote || !remote && !r.allowLocal {
+			continue
+		}
+		key := documentKey(fileURL)
+		if r.seen[key] {
+			continue
+		}
+		r.seen[key] = true
+
+		fileData, err := r.loader.LoadFile(fileURL)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to load %q referenced by %q: %w",
+				fileURL,
+				url,
+				err,
+			)
+		}
+		r.references = append(r.references, oaiReference{
+			URL:  fileURL,
+			Data: fileData,
+		})
+		if err := r.load(fileURL, fileData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectReferences appends the values of the $ref fields found
+// within node to refs, in document order.
+func collectReferences(
+	node *yaml.Node,
+	refs []*yaml.Node,
+) []*yaml.Node {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "$ref" && value.Kind == yaml.ScalarNode {
+				refs = append(refs, value)
+				continue
+			}
+			refs = collectReferences(value, refs)
+		}
+		return refs
+	}
+	for _, child := range node.Content {
+		refs = collectReferences(child, refs)
+	}
+	return refs
+}
+
+// newReferenceFS returns the libopenapi file system serving the local
+// files among references, along with the document located at source.
+// libopenapi identifies local files by absolute path, so the files are
+// served from their closest common directory.
+func newReferenceFS(
+	source string,
+	data []byte,
+	references []oaiReference,
+	logger *slog.Logger,
+) (*index.LocalFS, error) {
+	files := map[string][]byte{}
+	if !arazzo.IsRemoteFile(source) {
+		files[localPath(source)] = data
+	}
+	for _, reference := range references {
+		if !arazzo.IsRemoteFile(reference.URL) {
+			files[localPath(reference.URL)] = reference.Data
+		}
+	}
+
+	absFiles := map[string][]byte{}
+	dir := ""
+	for file, data := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		absFiles[abs] = data
+		if dir == "" {
+			dir = filepath.Dir(abs)
+		}
+		for !strings.HasPrefix(abs, dir+string(filepath.Separator)) &&
+			dir != filepath.Dir(dir) {
+			dir = filepath.Dir(dir)
+		}
+	}
+	if dir == "" {
+		dir, _ = filepath.Abs(".")
+	}
+	// libopenapi only indexes a single file if the base directory
+	// has an extension.
+	for filepath.Ext(dir) != "" && dir != filepath.Dir(dir) {
+		dir = filepath.Dir(dir)
+	}
+
+	fsys := memFS{}
+	for abs, data := range absFiles {
+		rel, err := filepath.Rel(dir, abs)
+		if err != nil {
+			return nil, err
+		}
+		fsys[filepath.ToSlash(rel)] = data
+	}
+	return index.NewLocalFSWithConfig(&index.LocalFSConfig{
+		BaseDirectory: dir,
+		DirFS:         fsys,
+		Logger:        logger,
+	})
+}
+
+// referenceURLHandler returns a libopenapi remote URL handler serving
+// the remote files among references, and fetching any other file with
+// loader.
+func referenceURLHandler(
+	loader *arazzo.Loader,
+	references []oaiReference,
+) func(string) (*http.Response, error) {
+	files := map[string][]byte{}
+	for _, reference := range references {
+		if arazzo.IsRemoteFile(reference.URL) {
+			files[reference.URL] = reference.Data
+		}
+	}
+	return func(rawURL string) (*http.Response, error) {
+		data, ok := files[rawURL]
+		if !ok {
+			var err error
+			data, err = loader.LoadFile(rawURL)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to load %q: %w",
+					rawURL,
+					err,
+				)
+			}
+		}
+		return &http.Response{
+			Status:        http.StatusText(http.StatusOK),
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{},
+			Body:          io.NopCloser(bytes.NewReader(data)),
+			ContentLength: int64(len(data)),
+		}, nil
+	}
+}
+
+// memFS is a read-only in-memory [fs.FS] holding files by slash
+// separated path. Directories are implied by the paths of the files.
+type memFS map[string][]byte
+
+// Open implements the fs.FS interface.
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		}
+	}
+	if data, ok := m[name]; ok {
+		return memFile{
+			Reader: bytes.NewReader(data),
+			info: memFileInfo{
+				name: path.Base(name),
+				size: int64(len(data)),
+			},
+		}, nil
+	}
+	if _, err := m.ReadDir(name); err != nil {
+		return nil, err
+	}
+	return memFile{
+		Reader: bytes.NewReader(nil),
+		info:   memFileInfo{name: path.Base(name), dir: true},
+	}, nil
+}
+
+// ReadDir implements the fs.ReadDirFS interface.
+func (m memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	children := map[string]memFileInfo{}
+	for file, data := range m {
+		rest, ok := strings.CutPrefix(file, prefix)
+		if !ok {
+			continue
+		}
+		child, _, dir := strings.Cut(rest, "/")
+		children[child] = memFileInfo{
+			name: child,
+			size: int64(len(data)),
+			dir:  dir,
+		}
+	}
+	if len(children) == 0 && name != "." {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fs.ErrNotExist,
+		}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, child := range slices.Sorted(maps.Keys(children)) {
+		entries = append(
+			entries,
+			fs.FileInfoToDirEntry(children[child]),
+		)
+	}
+	return entries, nil
+}
+
+// memFile is a file or a directory of a memFS.
+type memFile struct {
+	*bytes.Reader
+	info memFileInfo
+}
+
+// Stat implements the fs.File interface.
+func (f memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+// Close implements the fs.File interface.
+func (f memFile) Close() error { return nil }
+
+// memFileInfo describes a file or a directory of a memFS.
+type memFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+// Name implements the fs.FileInfo interface.
+func (i memFileInfo) Name() string { return i.name }
+
+// Size implements the fs.FileInfo interface.
+func (i memFileInfo) Size() int64 { return i.size }
+
+// Mode implements the fs.FileInfo interface.
+func (i memFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+// ModTime implements the fs.FileInfo interface.
+func (i memFileInfo) ModTime() time.Time { return time.Time{} }
+
+// IsDir implements the fs.FileInfo interface.
+func (i memFileInfo) IsDir() bool { return i.dir }
+
+// Sys implements the fs.FileInfo interface.
+func (i memFileInfo) Sys() any { return nil }
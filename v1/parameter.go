@@ -31,3 +31,12 @@ func (p *Parameter) GetName() string {
 func (p *Parameter) GetValue() *Value {
 	return p.value
 }
+
+// key returns the identity of the parameter. A unique parameter is
+// defined by the combination of its name and location.
+func (p *Parameter) key() string {
+	if p.model.In == nil {
+		return p.model.Name
+	}
+	return string(*p.model.In) + ":" + p.model.Name
+}
@@ -1,36 +1,274 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 
+	"github.com/antchfx/xmlquery"
 	"github.com/bragdonD/arazzo-go/v1/models"
 	jsonpointergo "github.com/bragdond/jsonpointer-go"
 )
 
+// jsonPointerAppendToken is the reference token designating the
+// nonexistent element after the last element of an array.
+const jsonPointerAppendToken = "-"
+
 // PayloadReplacement is a struct that represents an Arazzo
 // specification 1.0.X payload replacement object.
 //
 // A payload replacement object describes a location within a payload
 // (e.g., a request body) and a value to set within the location.
 type PayloadReplacement struct {
-	model  *models.PayloadReplacement
+	model *models.PayloadReplacement
+	// target is the target as a JSON pointer, nil if the target is
+	// not a valid JSON pointer.
 	target *jsonpointergo.JSONPointer
-	value  *Value
+	// tokens are the decoded reference tokens of the target.
+	tokens []string
+	// xpath is the target as an XPath expression, nil if the target
+	// is not a valid XPath expression.
+	xpath *XPath
+	value *Value
+}
+
+// PayloadReplacementError is returned when the target of a payload
+// replacement cannot be set within a payload.
+type PayloadReplacementError struct {
+	// Target is the target of the payload replacement.
+	Target string
+	// Token is the reference token of a JSON pointer target which
+	// could not be set, empty for XPath targets.
+	Token string
+	// Reason describes why the target could not be set.
+	Reason string
+}
+
+// Error returns a formatted error message indicating the target, the
+// reference token and the reason it could not be set.
+func (e *PayloadReplacementError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf(
+			"arazzo-go: unable to set payload replacement target %q: %s",
+			e.Target,
+			e.Reason,
+		)
+	}
+	return fmt.Sprintf(
+		"arazzo-go: unable to set payload replacement target %q at token %q: %s",
+		e.Target,
+		e.Token,
+		e.Reason,
+	)
+}
+
+// NewPayloadReplacement creates a new PayloadReplacement from its
+// model. The target is a JSON pointer for JSON based payloads and an
+// XPath expression for XML payloads. Which one applies is only known
+// once the payload is rendered, so the target is parsed as both.
+func NewPayloadReplacement(
+	pr *models.PayloadReplacement,
+) (*PayloadReplacement, error) {
+	replacement := &PayloadReplacement{
+		model: pr,
+		value: NewValue(pr.Value),
+	}
+	pointer, pointerErr := jsonpointergo.NewJSONPointer(pr.Target)
+	if pointerErr == nil {
+		replacement.target = pointer
+		replacement.tokens, pointerErr = jsonPointerTokens(pr.Target)
+	}
+	var xpathErr error
+	replacement.xpath, xpathErr = NewXPath(pr.Target, "")
+	if pointerErr != nil && xpathErr != nil {
+		return nil, errors.New(
+			"target is neither a json pointer nor an xpath expression",
+		)
+	}
+	return replacement, nil
+}
+
+func (p *PayloadReplacement) GetModel() *models.PayloadReplacement {
+	return p.model
+}
+
+// GetTarget returns the target as a JSON pointer, or nil if the
+// target is an XPath expression.
+func (p *PayloadReplacement) GetTarget() *jsonpointergo.JSONPointer {
+	return p.target
 }
 
-func NewPayloadReplacement(pr *models.PayloadReplacement) (*PayloadReplacement, error) {
-	pointer, err := jsonpointergo.NewJSONPointer(pr.Target)
+// ApplyToPayload evaluates the value of the replacement against ctx
+// and sets it at the target location within payload.
+//
+// If payload is an [XMLDocument], the target is an XPath expression
+// and the value replaces the content of every selected element or
+// attribute. Otherwise payload is a decoded JSON document and the
+// target a JSON pointer: missing objects and arrays along the target
+// are created and the "-" reference token appends to an array.
+//
+// Payloads are modified in place. The updated payload is returned, as
+// replacing the whole document or appending to an array produces a
+// new value.
+func (p *PayloadReplacement) ApplyToPayload(
+	payload any,
+	ctx *EvalContext,
+) (any, error) {
+	value, err := p.value.Evaluate(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new json"+
-			" pointer: %v", err)
+		return nil, fmt.Errorf(
+			"payload replacement %q: %w",
+			p.model.Target,
+			err,
+		)
+	}
+	if doc, ok := payload.(*XMLDocument); ok {
+		return doc, p.setXML(doc, value)
 	}
-	return &PayloadReplacement{
-		model:  pr,
-		target: pointer,
-		value:  NewValue(pr.Value),
-	}, nil
+	if p.target == nil {
+		return nil, &PayloadReplacementError{
+			Target: p.model.Target,
+			Reason: "the target of a json payload must be a json pointer",
+		}
+	}
+	return p.set(payload, p.tokens, value)
 }
 
-func (p *PayloadReplacement) ApplyToPayload(payload any, spec *Spec) error {
+// setXML sets value as the content of every node of doc selected by
+// the XPath target.
+func (p *PayloadReplacement) setXML(
+	doc *XMLDocument,
+	value any,
+) error {
+	if p.xpath == nil {
+		return &PayloadReplacementError{
+			Target: p.model.Target,
+			Reason: "the target of an xml payload must be an xpath expression",
+		}
+	}
+	result, err := p.xpath.Evaluate(doc)
+	if err != nil {
+		return err
+	}
+	nodes, ok := result.([]*xmlquery.Node)
+	if !ok || len(nodes) == 0 {
+		return &PayloadReplacementError{
+			Target: p.model.Target,
+			Reason: "no node is selected",
+		}
+	}
+	str, err := FormatValue(value)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		switch node.Type {
+		case xmlquery.AttributeNode:
+			node.Parent.SetAttr(node.Data, str)
+		case xmlquery.ElementNode:
+			node.FirstChild, node.LastChild = nil, nil
+			xmlquery.AddChild(node, &xmlquery.Node{
+				Type: xmlquery.TextNode,
+				Data: str,
+			})
+		case xmlquery.TextNode, xmlquery.CharDataNode:
+			node.Data = str
+		default:
+			return &PayloadReplacementError{
+				Target: p.model.Target,
+				Reason: "only elements, attributes and texts can be set",
+			}
+		}
+	}
+	return nil
+}
+
+// set sets value at the location referenced by tokens within
+// document and returns the updated document.
+func (p *PayloadReplacement) set(
+	document any,
+	tokens []string,
+	value any,
+) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	token, rest := tokens[0], tokens[1:]
+	if document == nil {
+		document = newJSONContainer(token)
+	}
 
+	switch v := document.(type) {
+	case map[string]any:
+		child, err := p.set(v[token], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		v[token] = child
+		return v, nil
+	case []any:
+		index := len(v)
+		if token != jsonPointerAppendToken {
+			var err error
+			index, err = strconv.Atoi(token)
+			// An index equal to the length of the array appends to
+			// it, so that missing arrays can be filled in order.
+			if err != nil || index < 0 || index > len(v) {
+				return nil, &PayloadReplacementError{
+					Target: p.model.Target,
+					Token:  token,
+					Reason: fmt.Sprintf(
+						"invalid index for an array of length %d",
+						len(v),
+					),
+				}
+			}
+		}
+		if index == len(v) {
+			v = append(v, nil)
+		}
+		child, err := p.set(v[index], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		v[index] = child
+		return v, nil
+	}
+	return nil, &PayloadReplacementError{
+		Target: p.model.Target,
+		Token:  token,
+		Reason: fmt.Sprintf("cannot traverse a %T", document),
+	}
+}
+
+// newJSONContainer returns the empty container referenced by token:
+// an array if token is an array index, an object otherwise.
+func newJSONContainer(token string) any {
+	if token == jsonPointerAppendToken {
+		return []any{}
+	}
+	if index, err := strconv.Atoi(token); err == nil && index >= 0 {
+		return []any{}
+	}
+	return map[string]any{}
+}
+
+// deepCopyJSON returns a copy of a decoded JSON document which shares
+// no object or array with value.
+func deepCopyJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for key, child := range v {
+			copied[key] = deepCopyJSON(child)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(v))
+		for i, child := range v {
+			copied[i] = deepCopyJSON(child)
+		}
+		return copied
+	}
+	return value
 }
@@ -0,0 +1,289 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/bragdonD/arazzo-go/internal/testutil"
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadReplacement_ApplyToPayload(t *testing.T) {
+	ctx := &EvalContext{
+		Inputs: map[string]any{"username": "john", "age": 4},
+	}
+	tests := []struct {
+		target   string
+		value    any
+		payload  any
+		expected any
+	}{
+		{"", "$inputs.username", map[string]any{}, "john"},
+		{
+			"/name",
+			"$inputs.username",
+			map[string]any{"name": "", "id": 1.0},
+			map[string]any{"name": "john", "id": 1.0},
+		},
+		{
+			"/owner/age",
+			"$inputs.age",
+			map[string]any{},
+			map[string]any{"owner": map[string]any{"age": 4}},
+		},
+		{
+			"/tags/-",
+			"new",
+			map[string]any{"tags": []any{"old"}},
+			map[string]any{"tags": []any{"old", "new"}},
+		},
+		{
+			"/photos/0/url",
+			"http://example.com/rex.png",
+			nil,
+			map[string]any{"photos": []any{
+				map[string]any{"url": "http://example.com/rex.png"},
+			}},
+		},
+		{
+			"/1",
+			map[string]any{"id": 2.0},
+			[]any{"a", "b"},
+			[]any{"a", map[string]any{"id": 2.0}},
+		},
+		{
+			"/a~1b/c~0d",
+			true,
+			map[string]any{},
+			map[string]any{"a/b": map[string]any{"c~d": true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			replacement, err := NewPayloadReplacement(
+				&models.PayloadReplacement{
+					Target: tt.target,
+					Value:  tt.value,
+				},
+			)
+			require.NoError(t, err)
+
+			payload, err := replacement.ApplyToPayload(
+				tt.payload,
+				ctx,
+			)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, payload)
+		})
+	}
+}
+
+func TestPayloadReplacement_ApplyToPayload_Invalid(t *testing.T) {
+	tests := []struct {
+		target  string
+		payload any
+		token   string
+		reason  string
+	}{
+		{
+			"/name/first",
+			map[string]any{"name": "rex"},
+			"first",
+			"cannot traverse a string",
+		},
+		{
+			"/id/-",
+			map[string]any{"id": 1.0},
+			"-",
+			"cannot traverse a float64",
+		},
+		{
+			"/tags/3",
+			map[string]any{"tags": []any{"a"}},
+			"3",
+			"invalid index for an array of length 1",
+		},
+		{
+			"/tags/first",
+			map[string]any{"tags": []any{}},
+			"first",
+			"invalid index for an array of length 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			replacement, err := NewPayloadReplacement(
+				&models.PayloadReplacement{
+					Target: tt.target,
+					Value:  "x",
+				},
+			)
+			require.NoError(t, err)
+
+			_, err = replacement.ApplyToPayload(tt.payload, nil)
+			var replacementErr *PayloadReplacementError
+			require.ErrorAs(t, err, &replacementErr)
+			assert.Equal(t, &PayloadReplacementError{
+				Target: tt.target,
+				Token:  tt.token,
+				Reason: tt.reason,
+			}, replacementErr)
+		})
+	}
+}
+
+func TestRequestBody_Render(t *testing.T) {
+	payload := map[string]any{
+		"name": "",
+		"tags": []any{"dog"},
+	}
+	requestBody, err := NewRequestBody(&models.RequestBody{
+		Payload: payload,
+		Replacements: []models.PayloadReplacement{
+			{Target: "/name", Value: "$inputs.name"},
+			{Target: "/tags/-", Value: "friendly"},
+			{Target: "/tags/0", Value: "cat"},
+		},
+	})
+	require.NoError(t, err)
+
+	rendered, err := requestBody.Render(&EvalContext{
+		Inputs: map[string]any{"name": "Tom"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name": "Tom",
+		"tags": []any{"cat", "friendly"},
+	}, rendered)
+	assert.Equal(t, map[string]any{
+		"name": "",
+		"tags": []any{"dog"},
+	}, payload)
+
+	requestBody, err = NewRequestBody(&models.RequestBody{
+		Payload: `{"name": ""}`,
+		Replacements: []models.PayloadReplacement{
+			{Target: "/name", Value: "Rex"},
+		},
+	})
+	require.NoError(t, err)
+	rendered, err = requestBody.Render(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "Rex"}, rendered)
+}
+
+func TestNewRequestBody_Invalid(t *testing.T) {
+	_, err := NewRequestBody(&models.RequestBody{
+		Replacements: []models.PayloadReplacement{{Target: "pets["}},
+	})
+	assert.EqualError(
+		t,
+		err,
+		`invalid payload replacement "pets[": target is neither a `+
+			`json pointer nor an xpath expression`,
+	)
+}
+
+func TestRequestBody_Render_XML(t *testing.T) {
+	requestBody, err := NewRequestBody(&models.RequestBody{
+		ContentType: testutil.Ptr("application/xml"),
+		Payload:     `<pet id="0"><name/><tag>a</tag><tag>b</tag></pet>`,
+		Replacements: []models.PayloadReplacement{
+			{Target: "/pet/@id", Value: "$inputs.id"},
+			{Target: "/pet/name", Value: "$inputs.name"},
+			{Target: "//tag", Value: "dog"},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := &EvalContext{
+		Inputs: map[string]any{"id": 7, "name": "Rex"},
+	}
+	rendered, err := requestBody.Render(ctx)
+	require.NoError(t, err)
+	require.IsType(t, &XMLDocument{}, rendered)
+	assert.Equal(
+		t,
+		`<?xml version="1.0"?><pet id="7"><name>Rex</name>`+
+			`<tag>dog</tag><tag>dog</tag></pet>`,
+		rendered.(*XMLDocument).String(),
+	)
+
+	data, contentType, err := requestBody.Encode(
+		"application/json",
+		ctx,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "application/xml", contentType)
+	assert.Equal(
+		t,
+		`<?xml version="1.0"?><pet id="7"><name>Rex</name>`+
+			`<tag>dog</tag><tag>dog</tag></pet>`,
+		string(data),
+	)
+}
+
+func TestRequestBody_Render_XML_Invalid(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{
+			"//owner",
+			`arazzo-go: unable to set payload replacement target ` +
+				`"//owner": no node is selected`,
+		},
+		{
+			"count(//name)",
+			`arazzo-go: unable to set payload replacement target ` +
+				`"count(//name)": no node is selected`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			requestBody, err := NewRequestBody(&models.RequestBody{
+				ContentType: testutil.Ptr("text/xml"),
+				Payload:     `<pet><name>rex</name></pet>`,
+				Replacements: []models.PayloadReplacement{
+					{Target: tt.target, Value: "tom"},
+				},
+			})
+			require.NoError(t, err)
+
+			_, err = requestBody.Render(nil)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestRequestBody_Render_Form(t *testing.T) {
+	requestBody, err := NewRequestBody(&models.RequestBody{
+		ContentType: testutil.Ptr(
+			"application/x-www-form-urlencoded",
+		),
+		Payload: "username=&remember=true",
+		Replacements: []models.PayloadReplacement{
+			{Target: "/username", Value: "$inputs.username"},
+			{Target: "/password", Value: "$inputs.password"},
+		},
+	})
+	require.NoError(t, err)
+
+	data, contentType, err := requestBody.Encode("", &EvalContext{
+		Inputs: map[string]any{
+			"username": "john",
+			"password": "p&ss",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-www-form-urlencoded", contentType)
+	assert.Equal(
+		t,
+		"password=p%26ss&remember=true&username=john",
+		string(data),
+	)
+}
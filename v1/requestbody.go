@@ -1,6 +1,10 @@
 package v1
 
-import "github.com/bragdonD/arazzo-go/v1/models"
+import (
+	"fmt"
+
+	"github.com/bragdonD/arazzo-go/v1/models"
+)
 
 // RequestBody is a struct that represents an Arazzo specification
 // 1.0.X request body object.
@@ -9,6 +13,109 @@ import "github.com/bragdonD/arazzo-go/v1/models"
 // passed by a step to an operation.
 type RequestBody struct {
 	model        *models.RequestBody
-	payload      string
+	payload      any
 	replacements []*PayloadReplacement
 }
+
+func NewRequestBody(model *models.RequestBody) (*RequestBody, error) {
+	requestBody := &RequestBody{
+		model:        model,
+		payload:      model.Payload,
+		replacements: []*PayloadReplacement{},
+	}
+
+	for i := range model.Replacements {
+		replacement, err := NewPayloadReplacement(
+			&model.Replacements[i],
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid payload replacement %q: %w",
+				model.Replacements[i].Target,
+				err,
+			)
+		}
+		requestBody.replacements = append(
+			requestBody.replacements,
+			replacement,
+		)
+	}
+
+	return requestBody, nil
+}
+
+func (r *RequestBody) GetModel() *models.RequestBody {
+	return r.model
+}
+
+// GetContentType returns the Content-Type declared by the request
+// body, or an empty string if it is omitted.
+func (r *RequestBody) GetContentType() string {
+	if r.model.ContentType == nil {
+		return ""
+	}
+	return *r.model.ContentType
+}
+
+func (r *RequestBody) GetPayload() any {
+	return r.payload
+}
+
+func (r *RequestBody) GetReplacements() []*PayloadReplacement {
+	return r.replacements
+}
+
+// Render returns the payload with every replacement applied in order,
+// their values being evaluated against ctx. The replacements are
+// applied to a copy of the payload which is left untouched.
+//
+// A string payload with replacements is first decoded according to
+// the Content-Type of the request body: replacements are applied to
+// JSON and URL encoded form payloads using JSON pointers and to XML
+// payloads, which render to an [XMLDocument], using XPath
+// expressions.
+func (r *RequestBody) Render(ctx *EvalContext) (any, error) {
+	return r.render(r.GetContentType(), ctx)
+}
+
+// Encode renders the payload and serializes it with [EncodeBody]. The
+// Content-Type declared by the request body takes precedence over
+// contentType. It returns the body and the Content-Type it must be
+// sent with.
+func (r *RequestBody) Encode(
+	contentType string,
+	ctx *EvalContext,
+) ([]byte, string, error) {
+	if declared := r.GetContentType(); declared != "" {
+		contentType = declared
+	}
+	payload, err := r.render(contentType, ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return EncodeBody(contentType, payload)
+}
+
+// render renders the payload as a body of the given Content-Type.
+func (r *RequestBody) render(
+	contentType string,
+	ctx *EvalContext,
+) (any, error) {
+	payload := deepCopyJSON(r.payload)
+	if str, ok := payload.(string); ok && len(r.replacements) > 0 {
+		var err error
+		payload, err = decodePayload(contentType, str)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	for _, replacement := range r.replacements {
+		payload, err = replacement.ApplyToPayload(payload, ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return payload, nil
+}
@@ -0,0 +1,185 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	v1 "github.com/bragdonD/arazzo-go/v1"
+	"github.com/bragdonD/arazzo-go/v1/models"
+)
+
+// defaultContentType is the Content-Type used for request bodies when
+// neither the step nor the operation declare one.
+const defaultContentType = "application/json"
+
+// newRequest builds the HTTP request described by step from its
+// resolved operation, parameters and request body. Runtime
+// expressions are evaluated against evalCtx. It also returns the
+// request as seen by the $request runtime expressions.
+func (r *Runner) newRequest(
+	ctx context.Context,
+	step *v1.Step,
+	evalCtx *v1.EvalContext,
+) (*http.Request, *v1.Request, error) {
+	operation := step.GetOperation()
+	if operation == nil {
+		return nil, nil, fmt.Errorf(
+			"step does not reference a resolvable operation",
+		)
+	}
+
+	path := operation.Path
+	pathParams := map[string]string{}
+	query := url.Values{}
+	header := http.Header{}
+	cookies := []*http.Cookie{}
+	for _, param := range step.GetMergedParameters() {
+		if param.GetModel().In == nil {
+			return nil, nil, fmt.Errorf(
+				"parameter %q: the location must be specified",
+				param.GetName(),
+			)
+		}
+		value, err := param.GetValue().Evaluate(evalCtx)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"parameter %q: %w",
+				param.GetName(),
+				err,
+			)
+		}
+		str, err := v1.FormatValue(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"parameter %q: %w",
+				param.GetName(),
+				err,
+			)
+		}
+		switch param.GetLocation() {
+		case models.ParameterLocationPath:
+			pathParams[param.GetName()] = str
+			path = strings.ReplaceAll(
+				path,
+				"{"+param.GetName()+"}",
+				url.PathEscape(str),
+			)
+		case models.ParameterLocationQuery:
+			query.Add(param.GetName(), str)
+		case models.ParameterLocationHeader:
+			header.Add(param.GetName(), str)
+		case models.ParameterLocationCookie:
+			cookies = append(cookies, &http.Cookie{
+				Name:  param.GetName(),
+				Value: str,
+			})
+		default:
+			return nil, nil, fmt.Errorf(
+				"parameter %q: unknown location %q",
+				param.GetName(),
+				param.GetLocation(),
+			)
+		}
+	}
+
+	serverURL, err := r.serverURL(operation)
+	if err != nil {
+		return nil, nil, err
+	}
+	target := strings.TrimSuffix(serverURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	data, contentType, err := r.newRequestBody(step, evalCtx)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"failed to build request body: %w",
+			err,
+		)
+	}
+
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		string(operation.Method),
+		target,
+		body,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"failed to create request: %w",
+			err,
+		)
+	}
+	req.Header = header
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	decoded, err := v1.DecodeBody(contentType, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, &v1.Request{
+		URL:        req.URL,
+		Method:     req.Method,
+		Header:     req.Header,
+		PathParams: pathParams,
+		Body:       decoded,
+	}, nil
+}
+
+// newRequestBody serializes the request body of step. It returns nil
+// data if the step does not define a request body.
+func (r *Runner) newRequestBody(
+	step *v1.Step,
+	evalCtx *v1.EvalContext,
+) ([]byte, string, error) {
+	requestBody := step.GetRequestBody()
+	if requestBody == nil {
+		return nil, "", nil
+	}
+	return requestBody.Encode(
+		operationContentType(step.GetOperation()),
+		evalCtx,
+	)
+}
+
+// serverURL returns the URL of the server operation is sent to,
+// unless overridden for its source description with
+// [WithServerURL].
+func (r *Runner) serverURL(
+	operation *v1.OAIOperation,
+) (string, error) {
+	name := operation.Document.GetName()
+	if serverURL, ok := r.serverURLs[name]; ok {
+		return serverURL, nil
+	}
+	return operation.GetServerURL()
+}
+
+// operationContentType returns the first Content-Type accepted by the
+// request body of operation, or [defaultContentType] if the operation
+// does not describe its request body.
+func operationContentType(operation *v1.OAIOperation) string {
+	requestBody := operation.Operation.RequestBody
+	if requestBody == nil || requestBody.Content == nil {
+		return defaultContentType
+	}
+	for contentType := range requestBody.Content.KeysFromOldest() {
+		return contentType
+	}
+	return defaultContentType
+}
@@ -0,0 +1,810 @@
+// Package runner executes the workflows of an Arazzo document against
+// the APIs described by its source descriptions.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/bragdonD/arazzo-go/v1"
+	"github.com/bragdonD/arazzo-go/v1/models"
+)
+
+// DefaultMaxStepExecutions is the default maximum number of step
+// executions of a single run.
+const DefaultMaxStepExecutions = 1000
+
+// ErrMaxStepExecutions is returned when a run executes more steps
+// than allowed, which usually denotes a loop between goto actions.
+var ErrMaxStepExecutions = errors.New(
+	"maximum number of step executions exceeded",
+)
+
+// Runner executes the workflows of an Arazzo [v1.Spec]. Each step
+// calling an API operation is turned into an HTTP request which is
+// sent through the configured [http.RoundTripper].
+type Runner struct {
+	spec              *v1.Spec
+	client            *http.Client
+	serverURLs        map[string]string
+	maxStepExecutions int
+	parallelism       int
+	clock             Clock
+}
+
+// Clock is the source of time of a Runner. It is used to wait before
+// retrying a failed step.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep pauses for the duration d, or until ctx is done in which
+	// case the error of ctx is returned.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// realClock is the Clock reading the system time.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunnerOption defines a functional option for configuring Runner.
+type RunnerOption func(*Runner)
+
+// WithTransport sets the [http.RoundTripper] used to send the
+// requests built from the workflow steps.
+func WithTransport(transport http.RoundTripper) RunnerOption {
+	return func(r *Runner) {
+		r.client.Transport = transport
+	}
+}
+
+// WithServerURL overrides the server URL of the OpenAPI source
+// description named sourceName. By default, the first server declared
+// by the OpenAPI document is used.
+func WithServerURL(sourceName string, serverURL string) RunnerOption {
+	return func(r *Runner) {
+		r.serverURLs[sourceName] = serverURL
+	}
+}
+
+// WithMaxStepExecutions sets the maximum number of steps a run may
+// execute, including the steps of the workflows it transfers control
+// to, before failing with [ErrMaxStepExecutions]. It defaults to
+// [DefaultMaxStepExecutions].
+func WithMaxStepExecutions(max int) RunnerOption {
+	return func(r *Runner) {
+		r.maxStepExecutions = max
+	}
+}
+
+// WithParallelism sets the maximum number of workflow dependencies
+// executed concurrently. Dependencies are executed one after the
+// other by default.
+func WithParallelism(parallelism int) RunnerOption {
+	return func(r *Runner) {
+		r.parallelism = parallelism
+	}
+}
+
+// WithClock sets the Clock used to wait before retrying a failed
+// step. It defaults to the system clock.
+func WithClock(clock Clock) RunnerOption {
+	return func(r *Runner) {
+		r.clock = clock
+	}
+}
+
+// NewRunner creates a new Runner for spec with optional
+// configurations.
+func NewRunner(spec *v1.Spec, opts ...RunnerOption) *Runner {
+	r := &Runner{
+		spec:              spec,
+		client:            &http.Client{Timeout: 30 * time.Second},
+		serverURLs:        map[string]string{},
+		maxStepExecutions: DefaultMaxStepExecutions,
+		parallelism:       1,
+		clock:             realClock{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// execution holds the state of a single workflow execution.
+type execution struct {
+	workflow  *v1.Workflow
+	inputs    map[string]any
+	steps     map[string]map[string]any
+	workflows map[string]*v1.WorkflowContext
+	// stepExecutions counts the steps executed by the run, it is
+	// shared with every workflow the run executes.
+	stepExecutions *atomic.Int64
+}
+
+// transition describes where a workflow execution continues after a
+// step. A nil transition continues with the next step.
+type transition struct {
+	// end ends the workflow.
+	end bool
+	// stepId is the step of the workflow to continue with.
+	stepId string
+	// workflowId is the workflow to transfer control to.
+	workflowId string
+}
+
+// stepFailure is returned by runStep when the step fails, in which
+// case the failure actions of the step apply. A step fails when its
+// request cannot be sent, when a success criterion is not satisfied
+// or, if it declares no success criteria, when the server responds
+// with a status code other than 2xx.
+type stepFailure struct {
+	err error
+	// evalCtx is the context the failure actions are evaluated in.
+	evalCtx *v1.EvalContext
+}
+
+func (e *stepFailure) Error() string {
+	return e.err.Error()
+}
+
+func (e *stepFailure) Unwrap() error {
+	return e.err
+}
+
+// context returns the evaluation context of the execution for the
+// given exchange. Both req and resp may be nil. Expressions are
+// resolved against the document the workflow belongs to.
+func (r *Runner) context(
+	exec *execution,
+	req *v1.Request,
+	resp *v1.Response,
+) *v1.EvalContext {
+	return &v1.EvalContext{
+		Spec:      exec.workflow.GetParent(),
+		Request:   req,
+		Response:  resp,
+		Inputs:    exec.inputs,
+		Steps:     exec.steps,
+		Workflows: exec.workflows,
+	}
+}
+
+// Run executes the workflow identified by workflowId with the given
+// inputs, which are first validated against the input schema of the
+// workflow. The workflows it depends on are executed beforehand, each
+// one with the inputs its own input schema declares, as selected by
+// [v1.Workflow.FilterInputs], and validated against that schema.
+// Steps are executed in the order they are declared unless a success
+// or failure action ends the workflow, retries the step or transfers
+// control elsewhere. A step declaring no success criteria succeeds if
+// the server responds with a 2xx status code. The workflow outputs
+// are returned once the workflow has completed.
+func (r *Runner) Run(
+	ctx context.Context,
+	workflowId string,
+	inputs map[string]any,
+) (map[string]any, error) {
+	workflow, ok := r.spec.GetWorkflow(workflowId)
+	if !ok {
+		return nil, fmt.Errorf("workflow %q not found", workflowId)
+	}
+	if inputs == nil {
+		inputs = map[string]any{}
+	}
+
+	var stepExecutions atomic.Int64
+	return r.runWorkflow(ctx, &execution{
+		workflow:       workflow,
+		inputs:         inputs,
+		steps:          map[string]map[string]any{},
+		workflows:      map[string]*v1.WorkflowContext{},
+		stepExecutions: &stepExecutions,
+	})
+}
+
+// runWorkflow executes the steps of the workflow of exec and returns
+// its outputs.
+func (r *Runner) runWorkflow(
+	ctx context.Context,
+	exec *execution,
+) (map[string]any, error) {
+	inputs, err := exec.workflow.ValidateInputs(exec.inputs)
+	if err != nil {
+		return nil, err
+	}
+	exec.inputs = inputs
+	if err := r.runDependencies(ctx, exec); err != nil {
+		return nil, err
+	}
+	workflowId := exec.workflow.GetId()
+	exec.workflows[workflowId] = &v1.WorkflowContext{
+		Inputs: exec.inputs,
+	}
+
+	steps := exec.workflow.GetSteps()
+	for i := 0; i < len(steps); {
+		step := steps[i]
+		next, err := r.executeStep(ctx, exec, step)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"workflow %q: step %q: %w",
+				workflowId,
+				step.GetId(),
+				err,
+			)
+		}
+
+		switch {
+		case next == nil:
+			i++
+		case next.end:
+			i = len(steps)
+		case next.stepId != "":
+			i = stepIndex(steps, next.stepId)
+		default:
+			return r.transfer(ctx, exec, next.workflowId)
+		}
+	}
+
+	return r.completeWorkflow(exec)
+}
+
+// runDependencies executes the workflows the workflow of exec depends
+// on, directly or not, which have not been completed by the run yet.
+// A workflow is executed once all its own dependencies are completed,
+// up to r.parallelism workflows being executed concurrently.
+func (r *Runner) runDependencies(
+	ctx context.Context,
+	exec *execution,
+) error {
+	pending := []*v1.Workflow{}
+	for _, workflow := range dependencyOrder(exec.workflow) {
+		if !completed(exec.workflows, workflow.GetId()) {
+			pending = append(pending, workflow)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var mu sync.Mutex
+	if r.parallelism <= 1 {
+		for _, workflow := range pending {
+			if err := r.runDependency(ctx, exec, workflow, &mu); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// done is closed once the workflow has been executed, whether it
+	// succeeded or not.
+	done := map[*v1.Workflow]chan struct{}{}
+	for _, workflow := range pending {
+		done[workflow] = make(chan struct{})
+	}
+	sem := make(chan struct{}, r.parallelism)
+	var wg sync.WaitGroup
+	var firstErr error
+	for _, workflow := range pending {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[workflow])
+			for _, dependency := range workflow.GetDependencies() {
+				if ch, ok := done[dependency]; ok {
+					<-ch
+				}
+			}
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			// A failed dependency cancels the workflows which have not
+			// started yet.
+			if ctx.Err() != nil {
+				return
+			}
+			err := r.runDependency(ctx, exec, workflow, &mu)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// runDependency executes workflow, a dependency of the workflow of
+// exec, with the inputs of exec it declares, and records its inputs
+// and outputs within exec. mu guards the workflows of exec.
+func (r *Runner) runDependency(
+	ctx context.Context,
+	exec *execution,
+	workflow *v1.Workflow,
+	mu *sync.Mutex,
+) error {
+	mu.Lock()
+	workflows := maps.Clone(exec.workflows)
+	mu.Unlock()
+
+	_, err := r.runWorkflow(ctx, &execution{
+		workflow:       workflow,
+		inputs:         workflow.FilterInputs(exec.inputs),
+		steps:          map[string]map[string]any{},
+		workflows:      workflows,
+		stepExecutions: exec.stepExecutions,
+	})
+	if err != nil {
+		return fmt.Errorf(
+			"workflow %q: dependency: %w",
+			exec.workflow.GetId(),
+			err,
+		)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	exec.workflows[workflow.GetId()] = workflows[workflow.GetId()]
+	return nil
+}
+
+// dependencyOrder returns the workflows workflow depends on, directly
+// or not, each one being listed after its own dependencies. Cycles
+// are rejected when the spec is loaded.
+func dependencyOrder(workflow *v1.Workflow) []*v1.Workflow {
+	order := []*v1.Workflow{}
+	seen := map[*v1.Workflow]bool{}
+	var visit func(workflow *v1.Workflow)
+	visit = func(workflow *v1.Workflow) {
+		for _, dependency := range workflow.GetDependencies() {
+			if seen[dependency] {
+				continue
+			}
+			seen[dependency] = true
+			visit(dependency)
+			order = append(order, dependency)
+		}
+	}
+	visit(workflow)
+	return order
+}
+
+// completed reports whether the workflow identified by workflowId
+// has been completed, its outputs being recorded.
+func completed(
+	workflows map[string]*v1.WorkflowContext,
+	workflowId string,
+) bool {
+	workflow, ok := workflows[workflowId]
+	return ok && workflow.Outputs != nil
+}
+
+// executeStep runs step within exec and returns the transition to
+// take. A failed step, see stepFailure, is handled by its failure
+// actions, which may retry it.
+func (r *Runner) executeStep(
+	ctx context.Context,
+	exec *execution,
+	step *v1.Step,
+) (*transition, error) {
+	// retries counts the retries of each retry action, the limit
+	// of an action being independent from the others.
+	retries := map[*v1.FailureAction]int{}
+	for {
+		outputs, evalCtx, err := r.countedRunStep(ctx, exec, step)
+		if err == nil {
+			exec.steps[step.GetId()] = outputs
+			return successTransition(step, evalCtx)
+		}
+		var failure *stepFailure
+		if !errors.As(err, &failure) {
+			return nil, err
+		}
+
+		action, err := failureAction(step, failure.evalCtx, retries)
+		if err != nil {
+			return nil, err
+		}
+		if action == nil {
+			return nil, failure
+		}
+		switch action.GetType() {
+		case models.FailureActionTypeEnd:
+			return &transition{end: true}, nil
+		case models.FailureActionTypeGoto:
+			return &transition{
+				stepId:     action.GetStepId(),
+				workflowId: action.GetWorkflowId(),
+			}, nil
+		}
+
+		retries[action]++
+		if err := r.prepareRetry(ctx, exec, action); err != nil {
+			return nil, fmt.Errorf(
+				"failure action %q: %w",
+				action.GetName(),
+				err,
+			)
+		}
+		if err := r.clock.Sleep(ctx, action.GetRetryDelay()); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// countedRunStep runs step once the number of step executions of the
+// run has been checked against its maximum.
+func (r *Runner) countedRunStep(
+	ctx context.Context,
+	exec *execution,
+	step *v1.Step,
+) (map[string]any, *v1.EvalContext, error) {
+	if exec.stepExecutions.Add(1) > int64(r.maxStepExecutions) {
+		return nil, nil, fmt.Errorf(
+			"%w: %d steps executed",
+			ErrMaxStepExecutions,
+			r.maxStepExecutions,
+		)
+	}
+	return r.runStep(ctx, exec, step)
+}
+
+// successTransition returns the transition of the first success
+// action of step matching evalCtx, the actions of the step being
+// considered before the ones of the workflow.
+func successTransition(
+	step *v1.Step,
+	evalCtx *v1.EvalContext,
+) (*transition, error) {
+	for _, action := range step.GetMergedSuccessActions() {
+		ok, err := action.Matches(evalCtx)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"success action %q: failed to evaluate criteria: %w",
+				action.GetName(),
+				err,
+			)
+		}
+		if !ok {
+			continue
+		}
+		if action.GetType() == models.SuccessActionTypeEnd {
+			return &transition{end: true}, nil
+		}
+		return &transition{
+			stepId:     action.GetStepId(),
+			workflowId: action.GetWorkflowId(),
+		}, nil
+	}
+	return nil, nil
+}
+
+// failureAction returns the first failure action of step matching
+// evalCtx, or nil if none does. Retry actions which have reached
+// their retry limit are skipped. If no response was received, the
+// actions whose criteria cannot be evaluated do not match.
+func failureAction(
+	step *v1.Step,
+	evalCtx *v1.EvalContext,
+	retries map[*v1.FailureAction]int,
+) (*v1.FailureAction, error) {
+	for _, action := range step.GetMergedFailureActions() {
+		if action.GetType() == models.FailureActionTypeRetry &&
+			retries[action] >= action.GetRetryLimit() {
+			continue
+		}
+		ok, err := action.Matches(evalCtx)
+		if err != nil && evalCtx.Response == nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failure action %q: failed to evaluate criteria: %w",
+				action.GetName(),
+				err,
+			)
+		}
+		if ok {
+			return action, nil
+		}
+	}
+	return nil, nil
+}
+
+// prepareRetry executes the step or the workflow a retry action
+// references, if any, before the failed step is retried.
+func (r *Runner) prepareRetry(
+	ctx context.Context,
+	exec *execution,
+	action *v1.FailureAction,
+) error {
+	if stepId := action.GetStepId(); stepId != "" {
+		step, _ := exec.workflow.GetStep(stepId)
+		outputs, _, err := r.countedRunStep(ctx, exec, step)
+		if err != nil {
+			return fmt.Errorf("step %q: %w", stepId, err)
+		}
+		exec.steps[stepId] = outputs
+		return nil
+	}
+	workflowId := action.GetWorkflowId()
+	if workflowId == "" {
+		return nil
+	}
+	workflow, err := exec.workflow.GetParent().GetWorkflowByReference(
+		workflowId,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.runWorkflow(ctx, &execution{
+		workflow:       workflow,
+		inputs:         exec.inputs,
+		steps:          map[string]map[string]any{},
+		workflows:      exec.workflows,
+		stepExecutions: exec.stepExecutions,
+	})
+	return err
+}
+
+// transfer completes the workflow of exec and transfers control to
+// the workflow referenced by workflowId, which is executed with the
+// same inputs. The outputs of the latter are returned.
+func (r *Runner) transfer(
+	ctx context.Context,
+	exec *execution,
+	workflowId string,
+) (map[string]any, error) {
+	workflow, err := exec.workflow.GetParent().GetWorkflowByReference(
+		workflowId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"workflow %q: goto target: %w",
+			exec.workflow.GetId(),
+			err,
+		)
+	}
+	if _, err := r.completeWorkflow(exec); err != nil {
+		return nil, err
+	}
+	return r.runWorkflow(ctx, &execution{
+		workflow:       workflow,
+		inputs:         exec.inputs,
+		steps:          map[string]map[string]any{},
+		workflows:      exec.workflows,
+		stepExecutions: exec.stepExecutions,
+	})
+}
+
+// completeWorkflow evaluates and records the outputs of the workflow
+// of exec.
+func (r *Runner) completeWorkflow(
+	exec *execution,
+) (map[string]any, error) {
+	workflowId := exec.workflow.GetId()
+	outputs, err := evaluateOutputs(
+		exec.workflow.GetOutputs(),
+		r.context(exec, nil, nil),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"workflow %q: failed to evaluate outputs: %w",
+			workflowId,
+			err,
+		)
+	}
+	exec.workflows[workflowId].Outputs = outputs
+	return outputs, nil
+}
+
+// stepIndex returns the index of the step identified by stepId.
+// Goto targets are checked when the workflow is loaded, so the step
+// always exists.
+func stepIndex(steps []*v1.Step, stepId string) int {
+	for i, step := range steps {
+		if step.GetId() == stepId {
+			return i
+		}
+	}
+	return len(steps)
+}
+
+// runStep executes the operation or the workflow step calls and
+// returns the outputs of the step along with the context its success
+// criteria and actions are evaluated in.
+func (r *Runner) runStep(
+	ctx context.Context,
+	exec *execution,
+	step *v1.Step,
+) (map[string]any, *v1.EvalContext, error) {
+	var evalCtx *v1.EvalContext
+	var err error
+	if step.GetOpWorkflow() != nil {
+		evalCtx, err = r.callWorkflow(ctx, exec, step)
+	} else {
+		evalCtx, err = r.callOperation(ctx, exec, step)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// All the success criteria must be satisfied for the step to be
+	// deemed successful.
+	criteria := step.GetSuccessCriteria()
+	if resp := evalCtx.Response; len(criteria) == 0 && resp != nil &&
+		(resp.StatusCode < http.StatusOK ||
+			resp.StatusCode >= http.StatusMultipleChoices) {
+		return nil, nil, &stepFailure{
+			err: fmt.Errorf(
+				"server responded with status %d",
+				resp.StatusCode,
+			),
+			evalCtx: evalCtx,
+		}
+	}
+	for _, criterion := range criteria {
+		ok, err := criterion.Evaluate(evalCtx)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"failed to evaluate success criterion: %w",
+				err,
+			)
+		}
+		if !ok {
+			return nil, nil, &stepFailure{
+				err: fmt.Errorf(
+					"success criterion %q is not satisfied",
+					criterion.GetModel().Condition,
+				),
+				evalCtx: evalCtx,
+			}
+		}
+	}
+
+	// The outputs of a called workflow are the outputs of the step,
+	// along with the ones the step declares.
+	outputs := maps.Clone(evalCtx.CalledOutputs)
+	if outputs == nil {
+		outputs = map[string]any{}
+	}
+	declared, err := evaluateOutputs(step.GetOutputs(), evalCtx)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"failed to evaluate outputs: %w",
+			err,
+		)
+	}
+	maps.Copy(outputs, declared)
+	return outputs, evalCtx, nil
+}
+
+// callOperation sends the request described by step and returns the
+// context its response is evaluated in.
+func (r *Runner) callOperation(
+	ctx context.Context,
+	exec *execution,
+	step *v1.Step,
+) (*v1.EvalContext, error) {
+	req, reqCtx, err := r.newRequest(
+		ctx,
+		step,
+		r.context(exec, nil, nil),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		// The request is deemed failed unless the run was canceled.
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		return nil, &stepFailure{
+			err:     fmt.Errorf("failed to send request: %w", err),
+			evalCtx: r.context(exec, reqCtx, nil),
+		}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	body, err := v1.DecodeBody(resp.Header.Get("Content-Type"), data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return r.context(exec, reqCtx, &v1.Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}), nil
+}
+
+// callWorkflow executes the workflow step calls and returns the
+// context holding its outputs. The parameters of the step are the
+// inputs of the workflow, which is executed in its own scope: it can
+// neither reference the inputs nor the steps of the caller.
+func (r *Runner) callWorkflow(
+	ctx context.Context,
+	exec *execution,
+	step *v1.Step,
+) (*v1.EvalContext, error) {
+	callerCtx := r.context(exec, nil, nil)
+	inputs := map[string]any{}
+	for _, param := range step.GetMergedParameters() {
+		value, err := param.GetValue().Evaluate(callerCtx)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"parameter %q: %w",
+				param.GetName(),
+				err,
+			)
+		}
+		inputs[param.GetName()] = value
+	}
+
+	workflow := step.GetOpWorkflow()
+	workflows := map[string]*v1.WorkflowContext{}
+	outputs, err := r.runWorkflow(ctx, &execution{
+		workflow:       workflow,
+		inputs:         inputs,
+		steps:          map[string]map[string]any{},
+		workflows:      workflows,
+		stepExecutions: exec.stepExecutions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	exec.workflows[workflow.GetId()] = workflows[workflow.GetId()]
+
+	evalCtx := r.context(exec, nil, nil)
+	evalCtx.CalledOutputs = outputs
+	return evalCtx, nil
+}
+
+// evaluateOutputs evaluates every output value of a step or a
+// workflow against evalCtx.
+func evaluateOutputs(
+	values map[string]*v1.Value,
+	evalCtx *v1.EvalContext,
+) (map[string]any, error) {
+	outputs := map[string]any{}
+	for name, value := range values {
+		output, err := value.Evaluate(evalCtx)
+		if err != nil {
+			return nil, fmt.Errorf("output %q: %w", name, err)
+		}
+		outputs[name] = output
+	}
+	return outputs, nil
+}
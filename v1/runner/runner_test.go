@@ -0,0 +1,1413 @@
+package runner_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bragdonD/arazzo-go/internal/testutil"
+	v1 "github.com/bragdonD/arazzo-go/v1"
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/bragdonD/arazzo-go/v1/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPetstoreSpec builds a spec whose single source description is
+// the petstore OpenAPI test document.
+func newPetstoreSpec(
+	t *testing.T,
+	workflows ...models.Workflow,
+) *v1.Spec {
+	t.Helper()
+	model := testutil.NewSpecModel(
+		"../test_specs/petstore.openapi.yaml",
+		workflows...,
+	)
+	spec, err := v1.NewSpec(model, "petstore.arazzo.yaml")
+	require.NoError(t, err)
+	return spec
+}
+
+func TestRunner_Run(t *testing.T) {
+	type received struct {
+		method string
+		path   string
+		query  string
+		header string
+		cookie string
+		body   map[string]any
+	}
+	var requests []received
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			rec := received{
+				method: r.Method,
+				path:   r.URL.Path,
+				query:  r.URL.RawQuery,
+				header: r.Header.Get("X-Api-Key"),
+			}
+			if cookie, err := r.Cookie("session"); err == nil {
+				rec.cookie = cookie.Value
+			}
+			if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+				_ = json.Unmarshal(data, &rec.body)
+			}
+			requests = append(requests, rec)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		},
+	))
+	defer server.Close()
+
+	spec := newPetstoreSpec(t, models.Workflow{
+		WorkflowId: "adoptPet",
+		Parameters: []models.ParameterOrReusable{
+			{Parameter: &models.Parameter{
+				Name:  "X-Api-Key",
+				In:    models.ParameterLocationHeader.ToPtr(),
+				Value: "workflow-key",
+			}},
+		},
+		Steps: []models.Step{
+			{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("getPetById"),
+				Parameters: []models.ParameterOrReusable{
+					{Parameter: &models.Parameter{
+						Name:  "petId",
+						In:    models.ParameterLocationPath.ToPtr(),
+						Value: "42",
+					}},
+					{Parameter: &models.Parameter{
+						Name:  "session",
+						In:    models.ParameterLocationCookie.ToPtr(),
+						Value: "abc",
+					}},
+				},
+			},
+			{
+				StepId:      "addPet",
+				OperationId: testutil.Ptr("addPet"),
+				Parameters: []models.ParameterOrReusable{
+					{Parameter: &models.Parameter{
+						Name:  "X-Api-Key",
+						In:    models.ParameterLocationHeader.ToPtr(),
+						Value: "step-key",
+					}},
+				},
+				RequestBody: &models.RequestBody{
+					Payload: map[string]any{"name": "Rex"},
+					Replacements: []models.PayloadReplacement{
+						{Target: "/tags/-", Value: "friendly"},
+					},
+				},
+			},
+			{
+				StepId:      "findPets",
+				OperationId: testutil.Ptr("findPetsByStatus"),
+				Parameters: []models.ParameterOrReusable{
+					{Parameter: &models.Parameter{
+						Name:  "status",
+						In:    models.ParameterLocationQuery.ToPtr(),
+						Value: "available",
+					}},
+				},
+			},
+		},
+		Outputs: map[string]any{"adopted": "yes"},
+	})
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL+"/api/v3"),
+		runner.WithTransport(server.Client().Transport),
+	)
+	outputs, err := r.Run(context.Background(), "adoptPet", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"adopted": "yes"}, outputs)
+
+	require.Len(t, requests, 3)
+	assert.Equal(t, received{
+		method: http.MethodGet,
+		path:   "/api/v3/pet/42",
+		header: "workflow-key",
+		cookie: "abc",
+	}, requests[0])
+	assert.Equal(t, received{
+		method: http.MethodPost,
+		path:   "/api/v3/pet",
+		header: "step-key",
+		body: map[string]any{
+			"name": "Rex",
+			"tags": []any{"friendly"},
+		},
+	}, requests[1])
+	assert.Equal(t, received{
+		method: http.MethodGet,
+		path:   "/api/v3/pet/findByStatus",
+		query:  "status=available",
+		header: "workflow-key",
+	}, requests[2])
+}
+
+func TestRunner_Run_Errors(t *testing.T) {
+	spec := newPetstoreSpec(t, models.Workflow{
+		WorkflowId: "noOperation",
+		Steps:      []models.Step{{StepId: "empty"}},
+	})
+	r := runner.NewRunner(spec)
+
+	tests := []struct {
+		workflowId string
+		wantErr    string
+	}{
+		{"doesNotExist", `workflow "doesNotExist" not found`},
+		{
+			"noOperation",
+			`workflow "noOperation": step "empty": step does not reference a resolvable operation`,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := r.Run(context.Background(), tt.workflowId, nil)
+		assert.EqualError(t, err, tt.wantErr)
+	}
+}
+
+func TestRunner_Run_RuntimeExpressions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/user/login":
+				w.Header().Set("X-Rate-Limit", "100")
+				_, _ = w.Write(
+					[]byte(
+						`"token-` + r.URL.Query().
+							Get("username") +
+							`"`,
+					),
+				)
+			case "/pet/7":
+				if r.Header.Get("Authorization") != "token-john" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write([]byte(`{"id": 7, "name": "Rex"}`))
+			}
+		},
+	))
+	defer server.Close()
+
+	spec := newPetstoreSpec(t, models.Workflow{
+		WorkflowId: "loginAndGetPet",
+		Steps: []models.Step{
+			{
+				StepId:      "login",
+				OperationId: testutil.Ptr("loginUser"),
+				Parameters: []models.ParameterOrReusable{
+					{Parameter: &models.Parameter{
+						Name:  "username",
+						In:    models.ParameterLocationQuery.ToPtr(),
+						Value: "$inputs.username",
+					}},
+				},
+				Outputs: map[string]any{
+					"token":     "$response.body",
+					"rateLimit": "$response.header.X-Rate-Limit",
+				},
+			},
+			{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("getPetById"),
+				Parameters: []models.ParameterOrReusable{
+					{Parameter: &models.Parameter{
+						Name:  "petId",
+						In:    models.ParameterLocationPath.ToPtr(),
+						Value: "$inputs.petId",
+					}},
+					{Parameter: &models.Parameter{
+						Name:  "Authorization",
+						In:    models.ParameterLocationHeader.ToPtr(),
+						Value: "$steps.login.outputs.token",
+					}},
+				},
+				SuccessCriteria: []models.Criterion{
+					{
+						Condition: "$statusCode == 200 && $response.body#/name == 'rex'",
+					},
+				},
+				Outputs: map[string]any{
+					"name":   "$response.body#/name",
+					"status": "$statusCode",
+					"url":    "$url",
+				},
+			},
+		},
+		Outputs: map[string]any{
+			"petName":   "$steps.getPet.outputs.name",
+			"status":    "$steps.getPet.outputs.status",
+			"url":       "$steps.getPet.outputs.url",
+			"rateLimit": "$steps.login.outputs.rateLimit",
+		},
+	})
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+	)
+	outputs, err := r.Run(
+		context.Background(),
+		"loginAndGetPet",
+		map[string]any{"username": "john", "petId": 7},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"petName":   "Rex",
+		"status":    http.StatusOK,
+		"url":       server.URL + "/pet/7",
+		"rateLimit": "100",
+	}, outputs)
+}
+
+func TestRunner_Run_LargeNumbers(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 10000000, "name": "Rex"}`))
+		},
+	))
+	defer server.Close()
+
+	getPet := func(stepId string, petId string) models.Step {
+		return models.Step{
+			StepId:      stepId,
+			OperationId: testutil.Ptr("getPetById"),
+			Parameters: []models.ParameterOrReusable{
+				{Parameter: &models.Parameter{
+					Name:  "petId",
+					In:    models.ParameterLocationPath.ToPtr(),
+					Value: petId,
+				}},
+			},
+			Outputs: map[string]any{"id": "$response.body#/id"},
+		}
+	}
+	spec := newPetstoreSpec(t, models.Workflow{
+		WorkflowId: "getPetTwice",
+		Steps: []models.Step{
+			getPet("first", "1"),
+			getPet("second", "$steps.first.outputs.id"),
+		},
+	})
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+	)
+	_, err := r.Run(context.Background(), "getPetTwice", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/pet/1", "/pet/10000000"}, paths)
+}
+
+func TestRunner_Run_SuccessCriteriaNotSatisfied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		},
+	))
+	defer server.Close()
+
+	spec := newPetstoreSpec(t, models.Workflow{
+		WorkflowId: "getPet",
+		Steps: []models.Step{
+			{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("getPetById"),
+				Parameters: []models.ParameterOrReusable{
+					{Parameter: &models.Parameter{
+						Name:  "petId",
+						In:    models.ParameterLocationPath.ToPtr(),
+						Value: "1",
+					}},
+				},
+				SuccessCriteria: []models.Criterion{
+					{Condition: "$statusCode == 200"},
+				},
+			},
+		},
+	})
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+	)
+	_, err := r.Run(context.Background(), "getPet", nil)
+	assert.EqualError(
+		t,
+		err,
+		`workflow "getPet": step "getPet": success criterion "$statusCode == 200" is not satisfied`,
+	)
+}
+
+func TestRunner_Run_XMLRequestBody(t *testing.T) {
+	var contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			w.Header().Set("Content-Type", "application/xml")
+			_, _ = w.Write(
+				[]byte(`<pet><id>10</id><name>Rex</name></pet>`),
+			)
+		},
+	))
+	defer server.Close()
+
+	spec := newPetstoreSpec(t, models.Workflow{
+		WorkflowId: "addPet",
+		Steps: []models.Step{
+			{
+				StepId:      "addPet",
+				OperationId: testutil.Ptr("addPet"),
+				RequestBody: &models.RequestBody{
+					ContentType: testutil.Ptr("application/xml"),
+					Payload:     `<pet><name/><status>available</status></pet>`,
+					Replacements: []models.PayloadReplacement{
+						{Target: "/pet/name", Value: "$inputs.name"},
+					},
+				},
+				SuccessCriteria: []models.Criterion{
+					{
+						Context:   testutil.Ptr("$response.body"),
+						Condition: "/pet[id > 0 and name = 'Rex']",
+						Type: &models.CriterionTypeOrCriterionExpressionType{
+							CriterionType: models.CriterionTypeXPath.ToPtr(),
+						},
+					},
+				},
+			},
+		},
+	})
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+	)
+	_, err := r.Run(
+		context.Background(),
+		"addPet",
+		map[string]any{"name": "Rex"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "application/xml", contentType)
+	assert.Equal(
+		t,
+		`<?xml version="1.0"?><pet><name>Rex</name><status>available</status></pet>`,
+		body,
+	)
+}
+
+func TestRunner_Run_SuccessActions(t *testing.T) {
+	var paths []string
+	// pending is the number of polls before the first pet is
+	// available.
+	pending := 2
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/pet/1" && pending > 0 {
+				pending--
+				w.WriteHeader(http.StatusAccepted)
+			}
+			_, _ = w.Write(
+				[]byte(`{"name": "pet` + r.URL.Path[5:] + `"}`),
+			)
+		},
+	))
+	defer server.Close()
+
+	getPet := func(stepId string, petId string) models.Step {
+		return models.Step{
+			StepId:      stepId,
+			OperationId: testutil.Ptr("getPetById"),
+			Parameters: []models.ParameterOrReusable{
+				{Parameter: &models.Parameter{
+					Name:  "petId",
+					In:    models.ParameterLocationPath.ToPtr(),
+					Value: petId,
+				}},
+			},
+			Outputs: map[string]any{"name": "$response.body#/name"},
+		}
+	}
+	poll := getPet("poll", "1")
+	poll.OnSuccess = []models.SuccessActionOrReusable{
+		{SuccessAction: &models.SuccessAction{
+			Name:   "pending",
+			Type:   models.SuccessActionTypeGoto,
+			StepId: testutil.Ptr("poll"),
+			Criteria: []models.Criterion{
+				{Condition: "$statusCode == 202"},
+			},
+		}},
+	}
+	transfer := getPet("transfer", "3")
+	transfer.OnSuccess = []models.SuccessActionOrReusable{
+		{SuccessAction: &models.SuccessAction{
+			Name:       "next",
+			Type:       models.SuccessActionTypeGoto,
+			WorkflowId: testutil.Ptr("pollPet"),
+		}},
+	}
+	spec := newPetstoreSpec(t,
+		models.Workflow{
+			WorkflowId: "pollPet",
+			Steps: []models.Step{
+				poll,
+				getPet("second", "2"),
+				getPet("never", "4"),
+			},
+			SuccessActions: []models.SuccessActionOrReusable{
+				{SuccessAction: &models.SuccessAction{
+					Name: "done",
+					Type: models.SuccessActionTypeEnd,
+					Criteria: []models.Criterion{{
+						Context:   testutil.Ptr("$url"),
+						Condition: "/pet/2$",
+						Type: &models.CriterionTypeOrCriterionExpressionType{
+							CriterionType: models.CriterionTypeRegex.ToPtr(),
+						},
+					}},
+				}},
+			},
+			Outputs: map[string]any{
+				"first":  "$steps.poll.outputs.name",
+				"second": "$steps.second.outputs.name",
+			},
+		},
+		models.Workflow{
+			WorkflowId: "transfer",
+			Steps: []models.Step{
+				transfer,
+				getPet("skipped", "5"),
+			},
+			Outputs: map[string]any{
+				"name": "$steps.transfer.outputs.name",
+			},
+		},
+	)
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+	)
+	outputs, err := r.Run(context.Background(), "pollPet", nil)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		map[string]any{"first": "pet1", "second": "pet2"},
+		outputs,
+	)
+	assert.Equal(
+		t,
+		[]string{"/pet/1", "/pet/1", "/pet/1", "/pet/2"},
+		paths,
+	)
+
+	paths, pending = nil, 1
+	outputs, err = r.Run(context.Background(), "transfer", nil)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		map[string]any{"first": "pet1", "second": "pet2"},
+		outputs,
+	)
+	assert.Equal(
+		t,
+		[]string{"/pet/3", "/pet/1", "/pet/1", "/pet/2"},
+		paths,
+	)
+}
+
+func TestRunner_Run_MaxStepExecutions(t *testing.T) {
+	executions := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			executions++
+		},
+	))
+	defer server.Close()
+
+	spec := newPetstoreSpec(t, models.Workflow{
+		WorkflowId: "loop",
+		Steps: []models.Step{
+			{
+				StepId:      "login",
+				OperationId: testutil.Ptr("loginUser"),
+				OnSuccess: []models.SuccessActionOrReusable{
+					{SuccessAction: &models.SuccessAction{
+						Name:   "again",
+						Type:   models.SuccessActionTypeGoto,
+						StepId: testutil.Ptr("login"),
+					}},
+				},
+			},
+		},
+	})
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+		runner.WithMaxStepExecutions(5),
+	)
+	_, err := r.Run(context.Background(), "loop", nil)
+	assert.True(t, errors.Is(err, runner.ErrMaxStepExecutions))
+	assert.EqualError(
+		t,
+		err,
+		`workflow "loop": step "login": maximum number of step executions exceeded: 5 steps executed`,
+	)
+	assert.Equal(t, 5, executions)
+}
+
+// fakeClock is a runner.Clock recording the sleeps instead of
+// waiting.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(_ context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func TestRunner_Run_FailureActions(t *testing.T) {
+	var paths []string
+	// unavailable is the number of requests answered with a 503
+	// before the pets are available.
+	unavailable := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Path == "/pet/9":
+				w.WriteHeader(http.StatusNotFound)
+			case unavailable > 0:
+				unavailable--
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			_, _ = w.Write(
+				[]byte(`{"name": "pet` + r.URL.Path[5:] + `"}`),
+			)
+		},
+	))
+	defer server.Close()
+
+	delay, limit := 2.0, 3
+	getPet := func(stepId string, petId string) models.Step {
+		return models.Step{
+			StepId:      stepId,
+			OperationId: testutil.Ptr("getPetById"),
+			Parameters: []models.ParameterOrReusable{
+				{Parameter: &models.Parameter{
+					Name:  "petId",
+					In:    models.ParameterLocationPath.ToPtr(),
+					Value: petId,
+				}},
+			},
+			SuccessCriteria: []models.Criterion{
+				{Condition: "$statusCode == 200"},
+			},
+		}
+	}
+	retry := getPet("retry", "1")
+	retry.OnFailure = []models.FailureActionOrReusable{
+		{FailureAction: &models.FailureAction{
+			Name:       "unavailable",
+			Type:       models.FailureActionTypeRetry,
+			RetryDelay: &delay,
+			RetryLimit: &limit,
+			Criteria: []models.Criterion{
+				{Condition: "$statusCode == 503"},
+			},
+		}},
+	}
+	missing := getPet("missing", "9")
+	missing.OnFailure = []models.FailureActionOrReusable{
+		{FailureAction: &models.FailureAction{
+			Name:   "fallback",
+			Type:   models.FailureActionTypeGoto,
+			StepId: testutil.Ptr("fallback"),
+		}},
+	}
+	spec := newPetstoreSpec(t,
+		models.Workflow{
+			WorkflowId: "retry",
+			Steps:      []models.Step{retry, getPet("next", "2")},
+			FailureActions: []models.FailureActionOrReusable{
+				{FailureAction: &models.FailureAction{
+					Name: "giveUp",
+					Type: models.FailureActionTypeEnd,
+				}},
+			},
+		},
+		models.Workflow{
+			WorkflowId: "strict",
+			Steps:      []models.Step{retry},
+		},
+		models.Workflow{
+			WorkflowId: "goto",
+			Steps: []models.Step{
+				missing,
+				getPet("skipped", "3"),
+				getPet("fallback", "4"),
+			},
+		},
+	)
+
+	clock := &fakeClock{}
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+		runner.WithClock(clock),
+	)
+
+	tests := []struct {
+		workflowId    string
+		unavailable   int
+		expectedPaths []string
+		expectedSleep int
+		expectedErr   string
+	}{
+		{
+			workflowId:  "retry",
+			unavailable: 2,
+			expectedPaths: []string{
+				"/pet/1",
+				"/pet/1",
+				"/pet/1",
+				"/pet/2",
+			},
+			expectedSleep: 2,
+		},
+		{
+			// The retry limit is reached, the workflow action ends
+			// the workflow.
+			workflowId:  "retry",
+			unavailable: 5,
+			expectedPaths: []string{
+				"/pet/1",
+				"/pet/1",
+				"/pet/1",
+				"/pet/1",
+			},
+			expectedSleep: 3,
+		},
+		{
+			workflowId:  "strict",
+			unavailable: 5,
+			expectedPaths: []string{
+				"/pet/1",
+				"/pet/1",
+				"/pet/1",
+				"/pet/1",
+			},
+			expectedSleep: 3,
+			expectedErr:   `workflow "strict": step "retry": success criterion "$statusCode == 200" is not satisfied`,
+		},
+		{
+			workflowId:    "goto",
+			expectedPaths: []string{"/pet/9", "/pet/4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.workflowId, func(t *testing.T) {
+			paths, unavailable = nil, tt.unavailable
+			clock.sleeps = nil
+
+			_, err := r.Run(context.Background(), tt.workflowId, nil)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedPaths, paths)
+			assert.Len(t, clock.sleeps, tt.expectedSleep)
+			for _, sleep := range clock.sleeps {
+				assert.Equal(t, 2*time.Second, sleep)
+			}
+		})
+	}
+}
+
+// roundTripperFunc is an [http.RoundTripper] calling itself.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(
+	req *http.Request,
+) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRunner_Run_FailureActions_StepErrors(t *testing.T) {
+	// responses lists the status code of the responses to send in
+	// turn, 0 standing for a transport error.
+	var responses []int
+	requests := 0
+	transport := roundTripperFunc(
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+			status := http.StatusOK
+			if len(responses) > 0 {
+				status, responses = responses[0], responses[1:]
+			}
+			if status == 0 {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{
+				StatusCode: status,
+				Header: http.Header{
+					"Content-Type": []string{"application/json"},
+				},
+				Body:    io.NopCloser(strings.NewReader(`{"id": 1}`)),
+				Request: req,
+			}, nil
+		},
+	)
+
+	delay, unavailableLimit, transportLimit := 1.0, 1, 2
+	spec := newPetstoreSpec(t, models.Workflow{
+		WorkflowId: "getPet",
+		Steps: []models.Step{
+			{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("getPetById"),
+				Parameters: []models.ParameterOrReusable{
+					{Parameter: &models.Parameter{
+						Name:  "petId",
+						In:    models.ParameterLocationPath.ToPtr(),
+						Value: "1",
+					}},
+				},
+				OnFailure: []models.FailureActionOrReusable{
+					{FailureAction: &models.FailureAction{
+						Name:       "unavailable",
+						Type:       models.FailureActionTypeRetry,
+						RetryDelay: &delay,
+						RetryLimit: &unavailableLimit,
+						Criteria: []models.Criterion{
+							{Condition: "$statusCode == 503"},
+						},
+					}},
+					{FailureAction: &models.FailureAction{
+						Name:       "transport",
+						Type:       models.FailureActionTypeRetry,
+						RetryDelay: &delay,
+						RetryLimit: &transportLimit,
+					}},
+				},
+			},
+		},
+	})
+
+	clock := &fakeClock{}
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", "http://petstore.test"),
+		runner.WithTransport(transport),
+		runner.WithClock(clock),
+	)
+
+	tests := []struct {
+		name             string
+		responses        []int
+		expectedRequests int
+		expectedErr      string
+	}{
+		{
+			name:             "transport error",
+			responses:        []int{0, 0},
+			expectedRequests: 3,
+		},
+		{
+			name:             "server error",
+			responses:        []int{0, http.StatusServiceUnavailable},
+			expectedRequests: 3,
+		},
+		{
+			name:             "retry limit",
+			responses:        []int{0, 0, 0},
+			expectedRequests: 3,
+			expectedErr:      "connection refused",
+		},
+		{
+			name: "server error retry limit",
+			responses: []int{
+				http.StatusServiceUnavailable,
+				http.StatusServiceUnavailable,
+				http.StatusInternalServerError,
+				http.StatusInternalServerError,
+			},
+			expectedRequests: 4,
+			expectedErr:      `workflow "getPet": step "getPet": server responded with status 500`,
+		},
+		{
+			name: "client error",
+			responses: []int{
+				http.StatusNotFound,
+				http.StatusNotFound,
+				http.StatusNotFound,
+			},
+			expectedRequests: 3,
+			expectedErr:      `workflow "getPet": step "getPet": server responded with status 404`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses, requests = tt.responses, 0
+			clock.sleeps = nil
+
+			_, err := r.Run(context.Background(), "getPet", nil)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedRequests, requests)
+			assert.Len(t, clock.sleeps, tt.expectedRequests-1)
+		})
+	}
+}
+
+func TestRunner_Run_NestedWorkflow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/user/login":
+				_, _ = w.Write(
+					[]byte(
+						`"token-` + r.URL.Query().
+							Get("username") +
+							`"`,
+					),
+				)
+			case "/pet/7":
+				if r.Header.Get("Authorization") != "token-john" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write([]byte(`{"id": 7, "name": "Rex"}`))
+			}
+		},
+	))
+	defer server.Close()
+
+	spec := newPetstoreSpec(t,
+		models.Workflow{
+			WorkflowId: "getPet",
+			Steps: []models.Step{
+				{
+					StepId:     "login",
+					WorkflowId: testutil.Ptr("login"),
+					Parameters: []models.ParameterOrReusable{
+						{Parameter: &models.Parameter{
+							Name:  "username",
+							Value: "$inputs.user",
+						}},
+					},
+					Outputs: map[string]any{
+						"header": "$outputs.token",
+					},
+				},
+				{
+					StepId:      "getPet",
+					OperationId: testutil.Ptr("getPetById"),
+					Parameters: []models.ParameterOrReusable{
+						{Parameter: &models.Parameter{
+							Name:  "petId",
+							In:    models.ParameterLocationPath.ToPtr(),
+							Value: "7",
+						}},
+						{Parameter: &models.Parameter{
+							Name:  "Authorization",
+							In:    models.ParameterLocationHeader.ToPtr(),
+							Value: "$steps.login.outputs.token",
+						}},
+					},
+					SuccessCriteria: []models.Criterion{
+						{Condition: "$statusCode == 200"},
+					},
+					Outputs: map[string]any{
+						"name": "$response.body#/name",
+					},
+				},
+			},
+			Outputs: map[string]any{
+				"name":     "$steps.getPet.outputs.name",
+				"header":   "$steps.login.outputs.header",
+				"username": "$workflows.login.inputs.username",
+			},
+		},
+		models.Workflow{
+			WorkflowId: "login",
+			Steps: []models.Step{
+				{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+					Parameters: []models.ParameterOrReusable{
+						{Parameter: &models.Parameter{
+							Name:  "username",
+							In:    models.ParameterLocationQuery.ToPtr(),
+							Value: "$inputs.username",
+						}},
+					},
+					Outputs: map[string]any{
+						"token": "$response.body",
+					},
+				},
+			},
+			Outputs: map[string]any{
+				"token": "$steps.login.outputs.token",
+			},
+		},
+		models.Workflow{
+			// The called workflow cannot reference the inputs of its
+			// caller.
+			WorkflowId: "leak",
+			Steps: []models.Step{
+				{
+					StepId:     "login",
+					WorkflowId: testutil.Ptr("login"),
+				},
+			},
+		},
+	)
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+	)
+	outputs, err := r.Run(
+		context.Background(),
+		"getPet",
+		map[string]any{"user": "john"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name":     "Rex",
+		"header":   "token-john",
+		"username": "john",
+	}, outputs)
+
+	_, err = r.Run(
+		context.Background(),
+		"leak",
+		map[string]any{"username": "john"},
+	)
+	assert.ErrorContains(
+		t,
+		err,
+		`workflow "leak": step "login": workflow "login": step "login": parameter "username"`,
+	)
+}
+
+func TestRunner_Run_Dependencies(t *testing.T) {
+	var mu sync.Mutex
+	var users []string
+	// concurrent is closed once the "b" and "c" logins are both in
+	// flight, which only happens when dependencies run concurrently.
+	concurrent := make(chan struct{})
+	inFlight := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			user := r.URL.Query().Get("username")
+			mu.Lock()
+			users = append(users, user)
+			wait := r.URL.Query().Get("password") == "wait" &&
+				(user == "b" || user == "c")
+			if wait {
+				inFlight++
+				if inFlight == 2 {
+					close(concurrent)
+				}
+			}
+			mu.Unlock()
+			if wait {
+				select {
+				case <-concurrent:
+				case <-time.After(5 * time.Second):
+					w.WriteHeader(http.StatusGatewayTimeout)
+					return
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`"token-` + user + `"`))
+		},
+	))
+	defer server.Close()
+
+	login := func(workflowId string, dependsOn ...string) models.Workflow {
+		return models.Workflow{
+			WorkflowId: workflowId,
+			DependsOn:  dependsOn,
+			Steps: []models.Step{
+				{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+					Parameters: []models.ParameterOrReusable{
+						{Parameter: &models.Parameter{
+							Name:  "username",
+							In:    models.ParameterLocationQuery.ToPtr(),
+							Value: workflowId,
+						}},
+						{Parameter: &models.Parameter{
+							Name:  "password",
+							In:    models.ParameterLocationQuery.ToPtr(),
+							Value: "$inputs.password",
+						}},
+					},
+					SuccessCriteria: []models.Criterion{
+						{Condition: "$statusCode == 200"},
+					},
+					Outputs: map[string]any{
+						"token": "$response.body",
+					},
+				},
+			},
+			Outputs: map[string]any{
+				"token": "$steps.login.outputs.token",
+			},
+		}
+	}
+	main := login("main", "b", "c")
+	main.Outputs = map[string]any{
+		"a": "$workflows.a.outputs.token",
+		"b": "$workflows.b.outputs.token",
+		"c": "$workflows.c.outputs.token",
+	}
+	spec := newPetstoreSpec(t,
+		main,
+		login("b", "a"),
+		login("c", "a"),
+		login("a"),
+	)
+	expected := map[string]any{
+		"a": "token-a",
+		"b": "token-b",
+		"c": "token-c",
+	}
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+	)
+	outputs, err := r.Run(
+		context.Background(),
+		"main",
+		map[string]any{"password": "secret"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, expected, outputs)
+	assert.Equal(t, []string{"a", "b", "c", "main"}, users)
+
+	users = nil
+	r = runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+		runner.WithParallelism(2),
+	)
+	outputs, err = r.Run(
+		context.Background(),
+		"main",
+		map[string]any{"password": "wait"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, expected, outputs)
+	require.Len(t, users, 4)
+	assert.Equal(t, "a", users[0])
+	assert.ElementsMatch(t, []string{"b", "c"}, users[1:3])
+	assert.Equal(t, "main", users[3])
+}
+
+func TestRunner_Run_DependencyInputs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(
+				[]byte(
+					`"token-` + r.URL.Query().Get("password") + `"`,
+				),
+			)
+		},
+	))
+	defer server.Close()
+
+	loginStep := models.Step{
+		StepId:      "login",
+		OperationId: testutil.Ptr("loginUser"),
+		Parameters: []models.ParameterOrReusable{
+			{Parameter: &models.Parameter{
+				Name:  "username",
+				In:    models.ParameterLocationQuery.ToPtr(),
+				Value: "user",
+			}},
+			{Parameter: &models.Parameter{
+				Name:  "password",
+				In:    models.ParameterLocationQuery.ToPtr(),
+				Value: "$inputs.password",
+			}},
+		},
+		SuccessCriteria: []models.Criterion{
+			{Condition: "$statusCode == 200"},
+		},
+		Outputs: map[string]any{"token": "$response.body"},
+	}
+	spec := newPetstoreSpec(t,
+		models.Workflow{
+			WorkflowId: "main",
+			DependsOn:  []string{"login"},
+			Inputs: map[string]any{
+				"type":     "object",
+				"required": []any{"petId"},
+				"properties": map[string]any{
+					"petId":    map[string]any{"type": "integer"},
+					"password": map[string]any{"type": "string"},
+				},
+			},
+			Steps: []models.Step{loginStep},
+			Outputs: map[string]any{
+				"petId":    "$inputs.petId",
+				"token":    "$workflows.login.outputs.token",
+				"password": "$workflows.login.inputs.password",
+			},
+		},
+		models.Workflow{
+			WorkflowId: "login",
+			Inputs: map[string]any{
+				"type":                 "object",
+				"required":             []any{"password"},
+				"additionalProperties": false,
+				"properties": map[string]any{
+					"password": map[string]any{"type": "string"},
+					"otp": map[string]any{
+						"type":    "string",
+						"default": "none",
+					},
+				},
+			},
+			Steps: []models.Step{loginStep},
+			Outputs: map[string]any{
+				"token": "$steps.login.outputs.token",
+			},
+		},
+	)
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+	)
+	outputs, err := r.Run(
+		context.Background(),
+		"main",
+		map[string]any{"petId": 1, "password": "secret"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"petId":    1,
+		"token":    "token-secret",
+		"password": "secret",
+	}, outputs)
+
+	// The dependency requires an input the caller does not.
+	_, err = r.Run(
+		context.Background(),
+		"main",
+		map[string]any{"petId": 1},
+	)
+	var inputErr *v1.InputValidationError
+	require.ErrorAs(t, err, &inputErr)
+	assert.ErrorContains(
+		t,
+		err,
+		`workflow "main": dependency: workflow "login"`,
+	)
+}
+
+func TestRunner_Run_InvalidInputs(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name": "Rex"}`))
+		},
+	))
+	defer server.Close()
+
+	spec := newPetstoreSpec(t, models.Workflow{
+		WorkflowId: "getPet",
+		Inputs: map[string]any{
+			"type":     "object",
+			"required": []any{"petId"},
+			"properties": map[string]any{
+				"petId": map[string]any{"type": "integer"},
+				"status": map[string]any{
+					"type":    "string",
+					"default": "available",
+				},
+			},
+		},
+		Steps: []models.Step{
+			{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("getPetById"),
+				Parameters: []models.ParameterOrReusable{
+					{Parameter: &models.Parameter{
+						Name:  "petId",
+						In:    models.ParameterLocationPath.ToPtr(),
+						Value: "$inputs.petId",
+					}},
+				},
+			},
+		},
+		Outputs: map[string]any{"status": "$inputs.status"},
+	})
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+	)
+	_, err := r.Run(
+		context.Background(),
+		"getPet",
+		map[string]any{"petId": "seven"},
+	)
+	var inputErr *v1.InputValidationError
+	require.ErrorAs(t, err, &inputErr)
+	assert.EqualError(
+		t,
+		err,
+		`workflow "getPet": arazzo-go: invalid inputs: /petId: got string, want integer`,
+	)
+	assert.Zero(t, requests)
+
+	outputs, err := r.Run(
+		context.Background(),
+		"getPet",
+		map[string]any{"petId": 7},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"status": "available"}, outputs)
+	assert.Equal(t, 1, requests)
+}
+
+func TestRunner_Run_ArazzoSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(
+				[]byte(
+					`"token-` + r.URL.Query().Get("username") + `"`,
+				),
+			)
+		},
+	))
+	defer server.Close()
+
+	model := &models.Spec{
+		Arazzo: "1.0.0",
+		Info:   models.Info{Title: "petstore", Version: "1.0.0"},
+		SourcesDescriptions: []models.SourceDescription{
+			{
+				Name: "shared",
+				Url:  "../test_specs/shared.arazzo.yaml",
+				Type: models.SourceDescriptionTypeArazzo.ToPtr(),
+			},
+		},
+		Workflows: []models.Workflow{
+			{
+				WorkflowId: "main",
+				Steps: []models.Step{
+					{
+						StepId: "login",
+						WorkflowId: testutil.Ptr(
+							"$sourceDescriptions.shared.login",
+						),
+						Parameters: []models.ParameterOrReusable{
+							{Parameter: &models.Parameter{
+								Name:  "username",
+								Value: "$inputs.user",
+							}},
+						},
+					},
+				},
+				Outputs: map[string]any{
+					"token": "$steps.login.outputs.token",
+				},
+			},
+		},
+	}
+	spec, err := v1.NewSpec(model, "petstore.arazzo.yaml")
+	require.NoError(t, err)
+
+	r := runner.NewRunner(
+		spec,
+		runner.WithServerURL("petstore", server.URL),
+		runner.WithTransport(server.Client().Transport),
+	)
+	outputs, err := r.Run(
+		context.Background(),
+		"main",
+		map[string]any{"user": "john"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"token": "token-john"}, outputs)
+
+	// The inputs of the shared workflow are validated against the
+	// schema of the components of its own document.
+	_, err = r.Run(
+		context.Background(),
+		"main",
+		map[string]any{"user": 42},
+	)
+	assert.ErrorContains(
+		t,
+		err,
+		`workflow "login": arazzo-go: invalid inputs: /username: got number, want string`,
+	)
+}
@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+)
+
+// ResolveSourceURL resolves the URL of a source description against
+// base, the URL of the Arazzo document describing it, following the
+// reference resolution of [RFC 3986]. Relative references are
+// resolved against the directory of a local base path, in which case
+// the resolved path is still relative to the working directory if
+// base is.
+//
+// [RFC 3986]: https://www.rfc-editor.org/rfc/rfc3986#section-5
+func ResolveSourceURL(base string, ref string) (string, error) {
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid source url %q: %w", ref, err)
+	}
+	if refURL.IsAbs() || base == "" {
+		return ref, nil
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf(
+			"invalid document url %q: %w",
+			base,
+			err,
+		)
+	}
+	if baseURL.IsAbs() {
+		return baseURL.ResolveReference(refURL).String(), nil
+	}
+	if filepath.IsAbs(ref) {
+		return ref, nil
+	}
+	return filepath.Join(filepath.Dir(base), ref), nil
+}
+
+// localPath returns the path of a local source, which is either a
+// path or a "file" URL.
+func localPath(source string) string {
+	sourceURL, err := url.Parse(source)
+	if err != nil || sourceURL.Scheme != FileScheme {
+		return source
+	}
+	return sourceURL.Path
+}
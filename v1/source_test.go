@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSourceURL(t *testing.T) {
+	tests := []struct {
+		base     string
+		ref      string
+		expected string
+	}{
+		{"", "petstore.yaml", "petstore.yaml"},
+		{"petstore.arazzo.yaml", "petstore.yaml", "petstore.yaml"},
+		{
+			"specs/petstore.arazzo.yaml",
+			"petstore.yaml",
+			"specs/petstore.yaml",
+		},
+		{
+			"specs/petstore.arazzo.yaml",
+			"../apis/petstore.yaml",
+			"apis/petstore.yaml",
+		},
+		{
+			"petstore.arazzo.yaml",
+			"../apis/petstore.yaml",
+			"../apis/petstore.yaml",
+		},
+		{
+			"/specs/petstore.arazzo.yaml",
+			"/apis/petstore.yaml",
+			"/apis/petstore.yaml",
+		},
+		{
+			"specs/petstore.arazzo.yaml",
+			"https://example.com/petstore.yaml",
+			"https://example.com/petstore.yaml",
+		},
+		{
+			"https://example.com/specs/petstore.arazzo.yaml",
+			"../apis/petstore.yaml?v=1",
+			"https://example.com/apis/petstore.yaml?v=1",
+		},
+		{
+			"https://example.com/specs/petstore.arazzo.yaml",
+			"/petstore.yaml",
+			"https://example.com/petstore.yaml",
+		},
+		{
+			"file:///specs/petstore.arazzo.yaml",
+			"petstore.yaml",
+			"file:///specs/petstore.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.base+" "+tt.ref, func(t *testing.T) {
+			resolved, err := ResolveSourceURL(tt.base, tt.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resolved)
+		})
+	}
+}
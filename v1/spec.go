@@ -1,6 +1,17 @@
 package v1
 
-import "github.com/bragdonD/arazzo-go/v1/models"
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	arazzo "github.com/bragdonD/arazzo-go"
+	"github.com/bragdonD/arazzo-go/v1/expression"
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/pb33f/libopenapi/datamodel"
+)
 
 type Spec struct {
 	model      *models.Spec
@@ -8,33 +19,473 @@ type Spec struct {
 	workflows  []*Workflow
 	components *Components
 	oaiDocs    []*OAIDocument
-	// arazzo documents will be handled in a future version
-	// arazzoDocs []*Spec // TODO: Find a way to break out of circular dependencies
+	// arazzoDocs holds the Arazzo source descriptions by name. A
+	// document referenced by several documents is loaded once and
+	// shared between them.
+	arazzoDocs map[string]*Spec
+}
+
+// SpecOption defines a functional option for configuring NewSpec.
+type SpecOption func(*specOptions)
+
+// specOptions holds the configuration of NewSpec, along with the
+// state shared by the Arazzo documents it loads.
+type specOptions struct {
+	loader     *arazzo.Loader
+	oaiOptions []OAIDocumentOption
+	// documents caches the Arazzo documents loaded by URL.
+	documents map[string]*Spec
+	// loading lists the URLs of the Arazzo documents being loaded,
+	// each one referencing the next.
+	loading []string
+}
+
+// WithLoader sets the [arazzo.Loader] the source descriptions are
+// fetched with, along with its remote and local lookup policy. By
+// default, only local files can be loaded.
+func WithLoader(loader *arazzo.Loader) SpecOption {
+	return func(o *specOptions) {
+		o.loader = loader
+		o.oaiOptions = append(o.oaiOptions, WithOAILoader(loader))
+	}
 }
 
-func NewSpec(model *models.Spec, url string) (*Spec, error) {
+// WithDocumentConfiguration sets the configuration the OpenAPI source
+// descriptions are built with. It defaults to
+// [DefaultOAIDocumentConfiguration].
+func WithDocumentConfiguration(
+	config *datamodel.DocumentConfiguration,
+) SpecOption {
+	return func(o *specOptions) {
+		o.oaiOptions = append(
+			o.oaiOptions,
+			WithOAIDocumentConfiguration(config),
+		)
+	}
+}
+
+// NewSpec creates a new Spec from its model. The url is the location
+// the document was loaded from, the URLs of the source descriptions
+// being resolved against it.
+//
+// Arazzo source descriptions are loaded recursively, each document
+// being loaded once. A document referencing itself, directly or
+// through other documents, results in an error.
+func NewSpec(
+	model *models.Spec,
+	url string,
+	opts ...SpecOption,
+) (*Spec, error) {
+	options := &specOptions{
+		loader:    arazzo.NewLoader(arazzo.AllowLocalLookup()),
+		documents: map[string]*Spec{},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return newSpec(model, url, options)
+}
+
+// newSpec creates a new Spec from its model, loading its source
+// descriptions with options.
+func newSpec(
+	model *models.Spec,
+	url string,
+	options *specOptions,
+) (*Spec, error) {
+	options.loading = append(options.loading, documentKey(url))
+	defer func() {
+		options.loading = options.loading[:len(options.loading)-1]
+	}()
+
 	spec := &Spec{
 		model:      model,
 		url:        url,
 		workflows:  []*Workflow{},
 		components: NewComponents(model.Components),
 		oaiDocs:    []*OAIDocument{},
+		arazzoDocs: map[string]*Spec{},
 	}
 
 	for _, source := range model.SourcesDescriptions {
-		if *source.Type == models.SourceDescriptionTypeOpenAPI {
-			doc, err := NewOAIDocument(source.Url)
+		sourceURL, err := ResolveSourceURL(url, source.Url)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"source description %q: %w",
+				source.Name,
+				err,
+			)
+		}
+		if source.Type != nil &&
+			*source.Type == models.SourceDescriptionTypeArazzo {
+			doc, err := loadArazzoDocument(sourceURL, options)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf(
+					"source description %q: %w",
+					source.Name,
+					err,
+				)
+			}
+			spec.arazzoDocs[source.Name] = doc
+			continue
+		}
+		doc, err := NewOAIDocument(sourceURL, options.oaiOptions...)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"source description %q: %w",
+				source.Name,
+				err,
+			)
+		}
+		doc.name = source.Name
+		spec.oaiDocs = append(spec.oaiDocs, doc)
+	}
+
+	// Workflows are built once every source description is loaded
+	// so that steps can resolve the operations they reference.
+	for i := range model.Workflows {
+		workflow, err := NewWorkflow(&model.Workflows[i], spec)
+		if err != nil {
+			return nil, err
+		}
+		spec.workflows = append(spec.workflows, workflow)
+	}
+
+	// Steps calling a workflow are resolved once every workflow is
+	// built, as they may reference a workflow declared after theirs.
+	for _, workflow := range spec.workflows {
+		for _, step := range workflow.steps {
+			if err := step.resolveOpWorkflow(); err != nil {
+				return nil, fmt.Errorf(
+					"workflow %q: %w",
+					workflow.id,
+					err,
+				)
 			}
-			spec.oaiDocs = append(spec.oaiDocs, doc)
 		}
-		// TODO: Handle arazzo source types
+		if err := workflow.ResolveDependencies(); err != nil {
+			return nil, err
+		}
+	}
+	if err := spec.checkDependencyCycles(); err != nil {
+		return nil, err
 	}
 
 	return spec, nil
 }
 
+// loadArazzoDocument loads the Arazzo document located at url, or
+// returns it from the cache of options if it has already been loaded.
+func loadArazzoDocument(
+	url string,
+	options *specOptions,
+) (*Spec, error) {
+	key := documentKey(url)
+	if doc, ok := options.documents[key]; ok {
+		return doc, nil
+	}
+	if i := slices.Index(options.loading, key); i >= 0 {
+		return nil, fmt.Errorf(
+			"circular reference: %s",
+			strings.Join(append(options.loading[i:], key), " -> "),
+		)
+	}
+
+	data, err := options.loader.LoadFile(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %q: %w", url, err)
+	}
+	model, err := models.ExtractSpecWithDocumentCheck(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %q: %w", url, err)
+	}
+	doc, err := newSpec(model, url, options)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", url, err)
+	}
+	options.documents[key] = doc
+	return doc, nil
+}
+
+// documentKey returns the key identifying the document located at url
+// in the cache of the loaded documents.
+func documentKey(url string) string {
+	if arazzo.IsRemoteFile(url) {
+		return url
+	}
+	return filepath.Clean(localPath(url))
+}
+
+// checkDependencyCycles verifies that no workflow depends on itself,
+// directly or through other workflows.
+func (s *Spec) checkDependencyCycles() error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[*Workflow]int{}
+	path := []string{}
+	var visit func(workflow *Workflow) error
+	visit = func(workflow *Workflow) error {
+		path = append(path, workflow.id)
+		defer func() { path = path[:len(path)-1] }()
+		switch state[workflow] {
+		case visiting:
+			start := slices.Index(path, workflow.id)
+			return fmt.Errorf(
+				"workflow %q: circular dependency: %s",
+				workflow.id,
+				strings.Join(path[start:], " -> "),
+			)
+		case visited:
+			return nil
+		}
+		state[workflow] = visiting
+		for _, dependency := range workflow.dependencies {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		state[workflow] = visited
+		return nil
+	}
+	for _, workflow := range s.workflows {
+		if err := visit(workflow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Spec) GetModel() *models.Spec {
+	return s.model
+}
+
+func (s *Spec) GetURL() string {
+	return s.url
+}
+
 func (s *Spec) GetComponents() *Components {
 	return s.components
 }
+
+func (s *Spec) GetWorkflows() []*Workflow {
+	return s.workflows
+}
+
+// GetWorkflow returns the workflow identified by workflowId.
+func (s *Spec) GetWorkflow(workflowId string) (*Workflow, bool) {
+	for _, workflow := range s.workflows {
+		if workflow.id == workflowId {
+			return workflow, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Spec) GetOAIDocuments() []*OAIDocument {
+	return s.oaiDocs
+}
+
+// GetArazzoDocuments returns the Arazzo source descriptions by name.
+func (s *Spec) GetArazzoDocuments() map[string]*Spec {
+	return s.arazzoDocs
+}
+
+// GetArazzoDocument returns the Arazzo source description named name.
+func (s *Spec) GetArazzoDocument(name string) (*Spec, bool) {
+	doc, ok := s.arazzoDocs[name]
+	return doc, ok
+}
+
+// sourceArazzoDocument returns the Arazzo source description named
+// name or an error describing why there is none.
+func (s *Spec) sourceArazzoDocument(name string) (*Spec, error) {
+	if doc, ok := s.GetArazzoDocument(name); ok {
+		return doc, nil
+	}
+	for _, source := range s.model.SourcesDescriptions {
+		if source.Name == name {
+			return nil, fmt.Errorf(
+				"source description %q is not an arazzo document",
+				name,
+			)
+		}
+	}
+	return nil, fmt.Errorf("source description %q not found", name)
+}
+
+// GetWorkflowByReference resolves ref, either the workflowId of a
+// workflow of the spec or a $sourceDescriptions.<name>.<workflowId>
+// expression referencing a workflow of an Arazzo source description.
+func (s *Spec) GetWorkflowByReference(ref string) (*Workflow, error) {
+	sourceRef, ok := strings.CutPrefix(
+		ref,
+		expression.ABNFExpressionSourceDescriptions,
+	)
+	if !ok {
+		workflow, ok := s.GetWorkflow(ref)
+		if !ok {
+			return nil, fmt.Errorf("workflow %q not found", ref)
+		}
+		return workflow, nil
+	}
+
+	name, workflowId, ok := strings.Cut(sourceRef, ".")
+	if !ok || name == "" || workflowId == "" {
+		return nil, fmt.Errorf(
+			"workflow %q: expected $sourceDescriptions.<name>.<workflowId>",
+			ref,
+		)
+	}
+	doc, err := s.sourceArazzoDocument(name)
+	if err != nil {
+		return nil, fmt.Errorf("workflow %q: %w", ref, err)
+	}
+	workflow, ok := doc.GetWorkflow(workflowId)
+	if !ok {
+		return nil, fmt.Errorf(
+			"workflow %q not found in source description %q",
+			workflowId,
+			name,
+		)
+	}
+	return workflow, nil
+}
+
+// GetOAIDocument returns the OpenAPI source description named name.
+func (s *Spec) GetOAIDocument(name string) (*OAIDocument, bool) {
+	for _, doc := range s.oaiDocs {
+		if doc.name == name {
+			return doc, true
+		}
+	}
+	return nil, false
+}
+
+// sourceOAIDocument returns the OpenAPI source description named name
+// or an error describing why there is none.
+func (s *Spec) sourceOAIDocument(name string) (*OAIDocument, error) {
+	if doc, ok := s.GetOAIDocument(name); ok {
+		return doc, nil
+	}
+	for _, source := range s.model.SourcesDescriptions {
+		if source.Name == name {
+			return nil, fmt.Errorf(
+				"source description %q is not an openapi document",
+				name,
+			)
+		}
+	}
+	return nil, fmt.Errorf("source description %q not found", name)
+}
+
+// GetOperationById resolves operationId, either the operationId of an
+// operation of the OpenAPI source descriptions or a
+// $sourceDescriptions.<name>.<operationId> expression. A plain
+// operationId defined by several source descriptions is ambiguous and
+// results in an error.
+func (s *Spec) GetOperationById(
+	operationId string,
+) (*OAIOperation, error) {
+	if ref, ok := strings.CutPrefix(
+		operationId,
+		expression.ABNFExpressionSourceDescriptions,
+	); ok {
+		name, id, ok := strings.Cut(ref, ".")
+		if !ok {
+			return nil, fmt.Errorf(
+				"operation %q: expected $sourceDescriptions.<name>.<operationId>",
+				operationId,
+			)
+		}
+		doc, err := s.sourceOAIDocument(name)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"operation %q: %w",
+				operationId,
+				err,
+			)
+		}
+		operation, err := doc.GetOperationById(id)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"operation %q not found in source description %q",
+				id,
+				name,
+			)
+		}
+		return operation, nil
+	}
+
+	found := []*OAIOperation{}
+	sources := []string{}
+	for _, doc := range s.oaiDocs {
+		operation, err := doc.GetOperationById(operationId)
+		if err == nil {
+			found = append(found, operation)
+			sources = append(sources, strconv.Quote(doc.name))
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("operation %q not found", operationId)
+	case 1:
+		return found[0], nil
+	}
+	return nil, fmt.Errorf(
+		"operation %q is ambiguous, it is defined by the source descriptions %s",
+		operationId,
+		strings.Join(sources, ", "),
+	)
+}
+
+// GetOperationByPath resolves operationPath, a reference to an
+// OpenAPI source description combined with a JSON pointer to one of
+// its operations such as
+// "{$sourceDescriptions.petstore.url}#/paths/~1pet~1{petId}/get".
+func (s *Spec) GetOperationByPath(
+	operationPath string,
+) (*OAIOperation, error) {
+	source, pointer, ok := strings.Cut(operationPath, "#")
+	source = strings.TrimSuffix(strings.TrimPrefix(source, "{"), "}")
+	name, isSource := strings.CutPrefix(
+		source,
+		expression.ABNFExpressionSourceDescriptions,
+	)
+	name, isURL := strings.CutSuffix(name, ".url")
+	if !ok || !isSource || !isURL {
+		return nil, fmt.Errorf(
+			"operation path %q: expected {$sourceDescriptions.<name>.url}#<json pointer>",
+			operationPath,
+		)
+	}
+	doc, err := s.sourceOAIDocument(name)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"operation path %q: %w",
+			operationPath,
+			err,
+		)
+	}
+
+	tokens, err := jsonPointerTokens(pointer)
+	if err != nil || len(tokens) != 3 || tokens[0] != "paths" {
+		return nil, fmt.Errorf(
+			"operation path %q: json pointer %q does not reference an operation",
+			operationPath,
+			pointer,
+		)
+	}
+	operation, err := doc.GetOperationByPath(
+		tokens[1],
+		HTTPMethod(strings.ToUpper(tokens[2])),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"operation path %q: operation not found",
+			operationPath,
+		)
+	}
+	return operation, nil
+}
@@ -1 +1,798 @@
 package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	arazzo "github.com/bragdonD/arazzo-go"
+	"github.com/bragdonD/arazzo-go/internal/testutil"
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// petstoreOpenAPI is the location of the petstore OpenAPI test
+// document.
+const petstoreOpenAPI = "test_specs/petstore.openapi.yaml"
+
+func TestNewSpec_SuccessActions(t *testing.T) {
+	model := testutil.NewSpecModel(petstoreOpenAPI, models.Workflow{
+		WorkflowId: "getPet",
+		Steps: []models.Step{
+			{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("getPetById"),
+				OnSuccess: []models.SuccessActionOrReusable{
+					{SuccessAction: &models.SuccessAction{
+						Name:   "retry",
+						Type:   models.SuccessActionTypeGoto,
+						StepId: testutil.Ptr("getPet"),
+						Criteria: []models.Criterion{
+							{Condition: "$statusCode == 202"},
+						},
+					}},
+					{Reusable: &models.Reusable{
+						Reference: "$components.successActions.done",
+					}},
+				},
+			},
+		},
+		SuccessActions: []models.SuccessActionOrReusable{
+			{SuccessAction: &models.SuccessAction{
+				Name:   "done",
+				Type:   models.SuccessActionTypeGoto,
+				StepId: testutil.Ptr("getPet"),
+			}},
+			{SuccessAction: &models.SuccessAction{
+				Name: "end",
+				Type: models.SuccessActionTypeEnd,
+			}},
+		},
+	})
+	model.Components = &models.Components{
+		SuccessActions: map[string]models.SuccessAction{
+			"done": {Name: "done", Type: models.SuccessActionTypeEnd},
+		},
+	}
+
+	spec, err := NewSpec(model, "petstore.arazzo.yaml")
+	require.NoError(t, err)
+
+	workflow, ok := spec.GetWorkflow("getPet")
+	require.True(t, ok)
+	step, ok := workflow.GetStep("getPet")
+	require.True(t, ok)
+
+	// The "done" action of the step overrides the one of the
+	// workflow.
+	actions := step.GetMergedSuccessActions()
+	require.Len(t, actions, 3)
+	assert.Equal(t, "retry", actions[0].GetName())
+	assert.Equal(t, "getPet", actions[0].GetStepId())
+	assert.Len(t, actions[0].GetCriteria(), 1)
+	assert.Equal(t, "done", actions[1].GetName())
+	assert.Equal(t, models.SuccessActionTypeEnd, actions[1].GetType())
+	assert.Equal(t, "end", actions[2].GetName())
+}
+
+func TestNewSpec_InvalidSuccessActions(t *testing.T) {
+	tests := []struct {
+		action   models.SuccessActionOrReusable
+		expected string
+	}{
+		{
+			models.SuccessActionOrReusable{
+				SuccessAction: &models.SuccessAction{
+					Name: "next",
+					Type: models.SuccessActionTypeGoto,
+				},
+			},
+			`step "getPet": success action "next": a goto action requires a stepId or a workflowId`,
+		},
+		{
+			models.SuccessActionOrReusable{
+				SuccessAction: &models.SuccessAction{
+					Name:       "next",
+					Type:       models.SuccessActionTypeGoto,
+					StepId:     testutil.Ptr("getPet"),
+					WorkflowId: testutil.Ptr("getPet"),
+				},
+			},
+			`step "getPet": success action "next": stepId and workflowId are mutually exclusive`,
+		},
+		{
+			models.SuccessActionOrReusable{
+				SuccessAction: &models.SuccessAction{
+					Name: "next",
+					Type: "retry",
+				},
+			},
+			`step "getPet": success action "next": unknown type "retry"`,
+		},
+		{
+			models.SuccessActionOrReusable{
+				SuccessAction: &models.SuccessAction{
+					Name:   "next",
+					Type:   models.SuccessActionTypeGoto,
+					StepId: testutil.Ptr("missing"),
+				},
+			},
+			`step "getPet": success action "next": step "missing" not found`,
+		},
+		{
+			models.SuccessActionOrReusable{
+				SuccessAction: &models.SuccessAction{
+					Name: "next",
+					Type: models.SuccessActionTypeEnd,
+					Criteria: []models.Criterion{
+						{Condition: "$statusCode = 200"},
+					},
+				},
+			},
+			`step "getPet": success action "next": invalid criterion ` +
+				`"$statusCode = 200": arazzo-go: condition: syntax error at ` +
+				`pos: 12: unexpected character '='`,
+		},
+		{
+			models.SuccessActionOrReusable{Reusable: &models.Reusable{
+				Reference: "$components.failureActions.next",
+			}},
+			`step "getPet": expected $components.successActions.<name>`,
+		},
+		{
+			models.SuccessActionOrReusable{Reusable: &models.Reusable{
+				Reference: "$components.successActions.missing",
+			}},
+			`step "getPet": success action not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			model := testutil.NewSpecModel(
+				petstoreOpenAPI,
+				models.Workflow{
+					WorkflowId: "getPet",
+					Steps: []models.Step{
+						{
+							StepId:      "getPet",
+							OperationId: testutil.Ptr("getPetById"),
+							OnSuccess: []models.SuccessActionOrReusable{
+								tt.action,
+							},
+						},
+					},
+				},
+			)
+			model.Components = &models.Components{}
+
+			_, err := NewSpec(model, "petstore.arazzo.yaml")
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestNewSpec_FailureActions(t *testing.T) {
+	delay, limit := 1.5, 3
+	model := testutil.NewSpecModel(petstoreOpenAPI, models.Workflow{
+		WorkflowId: "getPet",
+		Steps: []models.Step{
+			{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("getPetById"),
+				OnFailure: []models.FailureActionOrReusable{
+					{Reusable: &models.Reusable{
+						Reference: "$components.failureActions.retry",
+					}},
+				},
+			},
+		},
+		FailureActions: []models.FailureActionOrReusable{
+			{FailureAction: &models.FailureAction{
+				Name: "retry",
+				Type: models.FailureActionTypeEnd,
+			}},
+			{FailureAction: &models.FailureAction{
+				Name: "end",
+				Type: models.FailureActionTypeEnd,
+			}},
+		},
+	})
+	model.Components = &models.Components{
+		FailureActions: map[string]models.FailureAction{
+			"retry": {
+				Name:       "retry",
+				Type:       models.FailureActionTypeRetry,
+				RetryDelay: &delay,
+				RetryLimit: &limit,
+			},
+		},
+	}
+
+	spec, err := NewSpec(model, "petstore.arazzo.yaml")
+	require.NoError(t, err)
+
+	workflow, ok := spec.GetWorkflow("getPet")
+	require.True(t, ok)
+	step, ok := workflow.GetStep("getPet")
+	require.True(t, ok)
+
+	// The "retry" action of the step overrides the one of the
+	// workflow, the "end" action is kept.
+	actions := step.GetMergedFailureActions()
+	require.Len(t, actions, 2)
+	assert.Equal(t, "retry", actions[0].GetName())
+	assert.Equal(
+		t,
+		models.FailureActionTypeRetry,
+		actions[0].GetType(),
+	)
+	assert.Equal(t, 1500*time.Millisecond, actions[0].GetRetryDelay())
+	assert.Equal(t, 3, actions[0].GetRetryLimit())
+	assert.Equal(t, "end", actions[1].GetName())
+	assert.Equal(t, 1, actions[1].GetRetryLimit())
+}
+
+func TestNewSpec_InvalidFailureActions(t *testing.T) {
+	delay, limit := -1.0, -1
+	tests := []struct {
+		action   models.FailureAction
+		expected string
+	}{
+		{
+			models.FailureAction{
+				Name: "next",
+				Type: models.FailureActionTypeGoto,
+			},
+			`step "getPet": failure action "next": a goto action requires a stepId or a workflowId`,
+		},
+		{
+			models.FailureAction{
+				Name:       "again",
+				Type:       models.FailureActionTypeRetry,
+				StepId:     testutil.Ptr("getPet"),
+				WorkflowId: testutil.Ptr("getPet"),
+			},
+			`step "getPet": failure action "again": stepId and workflowId are mutually exclusive`,
+		},
+		{
+			models.FailureAction{
+				Name:       "again",
+				Type:       models.FailureActionTypeRetry,
+				RetryDelay: &delay,
+			},
+			`step "getPet": failure action "again": retryDelay must not be negative`,
+		},
+		{
+			models.FailureAction{
+				Name:       "again",
+				Type:       models.FailureActionTypeRetry,
+				RetryLimit: &limit,
+			},
+			`step "getPet": failure action "again": retryLimit must not be negative`,
+		},
+		{
+			models.FailureAction{Name: "skip", Type: "skip"},
+			`step "getPet": failure action "skip": unknown type "skip"`,
+		},
+		{
+			models.FailureAction{
+				Name:   "again",
+				Type:   models.FailureActionTypeRetry,
+				StepId: testutil.Ptr("missing"),
+			},
+			`step "getPet": failure action "again": step "missing" not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			model := testutil.NewSpecModel(
+				petstoreOpenAPI,
+				models.Workflow{
+					WorkflowId: "getPet",
+					Steps: []models.Step{
+						{
+							StepId:      "getPet",
+							OperationId: testutil.Ptr("getPetById"),
+							OnFailure: []models.FailureActionOrReusable{
+								{FailureAction: &tt.action},
+							},
+						},
+					},
+				},
+			)
+			model.Components = &models.Components{}
+
+			_, err := NewSpec(model, "petstore.arazzo.yaml")
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestNewSpec_StepWorkflow(t *testing.T) {
+	model := testutil.NewSpecModel(petstoreOpenAPI,
+		models.Workflow{
+			WorkflowId: "main",
+			Steps: []models.Step{
+				{StepId: "login", WorkflowId: testutil.Ptr("login")},
+				{
+					StepId: "remote",
+					WorkflowId: testutil.Ptr(
+						"$sourceDescriptions.shared.login",
+					),
+				},
+			},
+		},
+		models.Workflow{
+			WorkflowId: "login",
+			Steps: []models.Step{
+				{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+				},
+			},
+		},
+	)
+	model.SourcesDescriptions = append(
+		model.SourcesDescriptions,
+		models.SourceDescription{
+			Name: "shared",
+			Url:  "test_specs/shared.arazzo.yaml",
+			Type: models.SourceDescriptionTypeArazzo.ToPtr(),
+		},
+	)
+
+	spec, err := NewSpec(model, "petstore.arazzo.yaml")
+	require.NoError(t, err)
+
+	workflow, ok := spec.GetWorkflow("main")
+	require.True(t, ok)
+	login, ok := spec.GetWorkflow("login")
+	require.True(t, ok)
+	step, ok := workflow.GetStep("login")
+	require.True(t, ok)
+	assert.Same(t, login, step.GetOpWorkflow())
+	shared, ok := spec.GetArazzoDocument("shared")
+	require.True(t, ok)
+	sharedLogin, ok := shared.GetWorkflow("login")
+	require.True(t, ok)
+	step, ok = workflow.GetStep("remote")
+	require.True(t, ok)
+	assert.Same(t, sharedLogin, step.GetOpWorkflow())
+	// The workflow of the shared document resolves its references
+	// within the components of its own document.
+	require.NotNil(t, sharedLogin.GetInputs())
+	_, err = sharedLogin.ValidateInputs(map[string]any{})
+	assert.EqualError(
+		t,
+		err,
+		`workflow "login": arazzo-go: invalid inputs: /username: is required`,
+	)
+
+	model.Workflows[0].Steps[1].WorkflowId = testutil.Ptr(
+		"$sourceDescriptions.shared.missing",
+	)
+	_, err = NewSpec(model, "petstore.arazzo.yaml")
+	assert.EqualError(
+		t,
+		err,
+		`workflow "main": step "remote": workflow "missing" not found in source description "shared"`,
+	)
+
+	model.Workflows[0].Steps[0].WorkflowId = testutil.Ptr("missing")
+	_, err = NewSpec(model, "petstore.arazzo.yaml")
+	assert.EqualError(
+		t,
+		err,
+		`workflow "main": step "login": workflow "missing" not found`,
+	)
+}
+
+func TestNewSpec_Dependencies(t *testing.T) {
+	workflow := func(workflowId string, dependsOn ...string) models.Workflow {
+		return models.Workflow{
+			WorkflowId: workflowId,
+			DependsOn:  dependsOn,
+			Steps: []models.Step{
+				{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+				},
+			},
+		}
+	}
+
+	model := testutil.NewSpecModel(petstoreOpenAPI,
+		workflow("main", "login", "$sourceDescriptions.shared.setup"),
+		workflow("login"),
+	)
+	model.SourcesDescriptions = append(
+		model.SourcesDescriptions,
+		models.SourceDescription{
+			Name: "shared",
+			Url:  "test_specs/shared.arazzo.yaml",
+			Type: models.SourceDescriptionTypeArazzo.ToPtr(),
+		},
+	)
+	spec, err := NewSpec(model, "petstore.arazzo.yaml")
+	require.NoError(t, err)
+	main, ok := spec.GetWorkflow("main")
+	require.True(t, ok)
+	login, ok := spec.GetWorkflow("login")
+	require.True(t, ok)
+	shared, ok := spec.GetArazzoDocument("shared")
+	require.True(t, ok)
+	setup, ok := shared.GetWorkflow("setup")
+	require.True(t, ok)
+	assert.Equal(t, []*Workflow{login, setup}, main.GetDependencies())
+
+	tests := []struct {
+		workflows []models.Workflow
+		expected  string
+	}{
+		{
+			[]models.Workflow{workflow("main", "missing")},
+			`workflow "main": dependency: workflow "missing" not found`,
+		},
+		{
+			[]models.Workflow{
+				workflow(
+					"main",
+					"$sourceDescriptions.petstore.login",
+				),
+			},
+			`workflow "main": dependency: workflow "$sourceDescriptions.petstore.login": ` +
+				`source description "petstore" is not an arazzo document`,
+		},
+		{
+			[]models.Workflow{
+				workflow("main", "$sourceDescriptions.missing.login"),
+			},
+			`workflow "main": dependency: workflow "$sourceDescriptions.missing.login": ` +
+				`source description "missing" not found`,
+		},
+		{
+			[]models.Workflow{workflow("main", "main")},
+			`workflow "main": circular dependency: main -> main`,
+		},
+		{
+			[]models.Workflow{
+				workflow("main", "a"),
+				workflow("a", "b"),
+				workflow("b", "a"),
+			},
+			`workflow "a": circular dependency: a -> b -> a`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			_, err := NewSpec(
+				testutil.NewSpecModel(
+					petstoreOpenAPI,
+					tt.workflows...),
+				"petstore.arazzo.yaml",
+			)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestNewSpec_StepOperations(t *testing.T) {
+	newModel := func(steps ...models.Step) *models.Spec {
+		model := testutil.NewSpecModel(
+			petstoreOpenAPI,
+			models.Workflow{
+				WorkflowId: "main",
+				Steps:      steps,
+			},
+		)
+		model.SourcesDescriptions = append(
+			model.SourcesDescriptions,
+			models.SourceDescription{
+				Name: "mirror",
+				Url:  "test_specs/petstore.openapi.yaml",
+			},
+			models.SourceDescription{
+				Name: "shared",
+				Url:  "test_specs/shared.arazzo.yaml",
+				Type: models.SourceDescriptionTypeArazzo.ToPtr(),
+			},
+		)
+		return model
+	}
+
+	spec, err := NewSpec(newModel(
+		models.Step{
+			StepId: "byId",
+			OperationId: testutil.Ptr(
+				"$sourceDescriptions.mirror.getPetById",
+			),
+		},
+		models.Step{
+			StepId: "byPath",
+			OperationPath: testutil.Ptr(
+				"{$sourceDescriptions.petstore.url}#/paths/~1pet~1{petId}/get",
+			),
+		},
+	), "petstore.arazzo.yaml")
+	require.NoError(t, err)
+	workflow, ok := spec.GetWorkflow("main")
+	require.True(t, ok)
+
+	step, ok := workflow.GetStep("byId")
+	require.True(t, ok)
+	require.NotNil(t, step.GetOperation())
+	assert.Equal(
+		t,
+		"getPetById",
+		step.GetOperation().Operation.OperationId,
+	)
+	assert.Equal(t, "mirror", step.GetOperation().Document.GetName())
+
+	step, ok = workflow.GetStep("byPath")
+	require.True(t, ok)
+	require.NotNil(t, step.GetOperation())
+	assert.Equal(t, "/pet/{petId}", step.GetOperation().Path)
+	assert.Equal(t, HTTPMethod(MethodGet), step.GetOperation().Method)
+	assert.Equal(
+		t,
+		"petstore",
+		step.GetOperation().Document.GetName(),
+	)
+
+	tests := []struct {
+		step     models.Step
+		expected string
+	}{
+		{
+			models.Step{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("getPetById"),
+			},
+			`step "getPet": operation "getPetById" is ambiguous, it is ` +
+				`defined by the source descriptions "petstore", "mirror"`,
+		},
+		{
+			models.Step{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("missing"),
+			},
+			`step "getPet": operation "missing" not found`,
+		},
+		{
+			models.Step{
+				StepId: "getPet",
+				OperationId: testutil.Ptr(
+					"$sourceDescriptions.mirror.missing",
+				),
+			},
+			`step "getPet": operation "missing" not found in source description "mirror"`,
+		},
+		{
+			models.Step{
+				StepId: "getPet",
+				OperationId: testutil.Ptr(
+					"$sourceDescriptions.shared.getPetById",
+				),
+			},
+			`step "getPet": operation "$sourceDescriptions.shared.getPetById": ` +
+				`source description "shared" is not an openapi document`,
+		},
+		{
+			models.Step{
+				StepId: "getPet",
+				OperationPath: testutil.Ptr(
+					"{$sourceDescriptions.missing.url}#/paths/~1pet/post",
+				),
+			},
+			`step "getPet": operation path "{$sourceDescriptions.missing.url}#/paths/~1pet/post": ` +
+				`source description "missing" not found`,
+		},
+		{
+			models.Step{
+				StepId: "getPet",
+				OperationPath: testutil.Ptr(
+					"{$sourceDescriptions.petstore.url}#/paths/~1pet",
+				),
+			},
+			`step "getPet": operation path "{$sourceDescriptions.petstore.url}#/paths/~1pet": ` +
+				`json pointer "/paths/~1pet" does not reference an operation`,
+		},
+		{
+			models.Step{
+				StepId: "getPet",
+				OperationPath: testutil.Ptr(
+					"{$sourceDescriptions.petstore.url}#/paths/~1pet/delete",
+				),
+			},
+			`step "getPet": operation path "{$sourceDescriptions.petstore.url}#/paths/~1pet/delete": ` +
+				`operation not found`,
+		},
+		{
+			models.Step{
+				StepId:        "getPet",
+				OperationPath: testutil.Ptr("#/paths/~1pet/post"),
+			},
+			`step "getPet": operation path "#/paths/~1pet/post": ` +
+				`expected {$sourceDescriptions.<name>.url}#<json pointer>`,
+		},
+		{
+			models.Step{
+				StepId: "getPet",
+				OperationId: testutil.Ptr(
+					"$sourceDescriptions.petstore.addPet",
+				),
+				OperationPath: testutil.Ptr(
+					"{$sourceDescriptions.petstore.url}#/paths/~1pet/post",
+				),
+			},
+			`step "getPet": operationId, operationPath and workflowId are mutually exclusive`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			_, err := NewSpec(
+				newModel(tt.step),
+				"petstore.arazzo.yaml",
+			)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestNewSpec_RemoteSources(t *testing.T) {
+	openapi, err := os.ReadFile("test_specs/petstore.openapi.yaml")
+	require.NoError(t, err)
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			_, _ = w.Write(openapi)
+		},
+	))
+	defer server.Close()
+
+	model := testutil.NewSpecModel(petstoreOpenAPI, models.Workflow{
+		WorkflowId: "getPet",
+		Steps: []models.Step{
+			{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("getPetById"),
+			},
+		},
+	})
+	model.SourcesDescriptions[0].Url = "../apis/petstore.openapi.yaml"
+	specURL := server.URL + "/specs/petstore.arazzo.yaml"
+
+	_, err = NewSpec(model, specURL)
+	assert.EqualError(
+		t,
+		err,
+		`source description "petstore": failed to load "`+server.URL+
+			`/apis/petstore.openapi.yaml": remote file lookup is not allowed`,
+	)
+
+	config := DefaultOAIDocumentConfiguration()
+	config.AllowRemoteReferences = false
+	spec, err := NewSpec(
+		model,
+		specURL,
+		WithLoader(arazzo.NewLoader(
+			arazzo.AllowRemoteLookup(),
+			arazzo.WithHTTPClient(server.Client()),
+		)),
+		WithDocumentConfiguration(config),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/apis/petstore.openapi.yaml"}, paths)
+	doc, ok := spec.GetOAIDocument("petstore")
+	require.True(t, ok)
+	assert.Len(t, doc.GetOperations(), 4)
+}
+
+func TestNewSpec_FSSources(t *testing.T) {
+	openapi, err := os.ReadFile("test_specs/petstore.openapi.yaml")
+	require.NoError(t, err)
+	model := testutil.NewSpecModel(petstoreOpenAPI, models.Workflow{
+		WorkflowId: "getPet",
+		Steps: []models.Step{
+			{
+				StepId:      "getPet",
+				OperationId: testutil.Ptr("getPetById"),
+			},
+		},
+	})
+	model.SourcesDescriptions[0].Url = "../apis/petstore.openapi.yaml"
+
+	tests := []struct {
+		name   string
+		loader *arazzo.Loader
+	}{
+		{
+			name: "fs",
+			loader: arazzo.NewLoader(
+				arazzo.AllowLocalLookup(),
+				arazzo.WithFS(fstest.MapFS{
+					"apis/petstore.openapi.yaml": {Data: openapi},
+				}),
+			),
+		},
+		{
+			name: "documents",
+			loader: arazzo.NewLoader(arazzo.WithDocuments(
+				map[string][]byte{
+					"/apis/petstore.openapi.yaml": openapi,
+				},
+			)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := NewSpec(
+				model,
+				"/specs/petstore.arazzo.yaml",
+				WithLoader(tt.loader),
+			)
+			require.NoError(t, err)
+			doc, ok := spec.GetOAIDocument("petstore")
+			require.True(t, ok)
+			assert.Len(t, doc.GetOperations(), 4)
+		})
+	}
+}
+
+func TestNewSpec_ArazzoSources(t *testing.T) {
+	arazzoSource := func(name string, url string) models.SourceDescription {
+		return models.SourceDescription{
+			Name: name,
+			Url:  url,
+			Type: models.SourceDescriptionTypeArazzo.ToPtr(),
+		}
+	}
+	workflows := []models.Workflow{{
+		WorkflowId: "main",
+		Steps: []models.Step{
+			{StepId: "login", OperationId: testutil.Ptr("loginUser")},
+		},
+	}}
+
+	model := testutil.NewSpecModel(petstoreOpenAPI, workflows...)
+	model.SourcesDescriptions = append(
+		model.SourcesDescriptions,
+		arazzoSource("one", "test_specs/shared.arazzo.yaml"),
+		arazzoSource(
+			"two",
+			"./test_specs/../test_specs/shared.arazzo.yaml",
+		),
+	)
+	spec, err := NewSpec(model, "petstore.arazzo.yaml")
+	require.NoError(t, err)
+	one, ok := spec.GetArazzoDocument("one")
+	require.True(t, ok)
+	two, ok := spec.GetArazzoDocument("two")
+	require.True(t, ok)
+	assert.Same(t, one, two)
+	assert.Equal(t, "test_specs/shared.arazzo.yaml", one.GetURL())
+	_, ok = one.GetOAIDocument("petstore")
+	assert.True(t, ok)
+
+	model = testutil.NewSpecModel(petstoreOpenAPI, workflows...)
+	model.SourcesDescriptions = append(
+		model.SourcesDescriptions,
+		arazzoSource("a", "test_specs/cycle_a.arazzo.yaml"),
+	)
+	_, err = NewSpec(model, "petstore.arazzo.yaml")
+	assert.ErrorContains(
+		t,
+		err,
+		"circular reference: test_specs/cycle_a.arazzo.yaml -> "+
+			"test_specs/cycle_b.arazzo.yaml -> test_specs/cycle_a.arazzo.yaml",
+	)
+}
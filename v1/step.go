@@ -1,6 +1,11 @@
 package v1
 
-import "github.com/bragdonD/arazzo-go/v1/models"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bragdonD/arazzo-go/v1/models"
+)
 
 // Step is a struct that represents an Arazzo specification 1.0.X step
 // object.
@@ -19,7 +24,7 @@ type Step struct {
 	successCriteria []*Criterion
 	onSuccess       []*SuccessAction
 	onFailure       []*FailureAction
-	outputs         map[string]any
+	outputs         map[string]*Value
 }
 
 func NewStep(model *models.Step, parent *Workflow) (*Step, error) {
@@ -32,7 +37,7 @@ func NewStep(model *models.Step, parent *Workflow) (*Step, error) {
 		successCriteria: []*Criterion{},
 		onSuccess:       []*SuccessAction{},
 		onFailure:       []*FailureAction{},
-		outputs:         map[string]any{},
+		outputs:         map[string]*Value{},
 	}
 
 	// Step's parameters can come from three sources:
@@ -62,13 +67,131 @@ func NewStep(model *models.Step, parent *Workflow) (*Step, error) {
 			step.parameters = append(step.parameters, parameter)
 		}
 	}
+	if err := step.checkParameters(); err != nil {
+		return nil, err
+	}
+
+	if err := step.resolveOperation(); err != nil {
+		return nil, fmt.Errorf("step %q: %w", step.id, err)
+	}
+
+	for i := range model.SuccessCriteria {
+		criterion, err := NewCriterion(&model.SuccessCriteria[i])
+		if err != nil {
+			return nil, fmt.Errorf("step %q: %w", step.id, err)
+		}
+		step.successCriteria = append(step.successCriteria, criterion)
+	}
+
+	for i := range model.OnSuccess {
+		actionModel, err := model.OnSuccess[i].ToSuccessAction(
+			parent.GetParent().GetComponents().GetModel(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("step %q: %w", step.id, err)
+		}
+		action, err := NewSuccessAction(actionModel)
+		if err != nil {
+			return nil, fmt.Errorf("step %q: %w", step.id, err)
+		}
+		step.onSuccess = append(step.onSuccess, action)
+	}
+
+	for i := range model.OnFailure {
+		actionModel, err := model.OnFailure[i].ToFailureAction(
+			parent.GetParent().GetComponents().GetModel(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("step %q: %w", step.id, err)
+		}
+		action, err := NewFailureAction(actionModel)
+		if err != nil {
+			return nil, fmt.Errorf("step %q: %w", step.id, err)
+		}
+		step.onFailure = append(step.onFailure, action)
+	}
+
+	if model.RequestBody != nil {
+		requestBody, err := NewRequestBody(model.RequestBody)
+		if err != nil {
+			return nil, fmt.Errorf("step %q: %w", step.id, err)
+		}
+		step.requestBody = requestBody
+	}
+
+	for name, output := range model.Outputs {
+		step.outputs[name] = NewValue(output)
+	}
 
 	return step, nil
 }
 
+// resolveOperation resolves the OpenAPI operation the step calls, if
+// any. A step calls exactly one operation or workflow.
+func (step *Step) resolveOperation() error {
+	targets := 0
+	for _, target := range []*string{
+		step.model.OperationId,
+		step.model.OperationPath,
+		step.model.WorkflowId,
+	} {
+		if target != nil {
+			targets++
+		}
+	}
+	if targets > 1 {
+		return errors.New(
+			"operationId, operationPath and workflowId are mutually exclusive",
+		)
+	}
+
+	var err error
+	switch {
+	case step.model.OperationId != nil:
+		step.operation, err = step.parent.GetParent().
+			GetOperationById(
+				*step.model.OperationId,
+			)
+	case step.model.OperationPath != nil:
+		step.operation, err = step.parent.GetParent().
+			GetOperationByPath(
+				*step.model.OperationPath,
+			)
+	}
+	return err
+}
+
+// resolveOpWorkflow resolves the workflow the step calls, if any. It
+// may belong to an Arazzo source description.
+func (step *Step) resolveOpWorkflow() error {
+	if step.model.WorkflowId == nil {
+		return nil
+	}
+	workflow, err := step.parent.GetParent().GetWorkflowByReference(
+		*step.model.WorkflowId,
+	)
+	if err != nil {
+		return fmt.Errorf("step %q: %w", step.id, err)
+	}
+	step.opWorkflow = workflow
+	return nil
+}
+
 // checkParameters verifies that parameters are not duplicated
 func (step *Step) checkParameters() error {
-
+	seen := map[string]bool{}
+	for _, param := range step.parameters {
+		key := param.key()
+		if seen[key] {
+			return fmt.Errorf(
+				"step %q: parameter %q is duplicated",
+				step.id,
+				param.GetName(),
+			)
+		}
+		seen[key] = true
+	}
+	return nil
 }
 
 func (s *Step) GetModel() *models.Step {
@@ -78,3 +201,95 @@ func (s *Step) GetModel() *models.Step {
 func (s *Step) GetParent() *Workflow {
 	return s.parent
 }
+
+func (s *Step) GetId() string {
+	return s.id
+}
+
+// GetOperation returns the OpenAPI operation the step calls, or nil
+// if the step does not reference a resolvable operation.
+func (s *Step) GetOperation() *OAIOperation {
+	return s.operation
+}
+
+// GetOpWorkflow returns the workflow the step calls, or nil if the
+// step does not reference a workflow of the spec.
+func (s *Step) GetOpWorkflow() *Workflow {
+	return s.opWorkflow
+}
+
+func (s *Step) GetParameters() []*Parameter {
+	return s.parameters
+}
+
+// GetMergedParameters returns the parameters of the parent workflow
+// overridden by the step's own parameters. A parameter is identified
+// by the combination of its name and location.
+func (s *Step) GetMergedParameters() []*Parameter {
+	merged := []*Parameter{}
+	overridden := map[string]bool{}
+	for _, param := range s.parameters {
+		overridden[param.key()] = true
+	}
+	for _, param := range s.parent.GetParameters() {
+		if !overridden[param.key()] {
+			merged = append(merged, param)
+		}
+	}
+	return append(merged, s.parameters...)
+}
+
+func (s *Step) GetRequestBody() *RequestBody {
+	return s.requestBody
+}
+
+func (s *Step) GetSuccessCriteria() []*Criterion {
+	return s.successCriteria
+}
+
+func (s *Step) GetOnSuccess() []*SuccessAction {
+	return s.onSuccess
+}
+
+// GetMergedSuccessActions returns the success actions of the step
+// followed by the ones of the parent workflow. A workflow action is
+// overridden by a step action with the same name.
+func (s *Step) GetMergedSuccessActions() []*SuccessAction {
+	merged := append([]*SuccessAction{}, s.onSuccess...)
+	overridden := map[string]bool{}
+	for _, action := range s.onSuccess {
+		overridden[action.name] = true
+	}
+	for _, action := range s.parent.GetSuccessActions() {
+		if !overridden[action.name] {
+			merged = append(merged, action)
+		}
+	}
+	return merged
+}
+
+func (s *Step) GetOnFailure() []*FailureAction {
+	return s.onFailure
+}
+
+// GetMergedFailureActions returns the failure actions of the step
+// followed by the ones of the parent workflow. A workflow action is
+// overridden by a step action with the same name, but is never
+// removed otherwise.
+func (s *Step) GetMergedFailureActions() []*FailureAction {
+	merged := append([]*FailureAction{}, s.onFailure...)
+	overridden := map[string]bool{}
+	for _, action := range s.onFailure {
+		overridden[action.name] = true
+	}
+	for _, action := range s.parent.GetFailureActions() {
+		if !overridden[action.name] {
+			merged = append(merged, action)
+		}
+	}
+	return merged
+}
+
+func (s *Step) GetOutputs() map[string]*Value {
+	return s.outputs
+}
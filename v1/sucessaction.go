@@ -1,6 +1,11 @@
 package v1
 
-import "github.com/bragdonD/arazzo-go/v1/models"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bragdonD/arazzo-go/v1/models"
+)
 
 // SuccessAction is a struct that represents an Arazzo specification
 // 1.0.X success action object.
@@ -14,3 +19,115 @@ type SuccessAction struct {
 	stepId     *string
 	criteria   []*Criterion
 }
+
+func NewSuccessAction(
+	model *models.SuccessAction,
+) (*SuccessAction, error) {
+	action := &SuccessAction{
+		model:      model,
+		name:       model.Name,
+		workflowId: model.WorkflowId,
+		stepId:     model.StepId,
+		criteria:   []*Criterion{},
+	}
+
+	var err error
+	switch model.Type {
+	case models.SuccessActionTypeEnd:
+	case models.SuccessActionTypeGoto:
+		err = checkGotoTarget(model.StepId, model.WorkflowId)
+	default:
+		err = fmt.Errorf("unknown type %q", model.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"success action %q: %w",
+			action.name,
+			err,
+		)
+	}
+
+	for i := range model.Criteria {
+		criterion, err := NewCriterion(&model.Criteria[i])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"success action %q: %w",
+				action.name,
+				err,
+			)
+		}
+		action.criteria = append(action.criteria, criterion)
+	}
+
+	return action, nil
+}
+
+// checkGotoTarget verifies that a goto action transfers control to
+// either a step or a workflow.
+func checkGotoTarget(stepId *string, workflowId *string) error {
+	if stepId != nil && workflowId != nil {
+		return errors.New(
+			"stepId and workflowId are mutually exclusive",
+		)
+	}
+	if stepId == nil && workflowId == nil {
+		return errors.New(
+			"a goto action requires a stepId or a workflowId",
+		)
+	}
+	return nil
+}
+
+func (s *SuccessAction) GetModel() *models.SuccessAction {
+	return s.model
+}
+
+func (s *SuccessAction) GetName() string {
+	return s.name
+}
+
+func (s *SuccessAction) GetType() models.SuccessActionType {
+	return s.model.Type
+}
+
+// GetStepId returns the step a goto action transfers control to, or
+// an empty string if it does not target a step.
+func (s *SuccessAction) GetStepId() string {
+	if s.stepId == nil {
+		return ""
+	}
+	return *s.stepId
+}
+
+// GetWorkflowId returns the workflow a goto action transfers control
+// to, or an empty string if it does not target a workflow.
+func (s *SuccessAction) GetWorkflowId() string {
+	if s.workflowId == nil {
+		return ""
+	}
+	return *s.workflowId
+}
+
+func (s *SuccessAction) GetCriteria() []*Criterion {
+	return s.criteria
+}
+
+// Matches reports whether every criterion of the action is satisfied
+// in ctx. An action without criteria always matches.
+func (s *SuccessAction) Matches(ctx *EvalContext) (bool, error) {
+	return matchCriteria(s.criteria, ctx)
+}
+
+// matchCriteria reports whether every criterion is satisfied in ctx.
+func matchCriteria(
+	criteria []*Criterion,
+	ctx *EvalContext,
+) (bool, error) {
+	for _, criterion := range criteria {
+		ok, err := criterion.Evaluate(ctx)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
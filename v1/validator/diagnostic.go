@@ -0,0 +1,202 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const (
+	// RuleSchema reports the values which do not conform to the JSON
+	// Schema of the Arazzo Specification.
+	RuleSchema = "schema"
+	// RuleUnsupportedVersion reports documents of an Arazzo version
+	// no schema is known for.
+	RuleUnsupportedVersion = "unsupported-version"
+	// RuleInvalidVersion reports documents whose Arazzo version is
+	// missing or is not a valid version.
+	RuleInvalidVersion = "invalid-version"
+)
+
+// Severity is the severity of a [Diagnostic].
+type Severity string
+
+const (
+	// SeverityError marks a document which cannot be run as
+	// described.
+	SeverityError Severity = "error"
+	// SeverityWarning marks a document which is likely wrong.
+	SeverityWarning Severity = "warning"
+)
+
+// schemaErrorPrinter prints the messages of the schema validation
+// errors.
+var schemaErrorPrinter = message.NewPrinter(language.English)
+
+// Diagnostic is a problem found in an Arazzo document.
+type Diagnostic struct {
+	// Rule is the ID of the rule reporting the problem.
+	Rule string `json:"rule"`
+	// Severity is the severity of the problem.
+	Severity Severity `json:"severity"`
+	// Path is the JSON pointer of the offending value within the
+	// document.
+	Path string `json:"path"`
+	// Message describes the problem.
+	Message string `json:"message"`
+	// File is the name of the document, empty if unknown.
+	File string `json:"file,omitempty"`
+	// Line is the 1-based line of the offending value, 0 if unknown.
+	Line int `json:"line,omitempty"`
+	// Column is the 1-based column of the offending value, 0 if
+	// unknown.
+	Column int `json:"column,omitempty"`
+}
+
+// Error returns a formatted error message indicating the location of
+// the problem, its description and the rule reporting it.
+func (d Diagnostic) Error() string {
+	return "arazzo-go: " + d.describe()
+}
+
+// describe returns the location of the problem, its description and
+// the rule reporting it.
+func (d Diagnostic) describe() string {
+	var sb strings.Builder
+	if d.File != "" {
+		sb.WriteString(d.File + ":")
+	}
+	if d.Line > 0 {
+		fmt.Fprintf(&sb, "%d:%d:", d.Line, d.Column)
+	}
+	if d.File != "" || d.Line > 0 {
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(string(d.Severity) + ": ")
+	if d.Path != "" {
+		sb.WriteString(d.Path + ": ")
+	}
+	fmt.Fprintf(&sb, "%s (%s)", d.Message, d.Rule)
+	return sb.String()
+}
+
+// InvalidDocumentError is returned when a document has diagnostics of
+// the error severity.
+type InvalidDocumentError struct {
+	// Diagnostics lists every problem found in the document.
+	Diagnostics []Diagnostic
+}
+
+// Error returns a formatted error message listing the problems found
+// in the document, one per line.
+func (e *InvalidDocumentError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("arazzo-go: invalid document:")
+	for _, diagnostic := range e.Diagnostics {
+		sb.WriteString("\n  " + diagnostic.describe())
+	}
+	return sb.String()
+}
+
+// hasErrors reports whether diagnostics holds a diagnostic of the
+// error severity.
+func hasErrors(diagnostics []Diagnostic) bool {
+	return slices.ContainsFunc(diagnostics, func(d Diagnostic) bool {
+		return d.Severity == SeverityError
+	})
+}
+
+// schemaVersionDiagnostics validates value, a decoded JSON document of the
+// given Arazzo version, against the schema of its version.
+func schemaVersionDiagnostics(
+	value any,
+	version string,
+) ([]Diagnostic, error) {
+	schema, err := Schema(version)
+	var versionErr *UnsupportedVersionError
+	if errors.As(err, &versionErr) {
+		return []Diagnostic{{
+			Rule:     RuleUnsupportedVersion,
+			Severity: SeverityError,
+			Path:     "/arazzo",
+			Message: fmt.Sprintf(
+				"unsupported arazzo version %q",
+				version,
+			),
+		}}, nil
+	}
+	var invalidErr *InvalidVersionError
+	if errors.As(err, &invalidErr) {
+		message := fmt.Sprintf("invalid arazzo version %q", version)
+		if version == "" {
+			message = "missing arazzo version"
+		}
+		return []Diagnostic{{
+			Rule:     RuleInvalidVersion,
+			Severity: SeverityError,
+			Path:     "/arazzo",
+			Message:  message,
+		}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return schemaDiagnostics(schema.Validate(value)), nil
+}
+
+// schemaDiagnostics flattens the error returned by the validation of
+// a document against its schema into a diagnostic per invalid value.
+func schemaDiagnostics(err error) []Diagnostic {
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if err == nil {
+		return nil
+	}
+	if !ok {
+		return []Diagnostic{{
+			Rule:     RuleSchema,
+			Severity: SeverityError,
+			Message:  err.Error(),
+		}}
+	}
+	return validationDiagnostics(validationErr)
+}
+
+func validationDiagnostics(
+	err *jsonschema.ValidationError,
+) []Diagnostic {
+	if len(err.Causes) > 0 {
+		diagnostics := []Diagnostic{}
+		for _, cause := range err.Causes {
+			diagnostics = append(
+				diagnostics,
+				validationDiagnostics(cause)...)
+		}
+		return diagnostics
+	}
+	tokens := make([]string, len(err.InstanceLocation))
+	for i, token := range err.InstanceLocation {
+		tokens[i] = "/" + pointerToken(token)
+	}
+	message := err.ErrorKind.LocalizedString(schemaErrorPrinter)
+	// Properties which are not part of the specification are
+	// rejected by a false schema.
+	if _, ok := err.ErrorKind.(*kind.FalseSchema); ok &&
+		len(err.InstanceLocation) > 0 {
+		message = fmt.Sprintf(
+			"unknown property %q",
+			err.InstanceLocation[len(err.InstanceLocation)-1],
+		)
+	}
+	return []Diagnostic{{
+		Rule:     RuleSchema,
+		Severity: SeverityError,
+		Path:     strings.Join(tokens, ""),
+		Message:  message,
+	}}
+}
@@ -0,0 +1,91 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diagnosticTestDocument = `arazzo: 1.0.0
+info:
+  title: test
+  version: 1.0.0
+sourceDescriptions:
+  - name: petstore
+    url: petstore.openapi.yaml
+    type: openapi
+workflows:
+  - workflowId: main
+    steps:
+      - stepId: login
+        operationId: loginUser
+      - stepId: login
+        operationId: loginUser
+        successCriteria:
+          - condition: 200
+  - workflowId: other
+`
+
+func TestValidateDocument(t *testing.T) {
+	diagnostics, err := ValidateDocument(
+		"test.arazzo.yaml",
+		[]byte(diagnosticTestDocument),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []Diagnostic{
+		{
+			Rule:     RuleDuplicateStepId,
+			Severity: SeverityError,
+			Path:     "/workflows/0/steps/1/stepId",
+			Message:  `stepId "login" is already used in workflow "main"`,
+			File:     "test.arazzo.yaml",
+			Line:     14,
+			Column:   9,
+		},
+		{
+			Rule:     RuleSchema,
+			Severity: SeverityError,
+			Path:     "/workflows/0/steps/1/successCriteria/0/condition",
+			Message:  "got number, want string",
+			File:     "test.arazzo.yaml",
+			Line:     17,
+			Column:   13,
+		},
+		{
+			Rule:     RuleSchema,
+			Severity: SeverityError,
+			Path:     "/workflows/1",
+			Message:  "missing property 'steps'",
+			File:     "test.arazzo.yaml",
+			Line:     18,
+			Column:   5,
+		},
+	}, diagnostics)
+
+	_, err = ValidateDocument("test.arazzo.yaml", []byte("a: [b"))
+	assert.ErrorContains(t, err, "failed to parse document")
+}
+
+func TestDiagnostic_Error(t *testing.T) {
+	diagnostic := Diagnostic{
+		Rule:     RuleDuplicateStepId,
+		Severity: SeverityError,
+		Path:     "/workflows/0/steps/1/stepId",
+		Message:  `stepId "login" is already used in workflow "main"`,
+	}
+	assert.EqualError(
+		t,
+		diagnostic,
+		`arazzo-go: error: /workflows/0/steps/1/stepId: stepId "login" is already used in workflow "main" (duplicate-step-id)`,
+	)
+
+	diagnostic.File = "test.arazzo.yaml"
+	diagnostic.Line = 14
+	diagnostic.Column = 9
+	assert.EqualError(
+		t,
+		diagnostic,
+		`arazzo-go: test.arazzo.yaml:14:9: error: /workflows/0/steps/1/stepId: stepId "login" is already used in workflow "main" (duplicate-step-id)`,
+	)
+}
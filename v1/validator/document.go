@@ -1,38 +1,156 @@
 package validator
 
 import (
+	"bytes"
 	"encoding/json"
-	"strings"
+	"errors"
+	"fmt"
+	"slices"
 
 	arazzo "github.com/bragdonD/arazzo-go/v1"
-	"github.com/bragdonD/arazzo-go/v1/validator/helpers"
+	"github.com/bragdonD/arazzo-go/v1/models"
 	"github.com/santhosh-tekuri/jsonschema/v6"
 )
 
-// ValidateArazzoDocument will validate an Arazzo [Spec] against the
-// Arazzo 1.0 schemas (depending on version). It will return true if
-// the document is valid, false if it is not and a slice of
-// [ValidationError] pointers.
+// ValidateArazzoDocument will validate the model of an Arazzo [Spec]
+// with [ValidateModel]. It will return true if the document has no
+// diagnostic of the error severity, false if it has and a [Diagnostic]
+// per problem found. A nil doc, or one without a model, is reported
+// as invalid with an error.
 func ValidateArazzoDocument(doc *arazzo.Spec) (bool, []error) {
-	compiler := jsonschema.NewCompiler()
-	compiler.UseLoader(helpers.NewCompilerLoader())
-
-	jsch, err := compiler.Compile("./schemas/schemav1_0.json")
+	if doc == nil {
+		return false, []error{errors.New("arazzo-go: spec is nil")}
+	}
+	if doc.GetModel() == nil {
+		return false, []error{
+			errors.New("arazzo-go: spec has no model"),
+		}
+	}
+	diagnostics, err := ValidateModel(doc.GetModel())
 	if err != nil {
 		return false, []error{err}
 	}
+	errs := make([]error, len(diagnostics))
+	for i, diagnostic := range diagnostics {
+		errs[i] = diagnostic
+	}
+	if len(errs) == 0 {
+		errs = nil
+	}
+	return !hasErrors(diagnostics), errs
+}
 
-	loadedSchema, err := json.Marshal(doc)
+// ValidateDocument validates the YAML or JSON Arazzo document data
+// against the JSON Schema of its Arazzo version and then with
+// [ValidateSemantics]. The schema validates the document as it is
+// written, before it is mapped to a model, so that unknown fields and
+// values of the wrong type are reported.
+//
+// Every diagnostic is located within the document, file being the
+// name it is reported with. Diagnostics are sorted by position. An
+// error is returned if data cannot be parsed.
+func ValidateDocument(
+	file string,
+	data []byte,
+) ([]Diagnostic, error) {
+	doc, err := models.ParseDocument(data)
 	if err != nil {
-		return false, []error{err}
+		return nil, err
+	}
+	value, err := doc.Value()
+	if err != nil {
+		return nil, err
+	}
+	// A version which is not a string, e.g. `arazzo: 1` in YAML, is
+	// reported as invalid rather than missing.
+	object, _ := value.(map[string]any)
+	version := ""
+	if arazzoVersion := object["arazzo"]; arazzoVersion != nil {
+		version = fmt.Sprint(arazzoVersion)
+	}
+	diagnostics, err := schemaVersionDiagnostics(value, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Semantic rules only apply to documents which can be mapped to a
+	// model, the schema diagnostics reporting the others.
+	if !slices.ContainsFunc(diagnostics, func(d Diagnostic) bool {
+		return d.Rule == RuleUnsupportedVersion ||
+			d.Rule == RuleInvalidVersion
+	}) {
+		if spec, err := doc.Spec(); err == nil {
+			diagnostics = append(
+				diagnostics,
+				ValidateSemantics(spec)...)
+		}
 	}
+	return locate(doc, file, diagnostics), nil
+}
 
-	decodedSchema, _ := jsonschema.UnmarshalJSON(strings.NewReader(string(loadedSchema)))
+// ValidateModel validates model against the JSON Schema of its Arazzo
+// version and then with [ValidateSemantics]. Diagnostics have no
+// position, as a model does not keep track of where it was read from.
+func ValidateModel(model *models.Spec) ([]Diagnostic, error) {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	diagnostics, err := schemaVersionDiagnostics(value, model.Arazzo)
+	if err != nil {
+		return nil, err
+	}
+	return append(diagnostics, ValidateSemantics(model)...), nil
+}
 
-	scErrs := jsch.Validate(decodedSchema)
+// LoadSpec validates the YAML or JSON Arazzo document data with
+// [ValidateDocument] and only then maps it to a model, so that a
+// document which does not conform to its schema is rejected rather
+// than partially mapped. An [InvalidDocumentError] is returned if the
+// document has diagnostics of the error severity, the remaining
+// diagnostics being returned along with the model.
+func LoadSpec(
+	file string,
+	data []byte,
+) (*models.Spec, []Diagnostic, error) {
+	diagnostics, err := ValidateDocument(file, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hasErrors(diagnostics) {
+		return nil, nil, &InvalidDocumentError{
+			Diagnostics: diagnostics,
+		}
+	}
+	spec, err := models.ExtractSpecWithDocumentCheck(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return spec, diagnostics, nil
+}
 
-	if scErrs != nil {
-		return false, []error{scErrs}
+// locate sets the location of diagnostics within doc, named file, and
+// sorts them by position.
+func locate(
+	doc *models.Document,
+	file string,
+	diagnostics []Diagnostic,
+) []Diagnostic {
+	for i := range diagnostics {
+		diagnostics[i].File = file
+		diagnostics[i].Line, diagnostics[i].Column = doc.Position(
+			diagnostics[i].Path,
+		)
 	}
-	return true, nil
+	slices.SortStableFunc(diagnostics, func(a, b Diagnostic) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return diagnostics
 }
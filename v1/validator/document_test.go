@@ -1,47 +1,171 @@
 package validator
 
 import (
-	"encoding/json"
-	"fmt"
 	"os"
 	"testing"
 
+	"github.com/bragdonD/arazzo-go/internal/testutil"
 	v1 "github.com/bragdonD/arazzo-go/v1"
+	"github.com/bragdonD/arazzo-go/v1/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidateArazzoDocument(t *testing.T) {
-	petstore, err := os.ReadFile("../test_specs/petstore.arazzo.json")
-	if err != nil {
-		t.Fatalf("failed to read petstore spec: %v", err)
-	}
-
-	var spec v1.Spec
-	if err := json.Unmarshal([]byte(petstore), &spec); err != nil {
-		t.Fatalf("could not unmarshal the test's data: %v", err)
-	}
+	data, err := os.ReadFile("../test_specs/shared.arazzo.yaml")
+	require.NoError(t, err)
+	model, err := models.ExtractSpecWithDocumentCheck(data)
+	require.NoError(t, err)
+	spec, err := v1.NewSpec(model, "../test_specs/shared.arazzo.yaml")
+	require.NoError(t, err)
 
-	valid, errs := ValidateArazzoDocument(&spec)
+	valid, errs := ValidateArazzoDocument(spec)
 
 	assert.True(t, valid)
 	assert.Empty(t, errs)
 }
 
 func TestValidateArazzoDocument_Invalid(t *testing.T) {
-	petstore, err := os.ReadFile("../test_specs/invalid.arazzo.json")
-	if err != nil {
-		t.Fatalf("failed to read invalid spec: %v", err)
+	tests := []struct {
+		name string
+		spec *v1.Spec
+	}{
+		{name: "no model", spec: &v1.Spec{}},
+		{name: "nil", spec: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, errs := ValidateArazzoDocument(tt.spec)
+
+			assert.False(t, valid)
+			assert.NotEmpty(t, errs)
+		})
 	}
+}
 
-	var spec v1.Spec
-	if err := json.Unmarshal([]byte(petstore), &spec); err != nil {
-		t.Fatalf("could not unmarshal the test's data: %v", err)
+// The invalid fixture cannot be built into a [v1.Spec], its steps
+// referencing operations of a source description it does not declare,
+// so its model is validated instead.
+func TestValidateModel_InvalidFixture(t *testing.T) {
+	data, err := os.ReadFile("../test_specs/invalid.arazzo.json")
+	require.NoError(t, err)
+	model, err := models.ExtractSpecWithDocumentCheck(data)
+	require.NoError(t, err)
+
+	diagnostics, err := ValidateModel(model)
+	require.NoError(t, err)
+	var got []string
+	for _, diagnostic := range diagnostics {
+		got = append(got, diagnostic.Error())
 	}
+	// The model always has an info object, unlike the document.
+	assert.Equal(t, []string{
+		"arazzo-go: error: /sourceDescriptions: minItems: got 0, want 1 (schema)",
+	}, got)
+}
 
-	valid, errs := ValidateArazzoDocument(&spec)
+func TestValidateDocument_TestSpecs(t *testing.T) {
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{file: "../test_specs/petstore.arazzo.json"},
+		{file: "../test_specs/shared.arazzo.yaml"},
+		{
+			file: "../test_specs/invalid.arazzo.json",
+			want: []string{
+				"arazzo-go: ../test_specs/invalid.arazzo.json:1:1: error: missing property 'info' (schema)",
+				"arazzo-go: ../test_specs/invalid.arazzo.json:3:5: error: /sourceDescriptions: minItems: got 0, want 1 (schema)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(tt.file)
+			require.NoError(t, err)
+			diagnostics, err := ValidateDocument(tt.file, data)
+			require.NoError(t, err)
+			var got []string
+			for _, diagnostic := range diagnostics {
+				got = append(got, diagnostic.Error())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateModel(t *testing.T) {
+	model := &models.Spec{
+		Arazzo: "1.0.0",
+		Info:   models.Info{Title: "test", Version: "1.0.0"},
+		Workflows: []models.Workflow{{
+			WorkflowId: "main",
+			Steps: []models.Step{
+				{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+				},
+				{
+					StepId:      "login",
+					OperationId: testutil.Ptr("logoutUser"),
+				},
+			},
+		}},
+	}
+
+	diagnostics, err := ValidateModel(model)
+	require.NoError(t, err)
+	assert.Equal(t, []Diagnostic{
+		{
+			Rule:     RuleSchema,
+			Severity: SeverityError,
+			Path:     "/sourceDescriptions",
+			Message:  "got null, want array",
+		},
+		{
+			Rule:     RuleDuplicateStepId,
+			Severity: SeverityError,
+			Path:     "/workflows/0/steps/1/stepId",
+			Message:  `stepId "login" is already used in workflow "main"`,
+		},
+	}, diagnostics)
+}
 
-	fmt.Println(errs)
+func TestLoadSpec(t *testing.T) {
+	const document = `arazzo: 1.0.0
+info:
+  title: test
+  version: 1.0.0
+sourceDescriptions:
+  - name: petstore
+    url: petstore.openapi.yaml
+workflows:
+  - workflowId: main
+    steps:
+      - stepId: login
+        operationID: loginUser
+`
+	_, _, err := LoadSpec("test.arazzo.yaml", []byte(document))
+	var invalidErr *InvalidDocumentError
+	require.ErrorAs(t, err, &invalidErr)
+	assert.Equal(t, `arazzo-go: invalid document:
+  test.arazzo.yaml:11:9: error: /workflows/0/steps/0: missing property 'operationId' (schema)
+  test.arazzo.yaml:11:9: error: /workflows/0/steps/0: missing property 'operationPath' (schema)
+  test.arazzo.yaml:11:9: error: /workflows/0/steps/0: missing property 'workflowId' (schema)
+  test.arazzo.yaml:12:9: error: /workflows/0/steps/0/operationID: unknown property "operationID" (schema)`, err.Error())
 
-	assert.False(t, valid)
-	assert.NotEmpty(t, errs)
+	spec, diagnostics, err := LoadSpec(
+		"test.arazzo.yaml",
+		[]byte(
+			document[:len(document)-len("        operationID: loginUser\n")]+
+				"        operationId: loginUser\n",
+		),
+	)
+	require.NoError(t, err)
+	assert.Empty(t, diagnostics)
+	assert.Equal(
+		t,
+		"loginUser",
+		*spec.Workflows[0].Steps[0].OperationId,
+	)
 }
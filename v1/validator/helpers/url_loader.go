@@ -1,16 +1,12 @@
 package helpers
 
 import (
+	"bytes"
 	"crypto/tls"
-	"fmt"
-	"io"
 	"net/http"
-	"net/url"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
+	arazzo "github.com/bragdonD/arazzo-go"
 	"github.com/santhosh-tekuri/jsonschema/v6"
 )
 
@@ -24,16 +20,18 @@ type URLLoader interface {
 	Load(url string) (any, error)
 }
 
+// HTTPURLLoader loads JSON schemas through an [arazzo.Loader], so that
+// schemas follow the same I/O policy as the documents they validate.
 type HTTPURLLoader struct {
-	httpClient  *http.Client
-	httpsClient *http.Client
+	httpClient *http.Client
+	loader     *arazzo.Loader
 }
 
 type HTTPURLLoaderOption func(*HTTPURLLoader)
 
 func WithHTTPInsecureSkipVerify() HTTPURLLoaderOption {
 	return func(h *HTTPURLLoader) {
-		h.httpsClient.Transport = &http.Transport{
+		h.httpClient.Transport = &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 		}
 	}
@@ -41,51 +39,27 @@ func WithHTTPInsecureSkipVerify() HTTPURLLoaderOption {
 
 func WithHTTPSInsecureSkipVerify() HTTPURLLoaderOption {
 	return func(h *HTTPURLLoader) {
-		h.httpsClient.Transport = &http.Transport{
+		h.httpClient.Transport = &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 		}
 	}
 }
 
-func (l *HTTPURLLoader) Load(rawUrl string) (any, error) {
-	parsedURL, err := url.Parse(rawUrl)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse URL: %v", err)
+// WithLoader loads the schemas with loader instead of a loader
+// allowing both local and remote lookups. The HTTP client options are
+// ignored, as loader has its own.
+func WithLoader(loader *arazzo.Loader) HTTPURLLoaderOption {
+	return func(h *HTTPURLLoader) {
+		h.loader = loader
 	}
-	var reader io.Reader
-
-	// Check if the URL represents a local file.
-	// A local file is identified by:
-	// - Having no scheme (e.g., "myfile.yaml",
-	// "/home/user/myfile.yaml")
-	// - Using the "file" scheme (e.g., "file:///home/user/file.yaml")
-	if parsedURL.Scheme == "" || parsedURL.Scheme == FileScheme {
-		path := filepath.FromSlash(parsedURL.Path)
-		reader, err = os.Open(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open file: %v", err)
-		}
-	} else {
-		var client *http.Client
-		if strings.HasPrefix(rawUrl, HttpsScheme) {
-			client = (*http.Client)(l.httpsClient)
-		} else {
-			client = (*http.Client)(l.httpClient)
-		}
+}
 
-		resp, err := client.Get(rawUrl)
-		if err != nil {
-			return nil, err
-		}
-		if resp.StatusCode != http.StatusOK {
-			_ = resp.Body.Close()
-			return nil, fmt.Errorf("%s returned status code %d", rawUrl, resp.StatusCode)
-		}
-		defer resp.Body.Close()
-		reader = resp.Body
+func (l *HTTPURLLoader) Load(rawUrl string) (any, error) {
+	data, err := l.loader.LoadFile(rawUrl)
+	if err != nil {
+		return nil, err
 	}
-
-	return jsonschema.UnmarshalJSON(reader)
+	return jsonschema.UnmarshalJSON(bytes.NewReader(data))
 }
 
 func NewHTTPURLLoader(opts ...HTTPURLLoaderOption) *HTTPURLLoader {
@@ -93,20 +67,27 @@ func NewHTTPURLLoader(opts ...HTTPURLLoaderOption) *HTTPURLLoader {
 		httpClient: &http.Client{
 			Timeout: 15 * time.Second,
 		},
-		httpsClient: &http.Client{
-			Timeout: 15 * time.Second,
-		},
 	}
 	for _, opt := range opts {
 		opt(httpLoader)
 	}
+	if httpLoader.loader == nil {
+		httpLoader.loader = arazzo.NewLoader(
+			arazzo.WithHTTPClient(httpLoader.httpClient),
+			arazzo.AllowRemoteLookup(),
+			arazzo.AllowLocalLookup(),
+		)
+	}
 	return httpLoader
 }
 
-func NewCompilerLoader(opts ...HTTPURLLoaderOption) jsonschema.SchemeURLLoader {
+func NewCompilerLoader(
+	opts ...HTTPURLLoaderOption,
+) jsonschema.SchemeURLLoader {
+	loader := NewHTTPURLLoader(opts...)
 	return jsonschema.SchemeURLLoader{
-		"file":  jsonschema.FileLoader{},
-		"http":  NewHTTPURLLoader(opts...),
-		"https": NewHTTPURLLoader(opts...),
+		FileScheme: loader,
+		"http":     loader,
+		"https":    loader,
 	}
 }
@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// schemaFS holds the JSON Schemas of the Arazzo Specification.
+//
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// schemaVersionRegex matches the major and minor versions of an
+// Arazzo version.
+var schemaVersionRegex = regexp.MustCompile(`^(\d+\.\d+)\.\d+(-.+)?$`)
+
+// schemas holds the compiled schema of every supported Arazzo
+// version, by major and minor version. Schemas are compiled once, on
+// first use.
+//
+// Only Arazzo 1.0 is supported. Documents of any other version, such
+// as 1.1, are reported as unsupported until the schema of that version
+// is embedded and registered here.
+var schemas = map[string]func() (*jsonschema.Schema, error){
+	"1.0": compileSchemaOnce("schemas/schemav1_0.json"),
+}
+
+// UnsupportedVersionError is returned when no schema is known for the
+// Arazzo version of a document.
+type UnsupportedVersionError struct {
+	// Version is the Arazzo version of the document.
+	Version string
+}
+
+// Error returns a formatted error message indicating the unsupported
+// version.
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf(
+		"arazzo-go: unsupported arazzo version %q",
+		e.Version,
+	)
+}
+
+// InvalidVersionError is returned when the Arazzo version of a
+// document is not a valid version, i.e. major.minor.patch with an
+// optional pre-release suffix.
+type InvalidVersionError struct {
+	// Version is the Arazzo version of the document.
+	Version string
+}
+
+// Error returns a formatted error message indicating the invalid
+// version.
+func (e *InvalidVersionError) Error() string {
+	return fmt.Sprintf(
+		"arazzo-go: invalid arazzo version %q",
+		e.Version,
+	)
+}
+
+// Schema returns the compiled JSON Schema of the Arazzo documents of
+// the given version, such as 1.0.1. Patch versions share the schema of
+// their minor version. An [InvalidVersionError] is returned if version
+// is not a valid Arazzo version and an [UnsupportedVersionError] if no
+// schema is known for it.
+func Schema(version string) (*jsonschema.Schema, error) {
+	match := schemaVersionRegex.FindStringSubmatch(version)
+	if match == nil {
+		return nil, &InvalidVersionError{Version: version}
+	}
+	compile, ok := schemas[match[1]]
+	if !ok {
+		return nil, &UnsupportedVersionError{Version: version}
+	}
+	return compile()
+}
+
+// compileSchemaOnce returns a function compiling the embedded schema
+// file on its first call and returning it afterwards.
+func compileSchemaOnce(
+	file string,
+) func() (*jsonschema.Schema, error) {
+	return sync.OnceValues(func() (*jsonschema.Schema, error) {
+		data, err := schemaFS.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema: %w", err)
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode schema: %w", err)
+		}
+		url := "arazzo-go://" + file
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource(url, doc); err != nil {
+			return nil, fmt.Errorf(
+				"failed to compile schema: %w",
+				err,
+			)
+		}
+		return compiler.Compile(url)
+	})
+}
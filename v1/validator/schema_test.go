@@ -0,0 +1,128 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	// The schemas are embedded, they do not depend on the working
+	// directory.
+	t.Chdir(t.TempDir())
+
+	schema, err := Schema("1.0.0")
+	require.NoError(t, err)
+	for _, version := range []string{"1.0.1", "1.0.0-rc1"} {
+		other, err := Schema(version)
+		require.NoError(t, err)
+		assert.Same(t, schema, other, version)
+	}
+
+	_, err = Schema("1.1.0")
+	var versionErr *UnsupportedVersionError
+	require.ErrorAs(t, err, &versionErr)
+	assert.EqualError(
+		t,
+		err,
+		`arazzo-go: unsupported arazzo version "1.1.0"`,
+	)
+
+	for _, version := range []string{"", "v1", "1.0", "1.0.0.0"} {
+		_, err := Schema(version)
+		var invalidErr *InvalidVersionError
+		assert.ErrorAs(t, err, &invalidErr, version)
+	}
+}
+
+func TestValidateDocument_UnsupportedVersion(t *testing.T) {
+	diagnostics, err := ValidateDocument(
+		"test.arazzo.yaml",
+		[]byte("info: {}\narazzo: 2.0.0\n"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []Diagnostic{{
+		Rule:     RuleUnsupportedVersion,
+		Severity: SeverityError,
+		Path:     "/arazzo",
+		Message:  `unsupported arazzo version "2.0.0"`,
+		File:     "test.arazzo.yaml",
+		Line:     2,
+		Column:   1,
+	}}, diagnostics)
+
+	diagnostics, err = ValidateDocument(
+		"test.arazzo.yaml",
+		[]byte("info: {}\narazzo: 1.1.0\n"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []Diagnostic{{
+		Rule:     RuleUnsupportedVersion,
+		Severity: SeverityError,
+		Path:     "/arazzo",
+		Message:  `unsupported arazzo version "1.1.0"`,
+		File:     "test.arazzo.yaml",
+		Line:     2,
+		Column:   1,
+	}}, diagnostics)
+}
+
+func TestValidateDocument_InvalidVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected Diagnostic
+	}{
+		{
+			name: "invalid",
+			data: "info: {}\narazzo: v1\n",
+			expected: Diagnostic{
+				Rule:     RuleInvalidVersion,
+				Severity: SeverityError,
+				Path:     "/arazzo",
+				Message:  `invalid arazzo version "v1"`,
+				File:     "test.arazzo.yaml",
+				Line:     2,
+				Column:   1,
+			},
+		},
+		{
+			name: "number",
+			data: "arazzo: 1\n",
+			expected: Diagnostic{
+				Rule:     RuleInvalidVersion,
+				Severity: SeverityError,
+				Path:     "/arazzo",
+				Message:  `invalid arazzo version "1"`,
+				File:     "test.arazzo.yaml",
+				Line:     1,
+				Column:   1,
+			},
+		},
+		{
+			name: "missing",
+			data: "- a\n",
+			expected: Diagnostic{
+				Rule:     RuleInvalidVersion,
+				Severity: SeverityError,
+				Path:     "/arazzo",
+				Message:  "missing arazzo version",
+				File:     "test.arazzo.yaml",
+				Line:     1,
+				Column:   1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics, err := ValidateDocument(
+				"test.arazzo.yaml",
+				[]byte(tt.data),
+			)
+			require.NoError(t, err)
+			assert.Equal(t, []Diagnostic{tt.expected}, diagnostics)
+		})
+	}
+}
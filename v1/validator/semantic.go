@@ -0,0 +1,708 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	arazzo "github.com/bragdonD/arazzo-go/v1"
+	"github.com/bragdonD/arazzo-go/v1/expression"
+	"github.com/bragdonD/arazzo-go/v1/models"
+)
+
+// Rule IDs of the semantic validation, see [ValidateSemantics].
+const (
+	// RuleDuplicateWorkflowId reports workflows sharing a workflowId.
+	RuleDuplicateWorkflowId = "duplicate-workflow-id"
+	// RuleDuplicateStepId reports steps of a workflow sharing a
+	// stepId.
+	RuleDuplicateStepId = "duplicate-step-id"
+	// RuleGotoTargetNotFound reports goto and retry actions whose
+	// step or workflow does not exist.
+	RuleGotoTargetNotFound = "goto-target-not-found"
+	// RuleGotoTargetConflict reports goto and retry actions setting
+	// both a stepId and a workflowId, and goto actions setting none.
+	RuleGotoTargetConflict = "goto-target-conflict"
+	// RuleStepOperationConflict reports steps setting more than one of
+	// operationId, operationPath and workflowId.
+	RuleStepOperationConflict = "step-operation-conflict"
+	// RuleComponentNotFound reports references to components which do
+	// not exist.
+	RuleComponentNotFound = "component-not-found"
+	// RuleStepForwardReference reports $steps expressions referring
+	// to the step they belong to or to a step declared later on in
+	// the workflow.
+	RuleStepForwardReference = "step-forward-reference"
+	// RuleDuplicateParameter reports parameters sharing a name and a
+	// location.
+	RuleDuplicateParameter = "duplicate-parameter"
+	// RuleInvalidOutputName reports output names not matching
+	// ^[a-zA-Z0-9\.\-_]+$.
+	RuleInvalidOutputName = "invalid-output-name"
+)
+
+var (
+	// outputNameRegex is the pattern output names must match.
+	outputNameRegex = regexp.MustCompile(`^[a-zA-Z0-9\.\-_]+$`)
+	// stepsRegex matches the step referenced by a $steps expression.
+	stepsRegex = regexp.MustCompile(
+		regexp.QuoteMeta(expression.ABNFExpressionSteps) +
+			`([A-Za-z0-9_\-]+)`,
+	)
+	// componentsRegex matches the kind and the name of the component
+	// referenced by a $components expression.
+	componentsRegex = regexp.MustCompile(
+		regexp.QuoteMeta(expression.ABNFExpressionComponents) +
+			`(inputs|parameters|successActions|failureActions)\.([A-Za-z0-9_.\-]+)`,
+	)
+)
+
+// componentsInputsRef is the prefix of the JSON schema references to
+// the inputs of the components.
+const componentsInputsRef = "#/components/inputs/"
+
+// ValidateSpec validates spec with [ValidateSemantics].
+func ValidateSpec(spec *arazzo.Spec) []Diagnostic {
+	return ValidateSemantics(spec.GetModel())
+}
+
+// ValidateSemantics reports the problems of an Arazzo document which
+// its JSON Schema cannot catch, such as duplicate identifiers or
+// references to steps, workflows and components which do not exist.
+// The diagnostics are returned in document order.
+//
+// It only relies on the model of the document, so that documents
+// rejected by [arazzo.NewSpec] can be diagnosed.
+func ValidateSemantics(model *models.Spec) []Diagnostic {
+	v := &semanticValidator{model: model}
+	v.validate()
+	return v.diagnostics
+}
+
+// semanticValidator collects the diagnostics of a document.
+type semanticValidator struct {
+	model       *models.Spec
+	diagnostics []Diagnostic
+}
+
+func (v *semanticValidator) report(
+	rule string,
+	severity Severity,
+	path string,
+	format string,
+	args ...any,
+) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		Rule:     rule,
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *semanticValidator) validate() {
+	workflowIds := map[string]bool{}
+	for i := range v.model.Workflows {
+		workflow := &v.model.Workflows[i]
+		path := fmt.Sprintf("/workflows/%d", i)
+		if workflowIds[workflow.WorkflowId] {
+			v.report(
+				RuleDuplicateWorkflowId,
+				SeverityError,
+				path+"/workflowId",
+				"workflowId %q is already used",
+				workflow.WorkflowId,
+			)
+		}
+		workflowIds[workflow.WorkflowId] = true
+		v.validateWorkflow(workflow, path)
+	}
+	if v.model.Components != nil {
+		v.validateComponents(v.model.Components)
+	}
+}
+
+func (v *semanticValidator) validateWorkflow(
+	workflow *models.Workflow,
+	path string,
+) {
+	v.checkInputsRefs(workflow.Inputs, path+"/inputs")
+	v.checkParameters(workflow.Parameters, path+"/parameters")
+	v.checkExpressions(
+		workflow.Parameters,
+		path+"/parameters",
+		nil,
+		-1,
+	)
+
+	stepIds := map[string]bool{}
+	for i := range workflow.Steps {
+		step := &workflow.Steps[i]
+		stepPath := fmt.Sprintf("%s/steps/%d", path, i)
+		if stepIds[step.StepId] {
+			v.report(
+				RuleDuplicateStepId,
+				SeverityError,
+				stepPath+"/stepId",
+				"stepId %q is already used in workflow %q",
+				step.StepId,
+				workflow.WorkflowId,
+			)
+		}
+		stepIds[step.StepId] = true
+		v.validateStep(workflow, i, stepPath)
+	}
+
+	for i, action := range workflow.SuccessActions {
+		v.checkSuccessAction(
+			workflow,
+			action,
+			fmt.Sprintf("%s/successActions/%d", path, i),
+		)
+	}
+	v.checkExpressions(
+		workflow.SuccessActions,
+		path+"/successActions",
+		nil,
+		-1,
+	)
+	for i, action := range workflow.FailureActions {
+		v.checkFailureAction(
+			workflow,
+			action,
+			fmt.Sprintf("%s/failureActions/%d", path, i),
+		)
+	}
+	v.checkExpressions(
+		workflow.FailureActions,
+		path+"/failureActions",
+		nil,
+		-1,
+	)
+	v.checkOutputs(workflow.Outputs, path+"/outputs")
+	v.checkExpressions(workflow.Outputs, path+"/outputs", nil, -1)
+}
+
+func (v *semanticValidator) validateStep(
+	workflow *models.Workflow,
+	index int,
+	path string,
+) {
+	step := &workflow.Steps[index]
+	set := []string{}
+	if step.OperationId != nil {
+		set = append(set, "operationId")
+	}
+	if step.OperationPath != nil {
+		set = append(set, "operationPath")
+	}
+	if step.WorkflowId != nil {
+		set = append(set, "workflowId")
+	}
+	if len(set) > 1 {
+		v.report(
+			RuleStepOperationConflict,
+			SeverityError,
+			path,
+			"step %q sets %s, which are mutually exclusive",
+			step.StepId,
+			strings.Join(set, ", "),
+		)
+	}
+	v.checkParameters(step.Parameters, path+"/parameters")
+	for i, action := range step.OnSuccess {
+		v.checkSuccessAction(
+			workflow,
+			action,
+			fmt.Sprintf("%s/onSuccess/%d", path, i),
+		)
+	}
+	for i, action := range step.OnFailure {
+		v.checkFailureAction(
+			workflow,
+			action,
+			fmt.Sprintf("%s/onFailure/%d", path, i),
+		)
+	}
+	v.checkOutputs(step.Outputs, path+"/outputs")
+
+	fields := []struct {
+		name  string
+		value any
+	}{
+		{"parameters", step.Parameters},
+		{"requestBody", step.RequestBody},
+		{"successCriteria", step.SuccessCriteria},
+		{"onSuccess", step.OnSuccess},
+		{"onFailure", step.OnFailure},
+		{"outputs", step.Outputs},
+	}
+	for _, field := range fields {
+		v.checkExpressions(
+			field.value,
+			path+"/"+field.name,
+			workflow,
+			index,
+		)
+	}
+}
+
+func (v *semanticValidator) validateComponents(
+	components *models.Components,
+) {
+	for _, name := range sortedKeys(components.Inputs) {
+		v.checkInputsRefs(
+			components.Inputs[name],
+			"/components/inputs/"+pointerToken(name),
+		)
+	}
+	for _, name := range sortedKeys(components.SuccessActions) {
+		action := components.SuccessActions[name]
+		v.checkActionTarget(
+			nil,
+			string(action.Type),
+			action.StepId,
+			action.WorkflowId,
+			"/components/successActions/"+pointerToken(name),
+		)
+	}
+	for _, name := range sortedKeys(components.FailureActions) {
+		action := components.FailureActions[name]
+		v.checkActionTarget(
+			nil,
+			string(action.Type),
+			action.StepId,
+			action.WorkflowId,
+			"/components/failureActions/"+pointerToken(name),
+		)
+	}
+}
+
+// checkParameters reports the parameters sharing a name and a
+// location, along with the references to unknown parameters.
+func (v *semanticValidator) checkParameters(
+	parameters []models.ParameterOrReusable,
+	path string,
+) {
+	seen := map[string]bool{}
+	for i, parameter := range parameters {
+		paramPath := fmt.Sprintf("%s/%d", path, i)
+		param := parameter.Parameter
+		if parameter.Reusable != nil {
+			param = v.resolveParameter(parameter.Reusable, paramPath)
+		}
+		if param == nil {
+			continue
+		}
+		in := ""
+		if param.In != nil {
+			in = string(*param.In)
+		}
+		key := param.Name + "\x00" + in
+		if seen[key] {
+			v.report(
+				RuleDuplicateParameter,
+				SeverityError,
+				paramPath,
+				"parameter %q in %q is already defined",
+				param.Name,
+				in,
+			)
+		}
+		seen[key] = true
+	}
+}
+
+func (v *semanticValidator) resolveParameter(
+	reusable *models.Reusable,
+	path string,
+) *models.Parameter {
+	name, ok := v.checkReusable(
+		reusable,
+		expression.ABNFExpressionComponentsParameters,
+		path,
+	)
+	if !ok {
+		return nil
+	}
+	parameter := v.model.Components.Parameters[name]
+	return &parameter
+}
+
+func (v *semanticValidator) checkSuccessAction(
+	workflow *models.Workflow,
+	action models.SuccessActionOrReusable,
+	path string,
+) {
+	if action.Reusable != nil {
+		name, ok := v.checkReusable(
+			action.Reusable,
+			expression.ABNFExpressionComponentsSuccessActions,
+			path,
+		)
+		if !ok {
+			return
+		}
+		resolved := v.model.Components.SuccessActions[name]
+		action.SuccessAction = &resolved
+	}
+	if action.SuccessAction == nil {
+		return
+	}
+	v.checkActionTarget(
+		workflow,
+		string(action.SuccessAction.Type),
+		action.SuccessAction.StepId,
+		action.SuccessAction.WorkflowId,
+		path,
+	)
+}
+
+func (v *semanticValidator) checkFailureAction(
+	workflow *models.Workflow,
+	action models.FailureActionOrReusable,
+	path string,
+) {
+	if action.Reusable != nil {
+		name, ok := v.checkReusable(
+			action.Reusable,
+			expression.ABNFExpressionComponentsFailureActions,
+			path,
+		)
+		if !ok {
+			return
+		}
+		resolved := v.model.Components.FailureActions[name]
+		action.FailureAction = &resolved
+	}
+	if action.FailureAction == nil {
+		return
+	}
+	v.checkActionTarget(
+		workflow,
+		string(action.FailureAction.Type),
+		action.FailureAction.StepId,
+		action.FailureAction.WorkflowId,
+		path,
+	)
+}
+
+// checkActionTarget checks the step or workflow targeted by a goto or
+// retry action. The steps of workflow are the ones an action can
+// target, a nil workflow skipping the check of the stepId as reusable
+// actions are checked where they are used.
+func (v *semanticValidator) checkActionTarget(
+	workflow *models.Workflow,
+	actionType string,
+	stepId *string,
+	workflowId *string,
+	path string,
+) {
+	switch actionType {
+	case string(models.SuccessActionTypeGoto):
+		if stepId == nil && workflowId == nil {
+			v.report(
+				RuleGotoTargetConflict,
+				SeverityError,
+				path,
+				"a goto action requires either a stepId or a workflowId",
+			)
+			return
+		}
+	case string(models.FailureActionTypeRetry):
+	default:
+		return
+	}
+	if stepId != nil && workflowId != nil {
+		v.report(
+			RuleGotoTargetConflict,
+			SeverityError,
+			path,
+			"stepId and workflowId are mutually exclusive",
+		)
+		return
+	}
+	if workflowId != nil {
+		v.checkWorkflowTarget(*workflowId, path+"/workflowId")
+	}
+	if stepId != nil && workflow != nil &&
+		!slices.ContainsFunc(
+			workflow.Steps,
+			func(step models.Step) bool {
+				return step.StepId == *stepId
+			},
+		) {
+		v.report(
+			RuleGotoTargetNotFound,
+			SeverityError,
+			path+"/stepId",
+			"step %q not found in workflow %q",
+			*stepId,
+			workflow.WorkflowId,
+		)
+	}
+}
+
+// checkWorkflowTarget checks that the workflow referenced by ref,
+// either a workflowId or a $sourceDescriptions expression, exists.
+func (v *semanticValidator) checkWorkflowTarget(
+	ref string,
+	path string,
+) {
+	sourceRef, ok := strings.CutPrefix(
+		ref,
+		expression.ABNFExpressionSourceDescriptions,
+	)
+	if !ok {
+		if !slices.ContainsFunc(
+			v.model.Workflows,
+			func(workflow models.Workflow) bool {
+				return workflow.WorkflowId == ref
+			},
+		) {
+			v.report(
+				RuleGotoTargetNotFound,
+				SeverityError,
+				path,
+				"workflow %q not found",
+				ref,
+			)
+		}
+		return
+	}
+	// The workflows of other documents are not known, only their
+	// source description is checked.
+	name, _, _ := strings.Cut(sourceRef, ".")
+	if !slices.ContainsFunc(
+		v.model.SourcesDescriptions,
+		func(source models.SourceDescription) bool {
+			return source.Name == name
+		},
+	) {
+		v.report(
+			RuleGotoTargetNotFound,
+			SeverityError,
+			path,
+			"source description %q not found",
+			name,
+		)
+	}
+}
+
+// checkReusable checks that reusable references a component of the
+// kind designated by prefix, and returns its name.
+func (v *semanticValidator) checkReusable(
+	reusable *models.Reusable,
+	prefix string,
+	path string,
+) (string, bool) {
+	ref := strings.TrimSuffix(
+		strings.TrimPrefix(reusable.Reference, "{"),
+		"}",
+	)
+	name, ok := strings.CutPrefix(ref, prefix)
+	if !ok || name == "" {
+		v.report(
+			RuleComponentNotFound,
+			SeverityError,
+			path+"/reference",
+			"reference %q must be %s<name>",
+			reusable.Reference,
+			prefix,
+		)
+		return "", false
+	}
+	if !v.hasComponent(prefix, name) {
+		v.report(
+			RuleComponentNotFound,
+			SeverityError,
+			path+"/reference",
+			"component %q not found",
+			ref,
+		)
+		return "", false
+	}
+	return name, true
+}
+
+// hasComponent reports whether the component named name of the kind
+// designated by prefix exists.
+func (v *semanticValidator) hasComponent(
+	prefix string,
+	name string,
+) bool {
+	components := v.model.Components
+	if components == nil {
+		return false
+	}
+	var ok bool
+	switch prefix {
+	case expression.ABNFExpressionComponentsInputs:
+		_, ok = components.Inputs[name]
+	case expression.ABNFExpressionComponentsParameters:
+		_, ok = components.Parameters[name]
+	case expression.ABNFExpressionComponentsSuccessActions:
+		_, ok = components.SuccessActions[name]
+	case expression.ABNFExpressionComponentsFailureActions:
+		_, ok = components.FailureActions[name]
+	}
+	return ok
+}
+
+// checkInputsRefs reports the references of a JSON schema to inputs
+// of the components which do not exist.
+func (v *semanticValidator) checkInputsRefs(schema any, path string) {
+	switch s := schema.(type) {
+	case map[string]any:
+		for _, key := range sortedKeys(s) {
+			childPath := path + "/" + pointerToken(key)
+			ref, isString := s[key].(string)
+			if key != "$ref" || !isString {
+				v.checkInputsRefs(s[key], childPath)
+				continue
+			}
+			name, ok := strings.CutPrefix(ref, componentsInputsRef)
+			if ok && !v.hasComponent(
+				expression.ABNFExpressionComponentsInputs,
+				name,
+			) {
+				v.report(
+					RuleComponentNotFound,
+					SeverityError,
+					childPath,
+					"component %q not found",
+					ref,
+				)
+			}
+		}
+	case []any:
+		for i, item := range s {
+			v.checkInputsRefs(item, fmt.Sprintf("%s/%d", path, i))
+		}
+	}
+}
+
+// checkOutputs reports the output names not matching the pattern
+// defined by the specification.
+func (v *semanticValidator) checkOutputs(
+	outputs map[string]any,
+	path string,
+) {
+	for _, name := range sortedKeys(outputs) {
+		if !outputNameRegex.MatchString(name) {
+			v.report(
+				RuleInvalidOutputName,
+				SeverityError,
+				path+"/"+pointerToken(name),
+				"output name %q must match %s",
+				name,
+				outputNameRegex,
+			)
+		}
+	}
+}
+
+// checkExpressions walks the strings of value, the models being walked
+// through their JSON representation, and reports the references to
+// unknown components. If workflow is not nil, the references of the
+// step at index to itself or to steps of the workflow declared after
+// it are reported too.
+func (v *semanticValidator) checkExpressions(
+	value any,
+	path string,
+	workflow *models.Workflow,
+	index int,
+) {
+	walkStrings(toJSON(value), path, func(str string, path string) {
+		// References of reusable objects are checked along with
+		// the kind of component they must reference.
+		if strings.HasSuffix(path, "/reference") {
+			return
+		}
+		for _, match := range componentsRegex.FindAllStringSubmatch(str, -1) {
+			prefix := expression.ABNFExpressionComponents + match[1] + "."
+			if !v.hasComponent(prefix, match[2]) {
+				v.report(
+					RuleComponentNotFound,
+					SeverityError,
+					path,
+					"component %q not found",
+					prefix+match[2],
+				)
+			}
+		}
+		if workflow == nil {
+			return
+		}
+		for _, match := range stepsRegex.FindAllStringSubmatch(str, -1) {
+			target := slices.IndexFunc(
+				workflow.Steps,
+				func(step models.Step) bool {
+					return step.StepId == match[1]
+				},
+			)
+			switch {
+			case target == index:
+				v.report(
+					RuleStepForwardReference,
+					SeverityWarning,
+					path,
+					"step %q references itself",
+					match[1],
+				)
+			case target > index:
+				v.report(
+					RuleStepForwardReference,
+					SeverityWarning,
+					path,
+					"step %q is declared after step %q",
+					match[1],
+					workflow.Steps[index].StepId,
+				)
+			}
+		}
+	})
+}
+
+// toJSON returns the JSON representation of value, or nil if it
+// cannot be encoded.
+func toJSON(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil
+	}
+	return decoded
+}
+
+// walkStrings calls fn with every string of a decoded JSON value and
+// its JSON pointer, in a stable order.
+func walkStrings(value any, path string, fn func(str, path string)) {
+	switch v := value.(type) {
+	case string:
+		fn(v, path)
+	case map[string]any:
+		for _, key := range sortedKeys(v) {
+			walkStrings(v[key], path+"/"+pointerToken(key), fn)
+		}
+	case []any:
+		for i, item := range v {
+			walkStrings(item, fmt.Sprintf("%s/%d", path, i), fn)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// pointerToken escapes key as a JSON pointer reference token.
+func pointerToken(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
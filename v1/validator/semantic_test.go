@@ -0,0 +1,507 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/bragdonD/arazzo-go/internal/testutil"
+	"github.com/bragdonD/arazzo-go/v1/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSemanticTestModel returns a valid document holding workflows.
+func newSemanticTestModel(workflows ...models.Workflow) *models.Spec {
+	model := testutil.NewSpecModel(
+		"petstore.openapi.yaml",
+		workflows...)
+	model.Components = &models.Components{
+		Inputs: map[string]any{
+			"credentials": map[string]any{"type": "object"},
+		},
+		Parameters: map[string]models.Parameter{
+			"token": {
+				Name: "token",
+				In:   models.ParameterLocationHeader.ToPtr(),
+			},
+		},
+		FailureActions: map[string]models.FailureAction{
+			"retryLogin": {
+				Name:   "retryLogin",
+				Type:   models.FailureActionTypeRetry,
+				StepId: testutil.Ptr("login"),
+			},
+		},
+	}
+	return model
+}
+
+func TestValidateSemantics(t *testing.T) {
+	header := models.ParameterLocationHeader.ToPtr()
+	tests := []struct {
+		name      string
+		workflows []models.Workflow
+		want      []Diagnostic
+	}{
+		{
+			name: "valid",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Inputs: map[string]any{
+					"$ref": "#/components/inputs/credentials",
+				},
+				Steps: []models.Step{
+					{
+						StepId:      "login",
+						OperationId: testutil.Ptr("loginUser"),
+						Parameters: []models.ParameterOrReusable{
+							{Reusable: &models.Reusable{
+								Reference: "$components.parameters.token",
+							}},
+							{Parameter: &models.Parameter{
+								Name:  "token",
+								In:    models.ParameterLocationQuery.ToPtr(),
+								Value: "$inputs.token",
+							}},
+						},
+						OnFailure: []models.FailureActionOrReusable{
+							{Reusable: &models.Reusable{
+								Reference: "$components.failureActions.retryLogin",
+							}},
+						},
+						Outputs: map[string]any{
+							"token": "$response.body",
+						},
+					},
+					{
+						StepId:      "getPet",
+						OperationId: testutil.Ptr("getPetById"),
+						Parameters: []models.ParameterOrReusable{
+							{Parameter: &models.Parameter{
+								Name:  "token",
+								In:    header,
+								Value: "$steps.login.outputs.token",
+							}},
+						},
+					},
+				},
+				Outputs: map[string]any{
+					"pet.id": "$steps.getPet.outputs.id",
+				},
+			}},
+		},
+		{
+			name: "duplicate ids",
+			workflows: []models.Workflow{
+				{
+					WorkflowId: "main",
+					Steps: []models.Step{
+						{
+							StepId:      "login",
+							OperationId: testutil.Ptr("loginUser"),
+						},
+						{
+							StepId:      "login",
+							OperationId: testutil.Ptr("loginUser"),
+						},
+					},
+				},
+				{
+					WorkflowId: "main",
+					Steps: []models.Step{
+						{
+							StepId:      "login",
+							OperationId: testutil.Ptr("loginUser"),
+						},
+					},
+				},
+			},
+			want: []Diagnostic{
+				{
+					Rule:     RuleDuplicateStepId,
+					Severity: SeverityError,
+					Path:     "/workflows/0/steps/1/stepId",
+					Message:  `stepId "login" is already used in workflow "main"`,
+				},
+				{
+					Rule:     RuleDuplicateWorkflowId,
+					Severity: SeverityError,
+					Path:     "/workflows/1/workflowId",
+					Message:  `workflowId "main" is already used`,
+				},
+			},
+		},
+		{
+			name: "goto targets",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Steps: []models.Step{{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+					OnSuccess: []models.SuccessActionOrReusable{
+						{SuccessAction: &models.SuccessAction{
+							Name:   "missingStep",
+							Type:   models.SuccessActionTypeGoto,
+							StepId: testutil.Ptr("missing"),
+						}},
+						{SuccessAction: &models.SuccessAction{
+							Name:       "both",
+							Type:       models.SuccessActionTypeGoto,
+							StepId:     testutil.Ptr("login"),
+							WorkflowId: testutil.Ptr("main"),
+						}},
+						{SuccessAction: &models.SuccessAction{
+							Name: "none",
+							Type: models.SuccessActionTypeGoto,
+						}},
+					},
+				}},
+				FailureActions: []models.FailureActionOrReusable{
+					{FailureAction: &models.FailureAction{
+						Name:       "missingWorkflow",
+						Type:       models.FailureActionTypeGoto,
+						WorkflowId: testutil.Ptr("missing"),
+					}},
+					{FailureAction: &models.FailureAction{
+						Name: "missingSource",
+						Type: models.FailureActionTypeGoto,
+						WorkflowId: testutil.Ptr(
+							"$sourceDescriptions.other.main",
+						),
+					}},
+				},
+			}},
+			want: []Diagnostic{
+				{
+					Rule:     RuleGotoTargetNotFound,
+					Severity: SeverityError,
+					Path:     "/workflows/0/steps/0/onSuccess/0/stepId",
+					Message:  `step "missing" not found in workflow "main"`,
+				},
+				{
+					Rule:     RuleGotoTargetConflict,
+					Severity: SeverityError,
+					Path:     "/workflows/0/steps/0/onSuccess/1",
+					Message:  "stepId and workflowId are mutually exclusive",
+				},
+				{
+					Rule:     RuleGotoTargetConflict,
+					Severity: SeverityError,
+					Path:     "/workflows/0/steps/0/onSuccess/2",
+					Message:  "a goto action requires either a stepId or a workflowId",
+				},
+				{
+					Rule:     RuleGotoTargetNotFound,
+					Severity: SeverityError,
+					Path:     "/workflows/0/failureActions/0/workflowId",
+					Message:  `workflow "missing" not found`,
+				},
+				{
+					Rule:     RuleGotoTargetNotFound,
+					Severity: SeverityError,
+					Path:     "/workflows/0/failureActions/1/workflowId",
+					Message:  `source description "other" not found`,
+				},
+			},
+		},
+		{
+			name: "reusable action target",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Steps: []models.Step{{
+					StepId:      "getPet",
+					OperationId: testutil.Ptr("getPetById"),
+					OnFailure: []models.FailureActionOrReusable{
+						{Reusable: &models.Reusable{
+							Reference: "$components.failureActions.retryLogin",
+						}},
+					},
+				}},
+			}},
+			want: []Diagnostic{{
+				Rule:     RuleGotoTargetNotFound,
+				Severity: SeverityError,
+				Path:     "/workflows/0/steps/0/onFailure/0/stepId",
+				Message:  `step "login" not found in workflow "main"`,
+			}},
+		},
+		{
+			name: "step operation conflict",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Steps: []models.Step{{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+					OperationPath: testutil.Ptr(
+						"{$sourceDescriptions.petstore.url}#/paths/~1user~1login/get",
+					),
+					WorkflowId: testutil.Ptr("main"),
+				}},
+			}},
+			want: []Diagnostic{{
+				Rule:     RuleStepOperationConflict,
+				Severity: SeverityError,
+				Path:     "/workflows/0/steps/0",
+				Message:  `step "login" sets operationId, operationPath, workflowId, which are mutually exclusive`,
+			}},
+		},
+		{
+			name: "unknown components",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Inputs: map[string]any{
+					"allOf": []any{
+						map[string]any{
+							"$ref": "#/components/inputs/missing",
+						},
+					},
+				},
+				Steps: []models.Step{{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+					Parameters: []models.ParameterOrReusable{
+						{Reusable: &models.Reusable{
+							Reference: "$components.parameters.missing",
+						}},
+						{Reusable: &models.Reusable{
+							Reference: "$components.successActions.token",
+						}},
+						{Parameter: &models.Parameter{
+							Name:  "user",
+							In:    header,
+							Value: "$components.inputs.missing",
+						}},
+					},
+				}},
+			}},
+			want: []Diagnostic{
+				{
+					Rule:     RuleComponentNotFound,
+					Severity: SeverityError,
+					Path:     "/workflows/0/inputs/allOf/0/$ref",
+					Message:  `component "#/components/inputs/missing" not found`,
+				},
+				{
+					Rule:     RuleComponentNotFound,
+					Severity: SeverityError,
+					Path:     "/workflows/0/steps/0/parameters/0/reference",
+					Message:  `component "$components.parameters.missing" not found`,
+				},
+				{
+					Rule:     RuleComponentNotFound,
+					Severity: SeverityError,
+					Path:     "/workflows/0/steps/0/parameters/1/reference",
+					Message:  `reference "$components.successActions.token" must be $components.parameters.<name>`,
+				},
+				{
+					Rule:     RuleComponentNotFound,
+					Severity: SeverityError,
+					Path:     "/workflows/0/steps/0/parameters/2/value",
+					Message:  `component "$components.inputs.missing" not found`,
+				},
+			},
+		},
+		{
+			name: "step forward reference",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Steps: []models.Step{
+					{
+						StepId:      "login",
+						OperationId: testutil.Ptr("loginUser"),
+						SuccessCriteria: []models.Criterion{
+							{
+								Condition: "$steps.getPet.outputs.id != null",
+							},
+						},
+					},
+					{
+						StepId:      "getPet",
+						OperationId: testutil.Ptr("getPetById"),
+					},
+				},
+			}},
+			want: []Diagnostic{{
+				Rule:     RuleStepForwardReference,
+				Severity: SeverityWarning,
+				Path:     "/workflows/0/steps/0/successCriteria/0/condition",
+				Message:  `step "getPet" is declared after step "login"`,
+			}},
+		},
+		{
+			name: "step self reference",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Steps: []models.Step{{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+					SuccessCriteria: []models.Criterion{
+						{
+							Condition: "$steps.login.outputs.token != null",
+						},
+					},
+				}},
+			}},
+			want: []Diagnostic{{
+				Rule:     RuleStepForwardReference,
+				Severity: SeverityWarning,
+				Path:     "/workflows/0/steps/0/successCriteria/0/condition",
+				Message:  `step "login" references itself`,
+			}},
+		},
+		{
+			name: "workflow parameter expressions",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Parameters: []models.ParameterOrReusable{
+					{Parameter: &models.Parameter{
+						Name:  "id",
+						In:    header,
+						Value: "$components.parameters.missing",
+					}},
+				},
+				Steps: []models.Step{
+					{
+						StepId:      "login",
+						OperationId: testutil.Ptr("loginUser"),
+					},
+				},
+			}},
+			want: []Diagnostic{{
+				Rule:     RuleComponentNotFound,
+				Severity: SeverityError,
+				Path:     "/workflows/0/parameters/0/value",
+				Message:  `component "$components.parameters.missing" not found`,
+			}},
+		},
+		{
+			name: "workflow action expressions",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Steps: []models.Step{
+					{
+						StepId:      "login",
+						OperationId: testutil.Ptr("loginUser"),
+					},
+				},
+				SuccessActions: []models.SuccessActionOrReusable{
+					{SuccessAction: &models.SuccessAction{
+						Name: "done",
+						Type: models.SuccessActionTypeEnd,
+						Criteria: []models.Criterion{{
+							Condition: "$components.successActions.missing != null",
+						}},
+					}},
+				},
+				FailureActions: []models.FailureActionOrReusable{
+					{FailureAction: &models.FailureAction{
+						Name: "giveUp",
+						Type: models.FailureActionTypeEnd,
+						Criteria: []models.Criterion{{
+							Condition: "$components.inputs.missing != null",
+						}},
+					}},
+				},
+			}},
+			want: []Diagnostic{
+				{
+					Rule:     RuleComponentNotFound,
+					Severity: SeverityError,
+					Path:     "/workflows/0/successActions/0/criteria/0/condition",
+					Message:  `component "$components.successActions.missing" not found`,
+				},
+				{
+					Rule:     RuleComponentNotFound,
+					Severity: SeverityError,
+					Path:     "/workflows/0/failureActions/0/criteria/0/condition",
+					Message:  `component "$components.inputs.missing" not found`,
+				},
+			},
+		},
+		{
+			name: "duplicate parameters",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Parameters: []models.ParameterOrReusable{
+					{
+						Parameter: &models.Parameter{
+							Name: "id",
+							In:   header,
+						},
+					},
+					{
+						Parameter: &models.Parameter{
+							Name: "id",
+							In:   header,
+						},
+					},
+				},
+				Steps: []models.Step{{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+					Parameters: []models.ParameterOrReusable{
+						{
+							Parameter: &models.Parameter{
+								Name: "token",
+								In:   header,
+							},
+						},
+						{Reusable: &models.Reusable{
+							Reference: "$components.parameters.token",
+						}},
+					},
+				}},
+			}},
+			want: []Diagnostic{
+				{
+					Rule:     RuleDuplicateParameter,
+					Severity: SeverityError,
+					Path:     "/workflows/0/parameters/1",
+					Message:  `parameter "id" in "header" is already defined`,
+				},
+				{
+					Rule:     RuleDuplicateParameter,
+					Severity: SeverityError,
+					Path:     "/workflows/0/steps/0/parameters/1",
+					Message:  `parameter "token" in "header" is already defined`,
+				},
+			},
+		},
+		{
+			name: "invalid output names",
+			workflows: []models.Workflow{{
+				WorkflowId: "main",
+				Steps: []models.Step{{
+					StepId:      "login",
+					OperationId: testutil.Ptr("loginUser"),
+					Outputs: map[string]any{
+						"a/b": "$response.body",
+					},
+				}},
+				Outputs: map[string]any{
+					"pet id": "$steps.login.outputs",
+				},
+			}},
+			want: []Diagnostic{
+				{
+					Rule:     RuleInvalidOutputName,
+					Severity: SeverityError,
+					Path:     "/workflows/0/steps/0/outputs/a~1b",
+					Message:  `output name "a/b" must match ^[a-zA-Z0-9\.\-_]+$`,
+				},
+				{
+					Rule:     RuleInvalidOutputName,
+					Severity: SeverityError,
+					Path:     "/workflows/0/outputs/pet id",
+					Message:  `output name "pet id" must match ^[a-zA-Z0-9\.\-_]+$`,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := ValidateSemantics(
+				newSemanticTestModel(tt.workflows...),
+			)
+			assert.Equal(t, tt.want, diagnostics)
+		})
+	}
+}
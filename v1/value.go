@@ -1,7 +1,10 @@
 package v1
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/bragdonD/arazzo-go/v1/expression"
 )
@@ -11,35 +14,47 @@ type Value struct {
 	// expression
 	val     any
 	isConst bool
+	// parts are the pieces of a string embedding runtime
+	// expressions in braces, e.g. "Bearer {$inputs.token}".
+	parts []valuePart
+}
+
+// valuePart is either a literal text or a runtime expression embedded
+// in a string.
+type valuePart struct {
+	text string
+	expr expression.Expr
 }
 
 func NewValue(input any) *Value {
 	value := &Value{
-		isConst: false,
+		val:     input,
+		isConst: true,
 	}
 	str, ok := input.(string)
 	// If the value is not a string then it is a constant
-	// value. Else, it can either be a runtime expression
-	// or a constant string.
-	if !ok {
-		value.isConst = true
-		value.val = input
+	// value. Else, it can either be a runtime expression,
+	// a string embedding runtime expressions or a constant
+	// string.
+	if !ok || len(str) == 0 {
+		return value
 	}
+	expr := str
 	var err error
-	if len(str) > 0 && str[0] == '{' {
-		str, err = expression.Extract(str)
-		if err != nil {
-			value.isConst = true
-			value.val = input
+	if str[0] == '{' {
+		expr, err = expression.Extract(str)
+	}
+	if err == nil {
+		if _, err = expression.Parse(expr); err == nil {
+			value.isConst = false
+			return value
 		}
 	}
 
-	_, err = expression.Parse(str)
-	if err != nil {
-		value.isConst = true
-		value.val = input
+	if parts := interpolationParts(str); parts != nil {
+		value.isConst = false
+		value.parts = parts
 	}
-
 	return value
 }
 
@@ -47,10 +62,16 @@ func (v *Value) IsConstant() bool {
 	return v.isConst
 }
 
-func (v *Value) Evaluate(spec *Spec) (any, error) {
+// Evaluate returns the value. Runtime expressions are resolved
+// against ctx. The runtime expressions embedded in a string are
+// replaced by their value, formatted with [FormatValue].
+func (v *Value) Evaluate(ctx *EvalContext) (any, error) {
 	if v.isConst {
 		return v.val, nil
 	}
+	if v.parts != nil {
+		return v.interpolate(ctx)
+	}
 	str, ok := v.val.(string)
 	if !ok {
 		return nil, fmt.Errorf("the value being evaluated" +
@@ -72,5 +93,92 @@ func (v *Value) Evaluate(spec *Spec) (any, error) {
 			" runtime expression: %v", err)
 	}
 
-	return ResolveRuntimeExpression(expr, spec)
+	return ResolveRuntimeExpression(expr, ctx)
+}
+
+// interpolate returns the string of v with its embedded runtime
+// expressions replaced by their value.
+func (v *Value) interpolate(ctx *EvalContext) (string, error) {
+	builder := strings.Builder{}
+	for _, part := range v.parts {
+		if part.expr == nil {
+			builder.WriteString(part.text)
+			continue
+		}
+		value, err := ResolveRuntimeExpression(part.expr, ctx)
+		if err != nil {
+			return "", err
+		}
+		str, err := FormatValue(value)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(str)
+	}
+	return builder.String(), nil
+}
+
+// interpolationParts splits str into literal texts and the runtime
+// expressions it embeds in braces. It returns nil if str embeds no
+// runtime expression. Braces not enclosing a runtime expression are
+// kept as text.
+func interpolationParts(str string) []valuePart {
+	var parts []valuePart
+	embedsExpression := false
+	text := 0
+	for start := 0; start < len(str); start++ {
+		if str[start] != '{' {
+			continue
+		}
+		end := strings.IndexByte(str[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		expr, err := expression.Parse(str[start+1 : end])
+		if err != nil {
+			continue
+		}
+		if text < start {
+			parts = append(parts, valuePart{text: str[text:start]})
+		}
+		parts = append(parts, valuePart{expr: expr})
+		embedsExpression = true
+		text = end + 1
+		start = end
+	}
+	if !embedsExpression {
+		return nil
+	}
+	if text < len(str) {
+		parts = append(parts, valuePart{text: str[text:]})
+	}
+	return parts
+}
+
+// FormatValue returns the textual representation of a value set
+// within a path, a header, a form or any other non JSON text.
+// Objects and arrays are encoded as JSON and numbers are written
+// without exponent, so that the large integers decoded from JSON as
+// float64 keep their digits.
+func FormatValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode value: %w", err)
+		}
+		return string(data), nil
+	}
+	return fmt.Sprint(value), nil
 }
@@ -1,6 +1,10 @@
 package v1
 
-import "github.com/bragdonD/arazzo-go/v1/models"
+import (
+	"fmt"
+
+	"github.com/bragdonD/arazzo-go/v1/models"
+)
 
 // Workflow is a struct that represents an Arazzo specification 1.0.X
 // workflow object.
@@ -9,14 +13,18 @@ type Workflow struct {
 	parent         *Spec
 	id             string
 	dependencies   []*Workflow
+	inputs         *InputSchema
 	steps          []*Step
 	successActions []*SuccessAction
 	failureActions []*FailureAction
-	outputs        map[string]any
+	outputs        map[string]*Value
 	parameters     []*Parameter
 }
 
-func NewWorkflow(model *models.Workflow, parent *Spec) (*Workflow, error) {
+func NewWorkflow(
+	model *models.Workflow,
+	parent *Spec,
+) (*Workflow, error) {
 	workflow := &Workflow{
 		model:          model,
 		parent:         parent,
@@ -25,42 +33,268 @@ func NewWorkflow(model *models.Workflow, parent *Spec) (*Workflow, error) {
 		steps:          []*Step{},
 		successActions: []*SuccessAction{},
 		failureActions: []*FailureAction{},
-		outputs:        map[string]any{},
+		outputs:        map[string]*Value{},
 		parameters:     []*Parameter{},
 	}
 
-	for _, paramOrReusable := range model.Parameters {
-		var param *models.Parameter
-		var err error
-		if paramOrReusable.Parameter != nil {
-			param = paramOrReusable.Parameter
-		} else if paramOrReusable.Reusable != nil {
-			param, ok := parent.GetComponents().GetParameter(paramOrReusable.Reusable.Reference)
+	if len(model.Inputs) > 0 {
+		inputs, err := NewInputSchema(
+			model.Inputs,
+			parent.GetComponents(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"workflow %q: %w",
+				workflow.id,
+				err,
+			)
 		}
+		workflow.inputs = inputs
+	}
+
+	for _, paramOrReusable := range model.Parameters {
+		param, err := paramOrReusable.ToParameter(
+			parent.GetComponents().GetModel(),
+		)
 		if err != nil {
 			return nil, err
 		}
-		if param != nil {
-			parameter := NewParameter(param)
-			workflow.parameters = append(workflow.parameters, parameter)
-		}
+		workflow.parameters = append(
+			workflow.parameters,
+			NewParameter(param),
+		)
 	}
 
-	for _, step := range model.Steps {
-		stepObj, err := NewStep(&step, workflow)
+	for i := range model.Steps {
+		stepObj, err := NewStep(&model.Steps[i], workflow)
 		if err != nil {
 			return nil, err
 		}
 		workflow.steps = append(workflow.steps, stepObj)
 	}
 
+	for i := range model.SuccessActions {
+		actionModel, err := model.SuccessActions[i].ToSuccessAction(
+			parent.GetComponents().GetModel(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"workflow %q: %w",
+				workflow.id,
+				err,
+			)
+		}
+		action, err := NewSuccessAction(actionModel)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"workflow %q: %w",
+				workflow.id,
+				err,
+			)
+		}
+		workflow.successActions = append(
+			workflow.successActions,
+			action,
+		)
+	}
+
+	for i := range model.FailureActions {
+		actionModel, err := model.FailureActions[i].ToFailureAction(
+			parent.GetComponents().GetModel(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"workflow %q: %w",
+				workflow.id,
+				err,
+			)
+		}
+		action, err := NewFailureAction(actionModel)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"workflow %q: %w",
+				workflow.id,
+				err,
+			)
+		}
+		workflow.failureActions = append(
+			workflow.failureActions,
+			action,
+		)
+	}
+
+	for name, output := range model.Outputs {
+		workflow.outputs[name] = NewValue(output)
+	}
+
+	if err := workflow.checkGotoSteps(); err != nil {
+		return nil, err
+	}
+
 	return workflow, nil
 }
 
+func (w *Workflow) GetModel() *models.Workflow {
+	return w.model
+}
+
 func (w *Workflow) GetParent() *Spec {
 	return w.parent
 }
 
+func (w *Workflow) GetId() string {
+	return w.id
+}
+
+// GetInputs returns the schema of the inputs of the workflow, or nil
+// if the workflow does not declare its inputs.
+func (w *Workflow) GetInputs() *InputSchema {
+	return w.inputs
+}
+
+// ValidateInputs validates inputs against the input schema of the
+// workflow and returns them along with the defaults of the missing
+// ones. Inputs are returned as is if the workflow does not declare
+// its inputs.
+func (w *Workflow) ValidateInputs(
+	inputs map[string]any,
+) (map[string]any, error) {
+	if w.inputs == nil {
+		return inputs, nil
+	}
+	validated, err := w.inputs.Validate(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("workflow %q: %w", w.id, err)
+	}
+	return validated, nil
+}
+
+// FilterInputs returns the inputs declared by the input schema of the
+// workflow, see [InputSchema.Filter]. Inputs are returned as is if the
+// workflow does not declare its inputs.
+func (w *Workflow) FilterInputs(
+	inputs map[string]any,
+) map[string]any {
+	if w.inputs == nil {
+		return inputs
+	}
+	return w.inputs.Filter(inputs)
+}
+
+func (w *Workflow) GetSteps() []*Step {
+	return w.steps
+}
+
+// GetStep returns the step of the workflow identified by stepId.
+func (w *Workflow) GetStep(stepId string) (*Step, bool) {
+	for _, step := range w.steps {
+		if step.id == stepId {
+			return step, true
+		}
+	}
+	return nil, false
+}
+
+// checkGotoSteps verifies that the steps targeted by the actions of
+// the workflow and its steps exist within the workflow.
+func (w *Workflow) checkGotoSteps() error {
+	if err := w.checkActionSteps(
+		"workflow",
+		w.id,
+		w.successActions,
+		w.failureActions,
+	); err != nil {
+		return err
+	}
+	for _, step := range w.steps {
+		if err := w.checkActionSteps(
+			"step",
+			step.id,
+			step.onSuccess,
+			step.onFailure,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkActionSteps verifies that the steps targeted by the success
+// and failure actions of the object identified by kind and id exist
+// within the workflow.
+func (w *Workflow) checkActionSteps(
+	kind string,
+	id string,
+	successActions []*SuccessAction,
+	failureActions []*FailureAction,
+) error {
+	check := func(actionKind string, name string, stepId string) error {
+		if _, ok := w.GetStep(stepId); stepId != "" && !ok {
+			return fmt.Errorf(
+				"%s %q: %s action %q: step %q not found",
+				kind,
+				id,
+				actionKind,
+				name,
+				stepId,
+			)
+		}
+		return nil
+	}
+	for _, action := range successActions {
+		err := check("success", action.name, action.GetStepId())
+		if err != nil {
+			return err
+		}
+	}
+	for _, action := range failureActions {
+		err := check("failure", action.name, action.GetStepId())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Workflow) GetParameters() []*Parameter {
+	return w.parameters
+}
+
+func (w *Workflow) GetSuccessActions() []*SuccessAction {
+	return w.successActions
+}
+
+func (w *Workflow) GetFailureActions() []*FailureAction {
+	return w.failureActions
+}
+
+func (w *Workflow) GetOutputs() map[string]*Value {
+	return w.outputs
+}
+
+// GetDependencies returns the workflows which must be completed
+// before the workflow is executed. They may belong to other Arazzo
+// source descriptions.
+func (w *Workflow) GetDependencies() []*Workflow {
+	return w.dependencies
+}
+
+// ResolveDependencies resolves the workflows listed by the dependsOn
+// field. A dependency is either the workflowId of a workflow of the
+// spec or a $sourceDescriptions.<name>.<workflowId> expression
+// referencing a workflow of an Arazzo source description.
 func (w *Workflow) ResolveDependencies() error {
+	w.dependencies = []*Workflow{}
+	for _, dependsOn := range w.model.DependsOn {
+		dependency, err := w.parent.GetWorkflowByReference(dependsOn)
+		if err != nil {
+			return fmt.Errorf(
+				"workflow %q: dependency: %w",
+				w.id,
+				err,
+			)
+		}
+		w.dependencies = append(w.dependencies, dependency)
+	}
 	return nil
 }
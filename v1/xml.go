@@ -0,0 +1,156 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// XMLDocument is a decoded XML message body. Its textual
+// representation is the serialized document, so that XML bodies can
+// still be matched by regex criteria or used as output values.
+type XMLDocument struct {
+	root *xmlquery.Node
+}
+
+// ParseXML decodes an XML document.
+func ParseXML(data []byte) (*XMLDocument, error) {
+	root, err := xmlquery.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode xml body: %w", err)
+	}
+	return &XMLDocument{root: root}, nil
+}
+
+// GetRoot returns the document node of the XML document.
+func (d *XMLDocument) GetRoot() *xmlquery.Node {
+	return d.root
+}
+
+// String returns the serialized XML document.
+func (d *XMLDocument) String() string {
+	return d.root.OutputXML(false)
+}
+
+// MarshalText implements [encoding.TextMarshaler] so that an XML
+// document is encoded as a JSON string holding its serialization.
+func (d *XMLDocument) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// xmlAttributePrefix is the prefix of the members of an object
+// encoded as XML attributes rather than child elements.
+const xmlAttributePrefix = "@"
+
+// xmlTextMember is the member of an object encoded as the text of its
+// element.
+const xmlTextMember = "#text"
+
+// EncodeXML serializes payload, an object having a single member, as
+// an XML document whose root element is named after that member. The
+// members of an object are written as child elements sorted by name,
+// except the members prefixed with "@", written as attributes, and
+// "#text", written as text. An array repeats the element for each of
+// its items, and a scalar is written as text, e.g. in YAML:
+//
+//	pet:
+//	  "@id": 10
+//	  name: Rex
+//	  tags: [dog, small]
+//
+// is encoded as
+//
+//	<pet id="10"><name>Rex</name><tags>dog</tags><tags>small</tags></pet>
+func EncodeXML(payload any) ([]byte, error) {
+	object, ok := payload.(map[string]any)
+	if !ok || len(object) != 1 {
+		return nil, fmt.Errorf(
+			"an xml payload must be an object with a single member, got %T",
+			payload,
+		)
+	}
+	var buf bytes.Buffer
+	for name, value := range object {
+		if err := writeXMLElement(&buf, name, value); err != nil {
+			return nil, err
+		}
+	}
+	// The document is parsed back to reject the names which are not
+	// valid XML names.
+	if _, err := ParseXML(buf.Bytes()); err != nil {
+		return nil, fmt.Errorf(
+			"failed to encode xml payload: %w",
+			err,
+		)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeXMLElement writes value as the XML element name, or as an
+// element per item if value is an array.
+func writeXMLElement(
+	buf *bytes.Buffer,
+	name string,
+	value any,
+) error {
+	if items, ok := value.([]any); ok {
+		for _, item := range items {
+			if err := writeXMLElement(buf, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	buf.WriteString("<" + name)
+	object, ok := value.(map[string]any)
+	if !ok {
+		text, err := FormatValue(value)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(">")
+		xml.EscapeText(buf, []byte(text))
+		buf.WriteString("</" + name + ">")
+		return nil
+	}
+
+	names := slices.Sorted(maps.Keys(object))
+	for _, member := range names {
+		attribute, ok := strings.CutPrefix(member, xmlAttributePrefix)
+		if !ok {
+			continue
+		}
+		text, err := FormatValue(object[member])
+		if err != nil {
+			return err
+		}
+		buf.WriteString(" " + attribute + `="`)
+		xml.EscapeText(buf, []byte(text))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+	for _, member := range names {
+		switch {
+		case strings.HasPrefix(member, xmlAttributePrefix):
+		case member == xmlTextMember:
+			text, err := FormatValue(object[member])
+			if err != nil {
+				return err
+			}
+			xml.EscapeText(buf, []byte(text))
+		default:
+			err := writeXMLElement(buf, member, object[member])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	buf.WriteString("</" + name + ">")
+	return nil
+}
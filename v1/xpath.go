@@ -0,0 +1,313 @@
+package v1
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/bragdonD/arazzo-go/v1/models"
+)
+
+// XPath is a compiled XPath expression. Only [XPath 1.0] expressions
+// can be evaluated: the xpath-20 and xpath-30 versions are accepted,
+// but expressions using a construct introduced by a later version are
+// rejected with an [UnsupportedXPathError].
+//
+// [XPath 1.0]: https://www.w3.org/TR/1999/REC-xpath-19991116/
+type XPath struct {
+	input   string
+	version string
+	expr    *xpath.Expr
+}
+
+// UnsupportedXPathError is returned when an XPath expression uses a
+// construct introduced after XPath 1.0.
+type UnsupportedXPathError struct {
+	// Expression is the XPath expression being compiled.
+	Expression string
+	// Construct describes the unsupported construct.
+	Construct string
+}
+
+// Error returns a formatted error message indicating the expression
+// and the construct which is not supported.
+func (e *UnsupportedXPathError) Error() string {
+	return fmt.Sprintf(
+		"arazzo-go: XPath %q uses %s, only XPath 1.0 expressions are supported",
+		e.Expression,
+		e.Construct,
+	)
+}
+
+// NewXPath compiles the expression input. The version must be empty
+// or one of the XPath versions defined by the Arazzo specification.
+func NewXPath(input string, version string) (*XPath, error) {
+	switch version {
+	case "",
+		models.XPathVersion10,
+		models.XPathVersion20,
+		models.XPathVersion30:
+	default:
+		return nil, fmt.Errorf(
+			"unsupported XPath version %q",
+			version,
+		)
+	}
+
+	// The XPath implementation silently misreads most of the
+	// constructs introduced after XPath 1.0, they are looked for
+	// before compiling the expression.
+	if construct, ok := findXPath2Construct(input); ok {
+		return nil, &UnsupportedXPathError{
+			Expression: input,
+			Construct:  construct,
+		}
+	}
+	expr, err := xpath.Compile(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XPath %q: %w", input, err)
+	}
+
+	return &XPath{
+		input:   input,
+		version: version,
+		expr:    expr,
+	}, nil
+}
+
+func (p *XPath) String() string {
+	return p.input
+}
+
+func (p *XPath) GetVersion() string {
+	return p.version
+}
+
+// Evaluate evaluates the expression against value, an [XMLDocument]
+// or its serialization. The result is either a bool, a float64, a
+// string or the selected nodes.
+func (p *XPath) Evaluate(value any) (result any, err error) {
+	root, err := toXMLNode(value)
+	if err != nil {
+		return nil, err
+	}
+	// The XPath implementation panics on type errors, such as
+	// calling a function with a wrong argument.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf(
+				"failed to evaluate XPath %q: %v",
+				p.input,
+				r,
+			)
+		}
+	}()
+	result = p.expr.Evaluate(xmlquery.CreateXPathNavigator(root))
+	if _, ok := result.(*xpath.NodeIterator); ok {
+		return xmlquery.QuerySelectorAll(root, p.expr), nil
+	}
+	return result, nil
+}
+
+// Test evaluates the expression against value and converts the
+// result to a boolean as the XPath boolean() function does: a node
+// set is true if it is not empty, a number if it is neither zero nor
+// NaN and a string if it is not empty.
+func (p *XPath) Test(value any) (bool, error) {
+	result, err := p.Evaluate(value)
+	if err != nil {
+		return false, err
+	}
+	switch v := result.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		return v != 0 && !math.IsNaN(v), nil
+	case string:
+		return v != "", nil
+	case []*xmlquery.Node:
+		return len(v) > 0, nil
+	}
+	return false, fmt.Errorf(
+		"XPath %q evaluated to an unexpected %T",
+		p.input,
+		result,
+	)
+}
+
+// toXMLNode returns the document node of value, which is either an
+// [XMLDocument] or its serialization.
+func toXMLNode(value any) (*xmlquery.Node, error) {
+	var doc *XMLDocument
+	var err error
+	switch v := value.(type) {
+	case *XMLDocument:
+		doc = v
+	case string:
+		doc, err = ParseXML([]byte(v))
+	case []byte:
+		doc, err = ParseXML(v)
+	default:
+		return nil, fmt.Errorf(
+			"XPath argument must be an XML document, got %T",
+			value,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.root, nil
+}
+
+// xpath1OperatorNames are the operator names of XPath 1.0. Any other
+// name found where an operator is expected belongs to a later
+// version, e.g. eq, to, union or instance of.
+var xpath1OperatorNames = map[string]bool{
+	"and": true,
+	"or":  true,
+	"mod": true,
+	"div": true,
+}
+
+// xpath2Expressions are the keywords which start an expression
+// binding a variable in XPath 2.0 and later.
+var xpath2Expressions = map[string]bool{
+	"for":   true,
+	"let":   true,
+	"some":  true,
+	"every": true,
+}
+
+// findXPath2Construct scans expr for a construct introduced after
+// XPath 1.0 and returns its description. The scan follows the lexical
+// rules of XPath 1.0: a name following an operand is an operator name
+// and a name followed by a parenthesis is a function call. Malformed
+// expressions are left for the compiler to report.
+func findXPath2Construct(expr string) (string, bool) {
+	// operand reports whether the previous token ends an operand, in
+	// which case an operator is expected.
+	operand := false
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		next := byte(0)
+		if i+1 < len(expr) {
+			next = expr[i+1]
+		}
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return "", false
+			}
+			i += end + 2
+			operand = true
+			continue
+		case c == '(' && next == ':':
+			return "comments", true
+		case c == '|' && next == '|':
+			return `the "||" operator`, true
+		case c == '!' && next != '=':
+			return `the "!" operator`, true
+		case c == '=' && next == '>':
+			return `the "=>" operator`, true
+		case c == ':' && next == '=':
+			return `the ":=" operator`, true
+		case (c == '<' || c == '>') && next == c:
+			return fmt.Sprintf("the %q operator", expr[i:i+2]), true
+		case c == ')' || c == ']':
+			operand = true
+		case c == '*':
+			// A star following an operand is a multiplication,
+			// otherwise it is a name test.
+			operand = !operand
+		case c == '.' && next == '.':
+			i += 2
+			operand = true
+			continue
+		case c == '.' && !isDigit(next):
+			operand = true
+		case c == '$':
+			i = scanXPathName(expr, i+1)
+			operand = true
+			continue
+		case isDigit(c) || c == '.':
+			for i < len(expr) && (isDigit(expr[i]) || expr[i] == '.') {
+				i++
+			}
+			if i < len(expr) && (expr[i] == 'e' || expr[i] == 'E') {
+				return "double literals", true
+			}
+			operand = true
+			continue
+		case isXPathNameStart(c):
+			start := i
+			i = scanXPathName(expr, i)
+			name := expr[start:i]
+			if operand {
+				if !xpath1OperatorNames[name] {
+					return fmt.Sprintf("the %q operator", name), true
+				}
+				operand = false
+				continue
+			}
+			rest := strings.TrimLeft(expr[i:], " \t\n\r")
+			switch {
+			case xpath2Expressions[name] && strings.HasPrefix(rest, "$"):
+				return fmt.Sprintf("the %q expression", name), true
+			case name == "if" && strings.HasPrefix(rest, "("):
+				return `the "if" expression`, true
+			case strings.HasPrefix(rest, "("),
+				strings.HasPrefix(rest, "::"):
+				// Function calls and node type tests become
+				// operands once their closing parenthesis is
+				// read, axes are followed by a node test.
+				operand = false
+			default:
+				operand = true
+			}
+			continue
+		default:
+			operand = false
+		}
+		i++
+	}
+	return "", false
+}
+
+// scanXPathName returns the index following the qualified name
+// starting at index start of expr.
+func scanXPathName(expr string, start int) int {
+	i := start
+	for i < len(expr) {
+		c := expr[i]
+		switch {
+		case isXPathNameStart(c) || isDigit(c) || c == '-' || c == '.':
+			i++
+		case c == ':' && i+1 < len(expr) &&
+			(isXPathNameStart(expr[i+1]) || expr[i+1] == '*'):
+			i += 2
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// isXPathNameStart reports whether c can start an XML name. Non
+// ASCII characters are assumed to be name characters.
+func isXPathNameStart(c byte) bool {
+	return c == '_' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		c >= 0x80
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
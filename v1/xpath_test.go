@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewXPath(t *testing.T) {
+	tests := []string{
+		"/catalog/book[price > 10]/title",
+		"//book[@id = 'b1' or @id != 'b2']",
+		"count(child::book) * 2 - 1 div 2 mod 3",
+		"//ns:book | //ns:*",
+		"ancestor-or-self::node()[last()]",
+		"../book[position() <= 2]/@lang",
+		"//for[if]/to",
+		"//book[contains(title, 'eq or to')]",
+		"//book[price >= .5]",
+	}
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := NewXPath(input, "")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewXPath_Unsupported(t *testing.T) {
+	tests := []struct {
+		input     string
+		construct string
+	}{
+		{"for $b in //book return $b/title", `the "for" expression`},
+		{
+			"some $b in //book satisfies $b/price > 10",
+			`the "some" expression`,
+		},
+		{"let $b := //book return $b", `the "let" expression`},
+		{"if (//book) then 1 else 0", `the "if" expression`},
+		{"//book[price eq 10]", `the "eq" operator`},
+		{"count(1 to 3)", `the "to" operator`},
+		{"//book intersect //book[1]", `the "intersect" operator`},
+		{"//book instance of element()", `the "instance" operator`},
+		{"//book[1] << //book[2]", `the "<<" operator`},
+		{"'a' || 'b'", `the "||" operator`},
+		{"//book ! title", `the "!" operator`},
+		{"//book => count()", `the "=>" operator`},
+		{"//book (: all of them :)", "comments"},
+		{"//book[price > 1e3]", "double literals"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := NewXPath(tt.input, "xpath-30")
+			var unsupported *UnsupportedXPathError
+			require.ErrorAs(t, err, &unsupported)
+			assert.Equal(t, tt.construct, unsupported.Construct)
+		})
+	}
+}